@@ -0,0 +1,226 @@
+package cucumber
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Access log formats supported by AccessLog
+const (
+	// AccessLogCombined is Apache combined log format
+	AccessLogCombined = "combined"
+	// AccessLogJSON writes one JSON object per request
+	AccessLogJSON = "json"
+)
+
+// accessLogFlushInterval is how often buffered access log is flushed to file
+const accessLogFlushInterval = time.Second
+
+// accessLogEntry is a single access log line
+type accessLogEntry struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"request_id,omitempty"`
+	ClientIP  string    `json:"client_ip"`
+	User      string    `json:"user,omitempty"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Proto     string    `json:"proto"`
+	Status    int       `json:"status"`
+	Size      int       `json:"size"`
+	Duration  float32   `json:"duration_ms"`
+	Referer   string    `json:"referer,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+}
+
+// accessLogWriter serializes access log writes, optionally buffering them
+type accessLogWriter struct {
+	mu   sync.Mutex
+	out  io.WriteCloser
+	buf  *bufio.Writer
+	stop chan struct{}
+	done chan struct{}
+}
+
+func (w *accessLogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.buf != nil {
+		return w.buf.Write(p)
+	}
+	return w.out.Write(p)
+}
+
+func (w *accessLogWriter) flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Flush()
+}
+
+// flushEvery flushes buffered writes every interval until writer is closed
+func (w *accessLogWriter) flushEvery(interval time.Duration) {
+	defer close(w.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = w.flush()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// close flushes buffered writes and closes log file
+func (w *accessLogWriter) close() error {
+	if w.buf != nil {
+		close(w.stop)
+		<-w.done
+		if err := w.flush(); err != nil {
+			return err
+		}
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.out.Close()
+}
+
+// accessLogs holds access log writers of application per file path, so
+// middlewares logging into the same file share the writer
+//
+// Writers are flushed and closed when application stops.
+type accessLogs struct {
+	mu      sync.Mutex
+	writers map[string]*accessLogWriter
+}
+
+// writer returns writer of log file at path, opening it on first use
+func (l *accessLogs) writer(path string, opts Options) *accessLogWriter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if w, ok := l.writers[path]; ok {
+		return w
+	}
+
+	w := &accessLogWriter{
+		out: &lumberjack.Logger{
+			Filename:   path,
+			MaxSize:    opts.AccessLogMaxSize,
+			MaxBackups: opts.AccessLogMaxBackups,
+		},
+	}
+	if opts.AccessLogBufferSize > 0 {
+		w.buf = bufio.NewWriterSize(w.out, opts.AccessLogBufferSize)
+		w.stop = make(chan struct{})
+		w.done = make(chan struct{})
+		go w.flushEvery(accessLogFlushInterval)
+	}
+	if l.writers == nil {
+		l.writers = make(map[string]*accessLogWriter)
+	}
+	l.writers[path] = w
+	return w
+}
+
+// Stop flushes and closes access log files
+func (l *accessLogs) Stop(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var err error
+	for path, w := range l.writers {
+		if cerr := w.close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		delete(l.writers, path)
+	}
+	return err
+}
+
+// AccessLog returns a middleware that writes one line per request into log file
+// at path, separately from the application log
+//
+// Format is either "combined" for Apache combined log format or "json" for
+// structured log. File is rotated once it grows over Options.AccessLogMaxSize
+// megabytes keeping Options.AccessLogMaxBackups old files. Writes are buffered
+// when Options.AccessLogBufferSize is set and flushed every second. Middlewares
+// using the same path share the file, which is flushed and closed when
+// application stops.
+//
+//	api := router.Group("/api", cucumber.AccessLog("/var/log/app/api.log", cucumber.AccessLogJSON))
+func AccessLog(path string, format string) HandlerFunc {
+	if format != AccessLogCombined && format != AccessLogJSON {
+		panic(fmt.Sprintf("unknown access log format %q", format))
+	}
+
+	return func(c *Context) {
+		// file is opened on first request as it is configured by app options
+		writer := c.app.accessLogs.writer(path, c.app.Options)
+
+		start := time.Now()
+
+		c.Next()
+
+		user, _, _ := c.Request.BasicAuth()
+		entry := accessLogEntry{
+			Time:      start,
+			RequestID: c.RequestID(),
+			ClientIP:  c.ClientIP(),
+			User:      user,
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.RequestURI(),
+			Proto:     c.Request.Proto,
+			Status:    c.Response.Status(),
+			Size:      c.Response.Size(),
+			Duration:  durationToMilliseconds(time.Since(start)),
+			Referer:   c.Request.Referer(),
+			UserAgent: c.Request.UserAgent(),
+		}
+		if entry.Size < 0 {
+			entry.Size = 0
+		}
+
+		var line []byte
+		if format == AccessLogJSON {
+			line, _ = json.Marshal(entry)
+			line = append(line, '\n')
+		} else {
+			line = []byte(formatCombinedLog(entry))
+		}
+		if _, err := writer.Write(line); err != nil {
+			c.Logger().Error("access-log: " + err.Error())
+		}
+	}
+}
+
+// formatCombinedLog formats entry in Apache combined log format
+func formatCombinedLog(e accessLogEntry) string {
+	size := "-"
+	if e.Size > 0 {
+		size = strconv.Itoa(e.Size)
+	}
+	return fmt.Sprintf("%s - %s [%s] \"%s %s %s\" %d %s \"%s\" \"%s\"\n",
+		e.ClientIP,
+		dashIfEmpty(e.User),
+		e.Time.Format("02/Jan/2006:15:04:05 -0700"),
+		e.Method, e.Path, e.Proto,
+		e.Status,
+		size,
+		dashIfEmpty(e.Referer),
+		dashIfEmpty(e.UserAgent),
+	)
+}
+
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,117 @@
+package cucumber
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessLogJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+
+	app := newTestAppInstance()
+	app.GET("/users/:id", AccessLog(path, AccessLogJSON), func(c *Context) {
+		c.String(http.StatusOK, "user "+c.Param("id"))
+	})
+	app.GET("/untracked", func(c *Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	for i := 0; i < 5; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/users/42?page=1", nil)
+		req.Header.Set("User-Agent", "test-agent")
+		app.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	performRequest(app, http.MethodGet, "/untracked")
+
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	entries := []accessLogEntry{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry accessLogEntry
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+
+	assert.Len(t, entries, 5)
+	for _, entry := range entries {
+		assert.Equal(t, http.MethodGet, entry.Method)
+		assert.Equal(t, "/users/42?page=1", entry.Path)
+		assert.Equal(t, http.StatusOK, entry.Status)
+		assert.Equal(t, len("user 42"), entry.Size)
+		assert.Equal(t, "test-agent", entry.UserAgent)
+		assert.False(t, entry.Time.IsZero())
+	}
+}
+
+func TestAccessLogCombinedBuffered(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+
+	app := newTestAppInstance()
+	app.AccessLogBufferSize = 4096
+	app.GET("/", AccessLog(path, AccessLogCombined), func(c *Context) {
+		c.Status(http.StatusNoContent)
+	})
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var content string
+	assert.Eventually(t, func() bool {
+		data, _ := ioutil.ReadFile(path)
+		content = string(data)
+		return content != ""
+	}, 3*time.Second, 50*time.Millisecond)
+
+	assert.True(t, strings.HasPrefix(content, "192.0.2.1 - - ["), content)
+	assert.Contains(t, content, "] \"GET / HTTP/1.1\" 204 - \"-\" \"-\"\n")
+}
+
+func TestAccessLogFlushedOnStop(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+
+	app := newTestAppInstance()
+	app.AccessLogBufferSize = 4096
+	app.GET("/users", AccessLog(path, AccessLogJSON), func(c *Context) {
+		c.Status(http.StatusOK)
+	})
+	app.GET("/orders", AccessLog(path, AccessLogJSON), func(c *Context) {
+		c.Status(http.StatusOK)
+	})
+	performRequest(app, http.MethodGet, "/users")
+	performRequest(app, http.MethodGet, "/orders")
+
+	// middlewares share writer of the same file
+	assert.Len(t, app.accessLogs.writers, 1)
+	writer := app.accessLogs.writers[path]
+
+	// buffered lines are written on stop, without waiting for flush interval
+	assert.NoError(t, app.stop())
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(data), "\n"))
+	assert.Empty(t, app.accessLogs.writers)
+
+	// flush goroutine is stopped
+	select {
+	case <-writer.done:
+	default:
+		t.Error("access log flush goroutine is still running")
+	}
+}
+
+func TestAccessLogUnknownFormat(t *testing.T) {
+	assert.Panics(t, func() {
+		AccessLog("access.log", "common")
+	})
+}
@@ -6,19 +6,25 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"path"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/AjdinHalac/cucumber/di"
 	"go.elastic.co/apm/module/apmgrpc"
 	"go.elastic.co/apm/module/apmhttp"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -28,13 +34,80 @@ var (
 
 // App holds fully working application setup
 type App struct {
+	// queueDepth is accessed atomically, first field to keep it 64-bit aligned
+	queueDepth int64
+
 	Options
 	container di.Container
 
 	server *grpc.Server
+	health *health.Server
 	router *Router
 	pool   sync.Pool
 
+	// httpServer is HTTP server of started application, see Drain
+	httpServer *http.Server
+
+	// unary interceptors sorted by priority and their chain,
+	// chain holds grpc.UnaryServerInterceptor read without locking by every call
+	unaryInterceptors []prioritizedInterceptor
+	unaryChain        atomic.Value
+
+	// stream interceptors and their chain holding grpc.StreamServerInterceptor
+	streamInterceptors []grpc.StreamServerInterceptor
+	streamChain        atomic.Value
+
+	// servicesRegistered reports whether any gRPC service is registered
+	servicesRegistered bool
+	// protoServices maps registered proto service names to types registering them
+	protoServices map[string]reflect.Type
+
+	// callbacks configuring gRPC server before it starts serving
+	grpcConfigurers []func(*grpc.Server)
+
+	// Autowired services and controllers in registration order, see Wire
+	consumers []interface{}
+
+	// services waiting for ordered initialization, see Init
+	orderedIniters []orderedIniter
+	initDone       bool
+
+	// services waiting for deferred initialization in registration order
+	deferredIniters  []DeferredIniter
+	deferredInitOnce sync.Once
+	deferredInitErr  error
+
+	// liveness guard watching for deadlocks, see LivenessGuard
+	liveness *livenessGuard
+
+	// services with lifecycle hooks in registration order
+	starters       []Starter
+	stoppers       []Stopper
+	startOnce      sync.Once
+	startErr       error
+	stopOnce       sync.Once
+	stopErr        error
+	servicesCancel context.CancelFunc
+
+	// access log files written by AccessLog middlewares
+	accessLogs *accessLogs
+
+	// middlewares executed before routing, e.g. path rewrite
+	preRouting HandlersChain
+
+	// body decoders and encoders per content type
+	codecs *codecs
+
+	// dependency injection timing collected when Options.DebugDI is set
+	diStats DIStats
+
+	// controller paths claimed by registered controllers
+	controllerPaths map[string]string
+
+	// cancel stops application started with StartWithContext
+	cancel context.CancelFunc
+	mu     sync.Mutex
+
 	methodNotAllowedHandler HandlerFunc
 	unauthorizedHandler     HandlerFunc
 	notFoundHandler         HandlerFunc
@@ -54,15 +127,58 @@ func NewWithOptions(opts Options) *App {
 
 	// create application router
 	r := NewRouter()
+	r.deferStacks = false
+	if opts.BasePath != "" {
+		r.basePath = path.Join("/", opts.BasePath)
+	}
+
+	app := &App{
+		router:    r,
+		container: di.NewContainer(),
+		codecs:    newCodecs(),
+
+		controllerPaths: make(map[string]string),
+		accessLogs:      &accessLogs{},
+	}
+	// access logs are closed last, so services can log while stopping
+	app.stoppers = append(app.stoppers, app.accessLogs)
+
+	// user interceptors run before built-in ones
+	for _, interceptor := range opts.UnaryInterceptors {
+		app.addInterceptor(0, interceptor)
+	}
+
+	app.addInterceptor(InterceptorPriorityAPM, apmgrpc.NewUnaryServerInterceptor())
+
+	for _, interceptor := range opts.StreamInterceptors {
+		app.addStreamInterceptor(interceptor)
+	}
+	app.addStreamInterceptor(apmgrpc.NewStreamServerInterceptor())
 
 	if opts.UseRequestLogger {
 		r.Use(RequestLogger())
-		opts.UnaryInterceptors = append(opts.UnaryInterceptors, NewUnaryRequestLogger(opts))
+		app.addInterceptor(InterceptorPriorityRequestLogger, NewUnaryRequestLogger(opts))
 	}
 
 	if opts.UsePanicRecovery {
 		r.Use(PanicRecovery())
-		opts.UnaryInterceptors = append(opts.UnaryInterceptors, NewUnaryPanicRecovery(opts))
+		app.addInterceptor(InterceptorPriorityPanicRecovery, NewUnaryPanicRecovery(opts))
+	}
+
+	if opts.UseRequestQueue {
+		r.Use(Queue(opts.QueueSize, opts.QueueTimeout))
+	}
+
+	if opts.DecompressBody {
+		r.Use(DecompressBody())
+	}
+
+	if opts.UseOPA {
+		r.Use(OPAMiddleware(opts.OPAConfig))
+	}
+
+	if opts.UseTranslator {
+		r.Use(Localize())
 	}
 
 	if opts.ServeStatic {
@@ -70,19 +186,20 @@ func NewWithOptions(opts Options) *App {
 	}
 
 	srvOpts := []grpc.ServerOption{}
-	opts.UnaryInterceptors = append(opts.UnaryInterceptors, apmgrpc.NewUnaryServerInterceptor())
-	srvOpts = append(srvOpts, grpc.UnaryInterceptor(ChainUnaryServer(opts.UnaryInterceptors...)))
-	srvOpts = append(srvOpts, grpc.StreamInterceptor(apmgrpc.NewStreamServerInterceptor()))
+	srvOpts = append(srvOpts, grpc.UnaryInterceptor(app.interceptUnary))
+	srvOpts = append(srvOpts, grpc.StreamInterceptor(app.interceptStream))
 
 	grpcServer := grpc.NewServer(srvOpts...)
 
-	reflection.Register(grpcServer)
+	if !opts.DisableGRPCReflection {
+		reflection.Register(grpcServer)
+	}
 
-	app := &App{
-		Options:   opts,
-		router:    r,
-		container: di.NewContainer(),
-		server:    grpcServer,
+	app.Options = opts
+	app.server = grpcServer
+
+	if opts.RegisterGRPCHealthService {
+		app.registerHealthService()
 	}
 
 	//context pool allocation
@@ -99,6 +216,37 @@ func (a *App) Use(middleware ...HandlerFunc) *App {
 	return a
 }
 
+// ValidateMiddlewareOrder adds middleware order rules to application router,
+// see Router.ValidateMiddlewareOrder
+func (a *App) ValidateMiddlewareOrder(rules ...OrderRule) *App {
+	a.router.ValidateMiddlewareOrder(rules...)
+	return a
+}
+
+// DefineStack registers named middleware stack on application router
+func (a *App) DefineStack(name string, middlewares ...HandlerFunc) *App {
+	a.router.DefineStack(name, middlewares...)
+	return a
+}
+
+// Stack returns copy of named middleware stack defined on application router
+//
+// Controllers can use application stacks on their own routers with
+// Router.UseStack, they are resolved when controller is registered
+func (a *App) Stack(name string) []HandlerFunc {
+	stack, ok := a.router.Stack(name)
+	if !ok {
+		panic(fmt.Sprintf("middleware stack `%s` is not defined", name))
+	}
+	return stack
+}
+
+// UseStack appends middlewares of named stack onto the Router stack.
+func (a *App) UseStack(name string) *App {
+	a.router.UseStack(name)
+	return a
+}
+
 // GET is a shortcut for router.Handle("GET", path, handle)
 func (a *App) GET(path string, handler ...HandlerFunc) *App {
 	a.router.GET(path, handler...)
@@ -141,6 +289,12 @@ func (a *App) DELETE(path string, handler ...HandlerFunc) *App {
 	return a
 }
 
+// QUERY is a shortcut for router.Handle("QUERY", path, handle)
+func (a *App) QUERY(path string, handler ...HandlerFunc) *App {
+	a.router.QUERY(path, handler...)
+	return a
+}
+
 // Any registers a route that matches all the HTTP methods.
 // GET, POST, PUT, PATCH, HEAD, OPTIONS, DELETE, CONNECT, TRACE.
 func (a *App) Any(relativePath string, handler ...HandlerFunc) *App {
@@ -148,45 +302,177 @@ func (a *App) Any(relativePath string, handler ...HandlerFunc) *App {
 	return a
 }
 
+// Handler registers http.Handler with the given path and method
+func (a *App) Handler(method, path string, handler http.Handler) *App {
+	a.router.Handler(method, path, handler)
+	return a
+}
+
+// Proxy registers route which forwards all the HTTP methods to target upstream
+func (a *App) Proxy(path string, target *url.URL, opts ProxyOptions) *App {
+	a.router.Proxy(path, target, opts)
+	return a
+}
+
 // Attach another router to current one
 func (a *App) Attach(prefix string, router *Router) *App {
 	a.router.Attach(prefix, router)
 	return a
 }
 
+// HotRegister registers a new request handle with the given path and method
+// while application is already serving requests
+//
+// Unlike Handle it is safe to call concurrently with request handling,
+// registration errors are returned instead of panicking.
+func (a *App) HotRegister(method, path string, handlers ...HandlerFunc) (err error) {
+	a.router.mu.Lock()
+	defer a.router.mu.Unlock()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	a.router.Handle(method, path, handlers...)
+	return nil
+}
+
+// Deregister removes the route registered with the given method and path
+// while application is serving requests, see Router.Deregister
+func (a *App) Deregister(method, path string) error {
+	return a.router.Deregister(method, path)
+}
+
 // Register appends one or more values as dependecies
 func (a *App) RegisterPackage(value interface{}) *App {
 	a.container.Add(value)
+	a.injectProvider(value)
 	return a
 }
 
 // Register appends one or more values as dependecies
+//
+// Non-pointer values, e.g. function types, are accepted when they implement
+// Service, Initer or DeferredIniter. Autowired services have to be pointers
+// so their fields can be injected.
 func (a *App) Register(value interface{}) *App {
 
 	typ := reflect.TypeOf(value)
+	if typ == nil {
+		panic("Service can not be nil")
+	}
 	fullSvcName := typ.String()
 
 	if typ.Kind() != reflect.Ptr {
-		panic(fmt.Sprintf("Service `%s` has to be pointer", fullSvcName))
+		if _, ok := value.(Autowired); ok {
+			panic(fmt.Sprintf("Autowired service `%s` has to be pointer, dependencies can not be injected into fields of a copy", fullSvcName))
+		}
+		_, isService := value.(Service)
+		_, isIniter := value.(Initer)
+		_, isDeferred := value.(DeferredIniter)
+		if !isService && !isIniter && !isDeferred {
+			panic(fmt.Sprintf("Service `%s` has to be pointer or implement Service, Initer or DeferredIniter", fullSvcName))
+		}
 	}
 
 	if _, ok := value.(Autowired); ok {
 		if a.container.Len() != 0 {
-			a.InjectDeps(value)
+			a.injectDeps(typ.Elem().String(), func() {
+				a.InjectDeps(value)
+			})
 		}
+		a.consumers = append(a.consumers, value)
 	}
 
 	if _, ok := value.(Service); ok {
 		a.container.Add(value)
+		a.injectProvider(value)
 	}
 
 	if i, ok := value.(Initer); ok {
-		i.Init(a)
+		if o, ok := value.(InitOrderer); ok && !a.initDone {
+			a.orderedIniters = append(a.orderedIniters, orderedIniter{Initer: i, order: o.InitOrder()})
+		} else {
+			i.Init(a)
+		}
+	}
+
+	if i, ok := value.(DeferredIniter); ok {
+		a.deferredIniters = append(a.deferredIniters, i)
+	}
+
+	if i, ok := value.(Starter); ok {
+		a.starters = append(a.starters, i)
+	}
+
+	if i, ok := value.(Stopper); ok {
+		a.stoppers = append(a.stoppers, i)
 	}
 
 	return a
 }
 
+// Wire injects registered dependencies into fields of Autowired services
+// and controllers which are still not set
+//
+// Dependencies registered after their consumers are injected right away,
+// so injection does not depend on registration order. Wire is called by
+// DeferredInit when application starts, before any service is initialized,
+// to catch values added to the container by other means.
+func (a *App) Wire() {
+	for _, consumer := range a.consumers {
+		di.InjectZero(consumer, a.container...)
+	}
+}
+
+// injectProvider injects newly registered value into consumers registered before
+func (a *App) injectProvider(value interface{}) {
+	val := reflect.ValueOf(value)
+	for _, consumer := range a.consumers {
+		if consumer != value {
+			di.InjectZero(consumer, val)
+		}
+	}
+}
+
+// Init runs Init of registered services implementing InitOrderer, ordered
+// by InitOrder and then by registration order
+//
+// Init runs only once, it is called by DeferredInit when application starts.
+// Ordered services registered afterwards are initialized right away.
+func (a *App) Init() {
+	if a.initDone {
+		return
+	}
+	a.initDone = true
+	sort.SliceStable(a.orderedIniters, func(i, j int) bool {
+		return a.orderedIniters[i].order < a.orderedIniters[j].order
+	})
+	for _, i := range a.orderedIniters {
+		i.Init(a)
+	}
+	a.orderedIniters = nil
+}
+
+// DeferredInit runs deferred initialization of all registered services
+// in registration order and stops on the first error
+//
+// Dependencies are wired and ordered services initialized first, see Wire and
+// Init. Deferred initialization runs only once, subsequent calls return the first result
+func (a *App) DeferredInit() error {
+	a.deferredInitOnce.Do(func() {
+		a.Wire()
+		a.Init()
+		for _, i := range a.deferredIniters {
+			if err := i.DeferredInit(a); err != nil {
+				a.deferredInitErr = err
+				return
+			}
+		}
+	})
+	return a.deferredInitErr
+}
+
 // InjectDeps accepts a destination struct and any optional context value(s),
 // and injects registered dependencies to the destination object
 func (a *App) InjectDeps(dest interface{}, ctx ...reflect.Value) {
@@ -196,24 +482,128 @@ func (a *App) InjectDeps(dest interface{}, ctx ...reflect.Value) {
 
 // RegisterServiceHandler registers a service and its implementation to the gRPC
 // server. This must be called before invoking Serve.
+//
+// It panics when service can not be registered, see RegisterServiceHandlerE.
 func (a *App) RegisterServiceHandler(service interface{}) *App {
-	a.Register(service)
+	if err := a.RegisterServiceHandlerE(service); err != nil {
+		panic(err.Error())
+	}
+	return a
+}
+
+// RegisterServiceHandlerE is RegisterServiceHandler returning an error when
+// service does not implement ServiceProtoRegister or its proto service is
+// already registered, e.g. by another module
+func (a *App) RegisterServiceHandlerE(service interface{}) error {
 	svcProtoRegister, ok := service.(ServiceProtoRegister)
 	if !ok {
-		panic("Service does not implement ServiceProtoRegister interface")
+		return errors.New("Service does not implement ServiceProtoRegister interface")
+	}
+	if err := a.trackProtoServices(svcProtoRegister); err != nil {
+		return err
+	}
+	a.Register(service)
+	known := make(map[string]bool)
+	for name := range a.server.GetServiceInfo() {
+		known[name] = true
 	}
 	svcProtoRegister.RegisterProtoServer(a.server)
+	a.setServicesServing(known)
+
+	a.mu.Lock()
+	a.servicesRegistered = true
+	a.mu.Unlock()
+	return nil
+}
+
+// RegisterServiceGroup registers all services of the group, services implementing
+// ServiceProtoRegister are registered to the gRPC server, ones implementing
+// ControllerRouter as controllers and the rest as dependencies
+func (a *App) RegisterServiceGroup(group ServiceGroup) *App {
+	for _, service := range group.Services() {
+		_, isProto := service.(ServiceProtoRegister)
+		_, isCtrl := service.(ControllerRouter)
+
+		if isProto {
+			a.RegisterServiceHandler(service)
+		}
+		if isCtrl {
+			a.RegisterController(service)
+		}
+		if !isProto && !isCtrl {
+			a.Register(service)
+		}
+	}
+
+	if r, ok := group.(ControllerRouter); ok {
+		a.router.Attach("/", r.Routes())
+	}
 	return a
 }
 
 // RegisterController registers application controller
 func (a *App) RegisterController(ctrl interface{}) *App {
+	fullCtrlName := a.controllerName(ctrl)
 
-	// set controller route prefix to default
-	prefix := "/"
-	// set controller version to default
-	version := ""
+	a.injectDeps(fullCtrlName, func() {
+		// get DI injector
+		injector := di.Struct(ctrl, a.container...)
+
+		// inject dependencies to controller
+		injector.Inject(ctrl)
+	})
+	a.consumers = append(a.consumers, ctrl)
+
+	a.mountController(ctrl, fullCtrlName)
+	return a
+}
 
+// RegisterControllers registers application controllers injecting their
+// dependencies concurrently, which speeds up boot of apps with many controllers
+//
+// Controllers are initialized and their routes attached in given order once
+// all of them are injected. Use RegisterController for controllers which
+// depend on registration order during injection.
+func (a *App) RegisterControllers(ctrls ...interface{}) *App {
+	names := make([]string, len(ctrls))
+	for i, ctrl := range ctrls {
+		names[i] = a.controllerName(ctrl)
+	}
+
+	var (
+		wg        sync.WaitGroup
+		panicOnce sync.Once
+		recovered interface{}
+	)
+	for i, ctrl := range ctrls {
+		wg.Add(1)
+		go func(ctrl interface{}, name string) {
+			defer wg.Done()
+			// re-panic in caller goroutine, as with RegisterController
+			defer func() {
+				if p := recover(); p != nil {
+					panicOnce.Do(func() { recovered = p })
+				}
+			}()
+			a.injectDeps(name, func() {
+				di.Struct(ctrl, a.container...).Inject(ctrl)
+			})
+		}(ctrl, names[i])
+	}
+	wg.Wait()
+	if recovered != nil {
+		panic(recovered)
+	}
+
+	a.consumers = append(a.consumers, ctrls...)
+	for i, ctrl := range ctrls {
+		a.mountController(ctrl, names[i])
+	}
+	return a
+}
+
+// controllerName validates controller and returns its full name
+func (a *App) controllerName(ctrl interface{}) string {
 	// check naming convention
 	typ := reflect.TypeOf(ctrl)
 
@@ -237,11 +627,22 @@ func (a *App) RegisterController(ctrl interface{}) *App {
 		panic(fmt.Sprintf("Controller `%s` does not follow naming convention", fullCtrlName))
 	}
 
-	// get DI injector
-	injector := di.Struct(ctrl, a.container...)
+	if _, ok := ctrl.(ControllerRouter); !ok {
+		panic(fmt.Sprintf("controller `%s` does not implement ControllerRouter interface", fullCtrlName))
+	}
+	return fullCtrlName
+}
+
+// mountController initializes injected controller and attaches its routes
+func (a *App) mountController(ctrl interface{}, fullCtrlName string) {
 
-	// inject dependencies to controller
-	injector.Inject(ctrl)
+	// set controller route prefix to default
+	prefix := a.ControllerIndexPrefix
+	if prefix == "" {
+		prefix = defaultControllerIndexPrefix
+	}
+	// set controller version to default
+	version := ""
 
 	// extract controller name from struct
 	ctrlName := strings.Replace(fullCtrlName, ".", "", -1)
@@ -272,29 +673,29 @@ func (a *App) RegisterController(ctrl interface{}) *App {
 		prefix = p.Prefix()
 	}
 
-	// check if controller imlements initer
-	if i, ok := ctrl.(Initer); ok {
-		i.Init(a)
-	}
-
 	path := fmt.Sprintf("%s%s", version, prefix)
 
 	if !strings.HasPrefix(path, "/") {
 		panic(fmt.Sprintf("Unable to register controller: `%s`, controller path has to start with `/`. Check Controller `Version()` and `Prefix()` method implementation ", fullCtrlName))
 	}
 
-	// log registration for debugging purposes
-	a.Logger.Debug(fmt.Sprintf("Registering `%s` with Path: `%s`", fullCtrlName, path))
+	// check if path is already claimed by another controller
+	if claimed, ok := a.controllerPaths[path]; ok {
+		panic(fmt.Sprintf("Unable to register controller: `%s`, controller path `%s` is already claimed by controller `%s`. Check Controller names and `Version()` and `Prefix()` method implementation", fullCtrlName, path, claimed))
+	}
+	a.controllerPaths[path] = fullCtrlName
 
-	ctrlRouter, ok := ctrl.(ControllerRouter)
-	if !ok {
-		panic(fmt.Sprintf("controller `%s` does not implement ControllerRouter interface", fullCtrlName))
+	// check if controller imlements initer
+	if i, ok := ctrl.(Initer); ok {
+		i.Init(a)
 	}
 
-	routes := ctrlRouter.Routes()
+	// log registration for debugging purposes
+	a.Logger.Debug(fmt.Sprintf("Registering `%s` with Path: `%s`", fullCtrlName, path))
+
+	routes := ctrl.(ControllerRouter).Routes()
 
 	a.router.Attach(path, routes)
-	return a
 }
 
 // MethodNotAllowedHandler is Handler where message and error can be personalized
@@ -322,6 +723,10 @@ func (a *App) ErrorHandler(handler HandlerFunc) {
 
 func (a *App) Start() {
 	a.Logger.Info(fmt.Sprintf("Starting %s version %s...", a.Name, a.Version))
+	a.logStartupSummary()
+	if err := a.checkProductionConfig(); err != nil {
+		a.Logger.Fatal(err)
+	}
 
 	group := new(errgroup.Group)
 	group.Go(func() error { return a.StartHTTP() })
@@ -330,6 +735,98 @@ func (a *App) Start() {
 	a.Logger.Fatal(group.Wait())
 }
 
+// StartWithContext starts both HTTP and gRPC servers and gracefully shuts
+// them down when ctx is cancelled.
+//
+// Unlike Start, no OS signal handlers are installed, so the caller is in
+// charge of the application lifecycle (e.g. with signal.NotifyContext).
+// HTTP server is given ShutdownDrainTimeout and gRPC server GRPCShutdownTimeout
+// to finish in-flight requests, both are drained at the same time.
+func (a *App) StartWithContext(ctx context.Context) error {
+	a.Logger.Info(fmt.Sprintf("Starting %s version %s...", a.Name, a.Version))
+	a.logStartupSummary()
+	if err := a.checkProductionConfig(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	a.mu.Lock()
+	a.cancel = cancel
+	a.mu.Unlock()
+
+	if err := a.DeferredInit(); err != nil {
+		return err
+	}
+	if err := a.startServices(ctx); err != nil {
+		return err
+	}
+	a.logRouterStats()
+
+	srv := a.newHTTPServer()
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	if a.HTTPAddr != "" {
+		group.Go(func() error {
+			a.Logger.Info(fmt.Sprintf("Starting HTTP Server at %s", a.HTTPAddr))
+			lis, err := a.listenHTTP()
+			if err != nil {
+				return err
+			}
+			if err := srv.Serve(lis); err != http.ErrServerClosed {
+				return err
+			}
+			return nil
+		})
+	}
+
+	if a.GRPCAddr != "" {
+		group.Go(func() error {
+			a.Logger.Info(fmt.Sprintf("Starting GRPC Server at %s", a.GRPCAddr))
+			lis, err := listen(a.GRPCAddr)
+			if err != nil {
+				return err
+			}
+			a.configureGRPC()
+			if err := a.server.Serve(lis); err != grpc.ErrServerStopped {
+				return err
+			}
+			return nil
+		})
+	}
+
+	group.Go(func() error {
+		<-groupCtx.Done()
+		a.Logger.Info("Shutting down application")
+
+		drainCtx := context.Background()
+		if a.ShutdownDrainTimeout > 0 {
+			var drainCancel context.CancelFunc
+			drainCtx, drainCancel = context.WithTimeout(drainCtx, a.ShutdownDrainTimeout)
+			defer drainCancel()
+		}
+
+		// drain gRPC server in background while HTTP server drains
+		grpcStopped := make(chan struct{})
+		go func() {
+			a.stopGRPC(a.GRPCShutdownTimeout)
+			close(grpcStopped)
+		}()
+
+		err := srv.Shutdown(drainCtx)
+		<-grpcStopped
+
+		// services are stopped once servers no longer use them
+		if err := a.stop(); err != nil {
+			a.Logger.Error(err.Error())
+		}
+		return err
+	})
+
+	return group.Wait()
+}
+
 // StartHTTP the application at the specified address/port and listen for OS
 // interrupt and kill signals and will attempt to stop the application gracefully.
 func (a *App) StartHTTP() error {
@@ -337,12 +834,18 @@ func (a *App) StartHTTP() error {
 		return nil
 	}
 
+	if err := a.DeferredInit(); err != nil {
+		return err
+	}
+	if err := a.startServices(context.Background()); err != nil {
+		return err
+	}
+	a.logRouterStats()
+
 	a.Logger.Info(fmt.Sprintf("Starting HTTP Server at %s", a.HTTPAddr))
 
 	// create http server
-	srv := http.Server{
-		Handler: apmhttp.Wrap(a),
-	}
+	srv := a.newHTTPServer()
 
 	// make interrupt channel
 	c := make(chan os.Signal, 1)
@@ -351,27 +854,54 @@ func (a *App) StartHTTP() error {
 	go func() {
 		<-c
 		a.Logger.Info("Shutting down application")
-		if err := a.stop(); err != nil {
+		if err := srv.Shutdown(context.Background()); err != nil {
 			a.Logger.Error(err.Error())
 		}
 
-		if err := srv.Shutdown(context.Background()); err != nil {
+		if err := a.stop(); err != nil {
 			a.Logger.Error(err.Error())
 		}
 	}()
 
 	srv.Addr = a.HTTPAddr
-	if strings.HasPrefix(a.HTTPAddr, "unix:") {
-		// create unix network listener
-		lis, err := net.Listen("unix", a.HTTPAddr[5:])
-		if err != nil {
-			return err
-		}
-		// start accepting incomming requests on listener
-		return srv.Serve(lis)
-	} else {
-		return srv.ListenAndServe()
+	lis, err := a.listenHTTP()
+	if err != nil {
+		return err
+	}
+	// start accepting incomming requests on listener
+	return srv.Serve(lis)
+}
+
+// newHTTPServer creates HTTP server serving the application
+func (a *App) newHTTPServer() *http.Server {
+	srv := &http.Server{
+		Handler:     apmhttp.Wrap(a),
+		ErrorLog:    a.serverErrorLog(),
+		BaseContext: a.BaseContext,
+		ConnContext: a.ConnContext,
+	}
+	if a.liveness != nil {
+		srv.ConnState = a.liveness.connState
+	}
+	a.mu.Lock()
+	a.httpServer = srv
+	a.mu.Unlock()
+	return srv
+}
+
+// Drain stops accepting HTTP requests and waits until in-flight requests
+// complete or ctx is done, gRPC server keeps serving
+//
+// It is intended for rolling deployments, where new instance takes over
+// traffic before the application is stopped. Drain can be called repeatedly.
+func (a *App) Drain(ctx context.Context) error {
+	a.mu.Lock()
+	srv := a.httpServer
+	a.mu.Unlock()
+	if srv == nil {
+		return nil
 	}
+	return srv.Shutdown(ctx)
 }
 
 // ServeGRPC the application at the specified address/port and listen for OS
@@ -381,6 +911,13 @@ func (a *App) StartGRPC() error {
 		return nil
 	}
 
+	if err := a.DeferredInit(); err != nil {
+		return err
+	}
+	if err := a.startServices(context.Background()); err != nil {
+		return err
+	}
+
 	a.Logger.Info(fmt.Sprintf("Starting GRPC Server at %s", a.GRPCAddr))
 
 	// make interrupt channel
@@ -390,28 +927,103 @@ func (a *App) StartGRPC() error {
 	go func() {
 		<-c
 		a.Logger.Info("Shutting down application")
+		a.stopGRPC(a.GRPCShutdownTimeout)
+
 		if err := a.stop(); err != nil {
 			a.Logger.Error(err.Error())
 		}
+	}()
+
+	lis, err := listen(a.GRPCAddr)
+	if err != nil {
+		return err
+	}
+	a.configureGRPC()
+	// start accepting incomming requests on listener
+	return a.server.Serve(lis)
+}
 
+// stopGRPC gracefully stops gRPC server, in-flight requests
+// still running after timeout are cancelled by hard stop
+func (a *App) stopGRPC(timeout time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
 		a.server.GracefulStop()
+		close(stopped)
 	}()
 
-	if strings.HasPrefix(a.GRPCAddr, "unix:") {
-		// create unix network listener
-		lis, err := net.Listen("unix", a.GRPCAddr[5:])
-		if err != nil {
-			return err
-		}
-		// start accepting incomming requests on listener
-		return a.server.Serve(lis)
-	} else {
-		lis, err := net.Listen("tcp", a.GRPCAddr)
-		if err != nil {
-			return err
-		}
-		return a.server.Serve(lis)
+	if timeout <= 0 {
+		<-stopped
+		return
+	}
+
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		a.Logger.Warn(fmt.Sprintf("GRPC Server did not stop within %s, forcing stop", timeout))
+		a.server.Stop()
+	}
+}
+
+// listen creates network listener for given address,
+// addresses prefixed with `unix:` are served over unix socket
+func listen(addr string) (net.Listener, error) {
+	if strings.HasPrefix(addr, "unix:") {
+		return net.Listen("unix", addr[5:])
+	}
+	return net.Listen("tcp", addr)
+}
+
+// Translator returns application translator, nil when translator is disabled
+func (a *App) Translator() *Translator {
+	return a.Options.Translator
+}
+
+// URL returns path of the route relative to Options.BasePath,
+// e.g. "/myservice/users" for "/users"
+func (a *App) URL(relativePath string) string {
+	return a.router.calculateAbsolutePath(relativePath)
+}
+
+// GRPCServer returns application gRPC server, e.g. to register services
+// of third-party libraries which expect *grpc.Server
+//
+// Services have to be registered before StartGRPC or StartWithContext,
+// use ConfigureGRPC to register them right before server starts.
+func (a *App) GRPCServer() *grpc.Server {
+	return a.server
+}
+
+// ConfigureGRPC registers fn called with application gRPC server right before
+// it starts serving in StartGRPC or StartWithContext
+//
+// Services registered by fn are reported as serving by gRPC health service.
+// Interceptors can not be added to the server, see AddGRPCUnaryInterceptor.
+func (a *App) ConfigureGRPC(fn func(*grpc.Server)) *App {
+	a.mu.Lock()
+	a.grpcConfigurers = append(a.grpcConfigurers, fn)
+	a.mu.Unlock()
+	return a
+}
+
+// configureGRPC calls callbacks registered with ConfigureGRPC
+func (a *App) configureGRPC() {
+	a.mu.Lock()
+	configurers := a.grpcConfigurers
+	a.grpcConfigurers = nil
+	a.mu.Unlock()
+	if len(configurers) == 0 {
+		return
 	}
+
+	known := make(map[string]bool)
+	for name := range a.server.GetServiceInfo() {
+		known[name] = true
+	}
+	for _, fn := range configurers {
+		fn(a.server)
+	}
+	a.setServicesServing(known)
 }
 
 // Router returns application router instance
@@ -431,19 +1043,43 @@ func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// reset context from previous use
 	c.reset()
 
+	if a.liveness != nil {
+		defer a.liveness.leave(a.liveness.enter())
+	}
+
 	// handle the request
-	a.handleHTTPRequest(c)
+	if !a.handlePreRouting(c) {
+		a.handleHTTPRequest(c)
+	}
 
 	// put back context to pool
 	a.pool.Put(c)
 }
 
 func (a *App) stop() error {
-	return nil
+	ctx := context.Background()
+	if a.ShutdownDrainTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.ShutdownDrainTimeout)
+		defer cancel()
+	}
+	return a.stopServices(ctx)
 }
 
 // Stop issues interrupt signal
+//
+// When application is started with StartWithContext its context
+// is cancelled instead
 func (a *App) Stop() error {
+	a.mu.Lock()
+	cancel := a.cancel
+	a.mu.Unlock()
+	if cancel != nil {
+		a.Logger.Debug("Stopping....")
+		cancel()
+		return nil
+	}
+
 	// get current process
 	proc, err := os.FindProcess(os.Getpid())
 	if err != nil {
@@ -457,38 +1093,70 @@ func (a *App) Stop() error {
 func (a *App) handleHTTPRequest(c *Context) {
 	req := c.Request
 	httpMethod := req.Method
-	path := req.URL.Path
+
+	// routes might be registered at runtime with HotRegister or removed with Deregister
+	a.router.mu.RLock()
+	handlers, ps, redirectPath, allow := a.lookupRoute(c)
+	a.router.mu.RUnlock()
+
+	if handlers != nil {
+		a.serveRoute(c, handlers, ps)
+		return
+	}
+
+	if redirectPath != "" {
+		code := http.StatusMovedPermanently // Permanent redirect, request with GET method
+		if httpMethod != "GET" {
+			code = http.StatusTemporaryRedirect
+		}
+		req.URL.Path = redirectPath
+		// logger here
+		http.Redirect(c.Response, req, req.URL.String(), code)
+		c.writermem.WriteHeaderNow()
+		return
+	}
+
+	c.handlers = a.router.Handlers
+	if len(allow) > 0 {
+		c.ServeError(http.StatusMethodNotAllowed, errors.New(optionalBody(a.Body405, default405Body)))
+		return
+	}
+	c.ServeError(http.StatusNotFound, errors.New(optionalBody(a.Body404, default404Body)))
+}
+
+// lookupRoute returns handlers of route matching request, or path request should
+// be redirected to, or methods allowed for request path when method is not allowed
+func (a *App) lookupRoute(c *Context) (handlers HandlersChain, ps Params, redirectPath string, allow string) {
+	httpMethod := c.Request.Method
+	path := c.Request.URL.Path
+
+	// hosts with domain-based group are routed exclusively to its routes
+	if handlers, ps, ok := a.lookupDomain(c); ok {
+		return handlers, ps, "", ""
+	}
+
+	// routes matching media type version take precedence
+	if handlers, ps = a.lookupVersioned(c); handlers != nil {
+		return
+	}
 
 	if root := a.router.trees[httpMethod]; root != nil {
-		if handlers, ps, tsr := root.getValue(path); handlers != nil {
-			c.handlers = handlers
-			c.Params = ps
-			c.Next()
-			c.writermem.WriteHeaderNow()
+		var tsr bool
+		if handlers, ps, tsr = root.getValue(path, c.Params); handlers != nil {
 			return
 		} else if httpMethod != "CONNECT" && path != "/" {
-			code := http.StatusMovedPermanently // Permanent redirect, request with GET method
-			if httpMethod != "GET" {
-				code = http.StatusTemporaryRedirect
-			}
 			if tsr && a.RedirectTrailingSlash {
-				req.URL.Path = path + "/"
+				redirectPath = path + "/"
 				if length := len(path); length > 1 && path[length-1] == '/' {
-					req.URL.Path = path[:length-1]
+					redirectPath = path[:length-1]
 				}
-				// logger here
-				http.Redirect(c.Response, req, req.URL.String(), code)
-				c.writermem.WriteHeaderNow()
 				return
 			}
 
 			if a.RedirectFixedPath {
 				fixedPath, found := root.findCaseInsensitivePath(CleanPath(path), a.RedirectTrailingSlash)
 				if found {
-					req.URL.Path = string(fixedPath)
-					// logger here
-					http.Redirect(c.Response, req, req.URL.String(), code)
-					c.writermem.WriteHeaderNow()
+					redirectPath = string(fixedPath)
 					return
 				}
 			}
@@ -496,17 +1164,53 @@ func (a *App) handleHTTPRequest(c *Context) {
 	}
 
 	if a.HandleMethodNotAllowed {
-		if allow := a.router.allowed(path, httpMethod); len(allow) > 0 {
-			c.handlers = a.router.Handlers
-			c.ServeError(http.StatusMethodNotAllowed, errors.New(default405Body))
-			return
-		}
+		allow = a.router.allowed(path, httpMethod)
 	}
 
-	c.handlers = a.router.Handlers
-	c.ServeError(http.StatusNotFound, errors.New(default404Body))
+	// unknown hosts without matching route are handled by wildcard domain handler
+	if allow == "" && a.router.domains.wildcard != nil {
+		handlers = a.router.domains.wildcard
+	}
+	return
+}
+
+// optionalBody returns configured response body, or fallback when it is not configured
+func optionalBody(body, fallback string) string {
+	if body == "" {
+		return fallback
+	}
+	return body
+}
+
+// serveRoute executes matched route handlers
+func (a *App) serveRoute(c *Context, handlers HandlersChain, ps Params) {
+	c.handlers = handlers
+	c.Params = ps
+	// apply global request deadline unless route is exempt
+	timeout := a.RequestTimeout > 0 && !hasNoRequestTimeout(handlers)
+	if timeout {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), a.RequestTimeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+	}
+	c.Next()
+	// request deadline fired before handler wrote response
+	if timeout && !c.Response.Written() && c.Request.Context().Err() == context.DeadlineExceeded {
+		c.ServeError(http.StatusServiceUnavailable, errors.New(default503Body))
+		return
+	}
+	// route has no terminal handler which responds
+	if a.EmptyResponseStatus > 0 && !c.Response.Written() && !c.writermem.statusSet {
+		c.ServeError(a.EmptyResponseStatus, errors.New(http.StatusText(a.EmptyResponseStatus)))
+		return
+	}
+	c.writermem.WriteHeaderNow()
 }
 
 func (a *App) allocateContext() *Context {
-	return &Context{app: a}
+	return &Context{
+		app:    a,
+		vars:   make(map[string]interface{}),
+		Params: make(Params, 0, a.router.maxParams()),
+	}
 }
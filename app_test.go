@@ -1,10 +1,24 @@
 package cucumber
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/AjdinHalac/cucumber/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
 )
 
 func TestAppServeHTTPDefault(t *testing.T) {
@@ -111,3 +125,918 @@ func TestAppRoutes(t *testing.T) {
 		})
 	}
 }
+
+func TestAppStartWithContext(t *testing.T) {
+
+	app := newTestAppInstance()
+	app.HTTPAddr = "127.0.0.1:0"
+	app.GRPCAddr = "127.0.0.1:0"
+	app.ShutdownDrainTimeout = time.Second
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- app.StartWithContext(ctx)
+	}()
+
+	// give servers time to start listening
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Errorf("StartWithContext returned error: %v", err)
+		}
+	case <-time.After(app.ShutdownDrainTimeout):
+		t.Errorf("StartWithContext did not return within %v", app.ShutdownDrainTimeout)
+	}
+}
+
+func TestAppRequestTimeout(t *testing.T) {
+
+	app := newTestAppInstance()
+	app.RequestTimeout = 10 * time.Millisecond
+
+	handler := func(ctx *Context) {
+		if _, ok := ctx.Request.Context().Deadline(); !ok {
+			ctx.Status(http.StatusOK)
+			return
+		}
+		<-ctx.Request.Context().Done()
+	}
+	// exemption does not depend on route prefix or parameters
+	api := app.Router().Group("/api")
+	api.GET("/slow", handler)
+	api.GET("/events/:id/stream", NoRequestTimeout, handler)
+
+	tt := []struct {
+		Path string
+		Code int
+	}{
+		{Path: "/api/slow", Code: http.StatusServiceUnavailable},
+		{Path: "/api/events/7/stream", Code: http.StatusOK},
+	}
+
+	for _, tc := range tt {
+		req, err := http.NewRequest("GET", tc.Path, nil)
+		if err != nil {
+			t.Errorf("An error occured. %v", err)
+		}
+
+		rr := httptest.NewRecorder()
+
+		app.ServeHTTP(rr, req)
+		if rr.Code != tc.Code {
+			t.Errorf("handler for %s returned wrong status code: got %v expected %v", tc.Path, rr.Code, tc.Code)
+		}
+	}
+}
+
+type deferredService struct {
+	name  string
+	inits *[]string
+}
+
+func (s *deferredService) DeferredInit(app *App) error {
+	*s.inits = append(*s.inits, s.name)
+	return nil
+}
+
+func TestAppDeferredInit(t *testing.T) {
+
+	inits := []string{}
+	socket := filepath.Join(t.TempDir(), "http.sock")
+
+	app := newTestAppInstance()
+	app.HTTPAddr = "unix:" + socket
+	app.Register(&deferredService{name: "first", inits: &inits})
+	app.Register(&deferredService{name: "second", inits: &inits})
+
+	if len(inits) != 0 {
+		t.Errorf("deferred init ran during registration: %v", inits)
+	}
+
+	servedInits := 0
+	app.GET("/", func(ctx *Context) {
+		servedInits = len(inits)
+		ctx.Status(http.StatusOK)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go app.StartWithContext(ctx)
+
+	client := http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return net.Dial("unix", socket)
+			},
+		},
+	}
+
+	var (
+		rr  *http.Response
+		err error
+	)
+	for i := 0; i < 50; i++ {
+		if rr, err = client.Get("http://unix/"); err == nil {
+			rr.Body.Close()
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("An error occured. %v", err)
+	}
+
+	if servedInits != 2 || inits[0] != "first" || inits[1] != "second" {
+		t.Errorf("deferred inits did not run before request was served: %v", inits)
+	}
+}
+
+type (
+	baseContextKey struct{}
+	connContextKey struct{}
+)
+
+func TestAppBaseContext(t *testing.T) {
+
+	socket := filepath.Join(t.TempDir(), "http.sock")
+
+	app := newTestAppInstance()
+	app.HTTPAddr = "unix:" + socket
+	app.BaseContext = func(net.Listener) context.Context {
+		return context.WithValue(context.Background(), baseContextKey{}, "base")
+	}
+	app.ConnContext = func(ctx context.Context, c net.Conn) context.Context {
+		return context.WithValue(ctx, connContextKey{}, "conn")
+	}
+
+	var base, conn interface{}
+	app.GET("/", func(ctx *Context) {
+		base = ctx.Request.Context().Value(baseContextKey{})
+		conn = ctx.Request.Context().Value(connContextKey{})
+		ctx.Status(http.StatusOK)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go app.StartWithContext(ctx)
+
+	client := http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return net.Dial("unix", socket)
+			},
+		},
+	}
+
+	var (
+		rr  *http.Response
+		err error
+	)
+	for i := 0; i < 50; i++ {
+		if rr, err = client.Get("http://unix/"); err == nil {
+			rr.Body.Close()
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("An error occured. %v", err)
+	}
+
+	if base != "base" || conn != "conn" {
+		t.Errorf("request context does not carry base and connection values: got %v and %v", base, conn)
+	}
+}
+
+// recordingLogger is log.Logger which records info entries
+type recordingLogger struct {
+	fields  log.Fields
+	entries *[]string
+}
+
+func newRecordingLogger() *recordingLogger {
+	return &recordingLogger{fields: log.Fields{}, entries: &[]string{}}
+}
+
+func (l *recordingLogger) record(args ...interface{}) {
+	*l.entries = append(*l.entries, fmt.Sprint(args...)+fmt.Sprint(l.fields["status"]))
+}
+
+func (l *recordingLogger) Debug(args ...interface{})                 {}
+func (l *recordingLogger) Debugf(format string, args ...interface{}) {}
+func (l *recordingLogger) Info(args ...interface{})                  { l.record(args...) }
+func (l *recordingLogger) Infof(format string, args ...interface{})  {}
+func (l *recordingLogger) Warn(args ...interface{})                  {}
+func (l *recordingLogger) Warnf(format string, args ...interface{})  {}
+func (l *recordingLogger) Error(args ...interface{})                 {}
+func (l *recordingLogger) Errorf(format string, args ...interface{}) {}
+func (l *recordingLogger) Fatal(args ...interface{})                 {}
+func (l *recordingLogger) Fatalf(format string, args ...interface{}) {}
+func (l *recordingLogger) Panic(args ...interface{})                 {}
+func (l *recordingLogger) Panicf(format string, args ...interface{}) {}
+
+func (l *recordingLogger) WithFields(fields log.Fields) log.Logger {
+	merged := log.Fields{}
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &recordingLogger{fields: merged, entries: l.entries}
+}
+
+func TestAppLogBackends(t *testing.T) {
+
+	first := newRecordingLogger()
+	second := newRecordingLogger()
+	explicit := newRecordingLogger()
+
+	opts := NewOptions()
+	opts.UseViewEngine = false
+	opts.UseSession = false
+	opts.UseTranslator = false
+	opts.Logger = explicit
+	opts.LogBackends = []log.Logger{first, second}
+
+	app := NewWithOptions(opts)
+	app.GET("/", func(ctx *Context) {
+		ctx.Status(http.StatusAccepted)
+	})
+
+	req, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Errorf("An error occured. %v", err)
+	}
+
+	app.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(*first.entries) != 1 || len(*second.entries) != 1 {
+		t.Fatalf("expected one entry per backend, got %v and %v", *first.entries, *second.entries)
+	}
+	if (*first.entries)[0] != "request-logger202" || (*first.entries)[0] != (*second.entries)[0] {
+		t.Errorf("backends received different entries: %v and %v", *first.entries, *second.entries)
+	}
+	if len(*explicit.entries) != 1 || (*explicit.entries)[0] != (*first.entries)[0] {
+		t.Errorf("explicit logger received %v, want %v", *explicit.entries, *first.entries)
+	}
+}
+
+func TestAppViewsLazyLoad(t *testing.T) {
+
+	root := t.TempDir()
+
+	opts := NewOptions()
+	opts.UseRequestLogger = false
+	opts.UseSession = false
+	opts.UseTranslator = false
+	opts.UseViewEngine = true
+	opts.ViewsLazyLoad = true
+	opts.ViewsRoot = root
+	opts.ViewsMasterLayout = ""
+
+	// views and partials folders do not exist yet
+	app := NewWithOptions(opts)
+	app.GET("/hello", func(ctx *Context) {
+		ctx.HTML(http.StatusOK, "hello", "cucumber")
+	})
+
+	if err := os.MkdirAll(filepath.Join(root, "partials"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "partials", "greeting.tpl"), []byte(`{{define "greeting"}}Hello{{end}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "hello.tpl"), []byte(`{{template "greeting"}} {{.model}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := performRequest(app, "GET", "/hello")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if w.Body.String() != "Hello cucumber" {
+		t.Errorf("unexpected body %q", w.Body.String())
+	}
+}
+
+func TestAppHotRegister(t *testing.T) {
+
+	app := newTestAppInstance()
+	app.GET("/", func(ctx *Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	// serve requests while route is registered
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			resp, err := http.Get(srv.URL + "/")
+			if err != nil {
+				t.Errorf("An error occured. %v", err)
+				return
+			}
+			resp.Body.Close()
+		}
+	}()
+
+	err := app.HotRegister("GET", "/plugin", func(ctx *Context) {
+		ctx.String(http.StatusOK, "plugin")
+	})
+	if err != nil {
+		t.Fatalf("HotRegister returned error: %v", err)
+	}
+	<-done
+
+	resp, err := http.Get(srv.URL + "/plugin")
+	if err != nil {
+		t.Fatalf("An error occured. %v", err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "plugin" {
+		t.Errorf("unexpected response %d %q", resp.StatusCode, body)
+	}
+
+	// duplicate route is reported as error
+	if err := app.HotRegister("GET", "/plugin", func(ctx *Context) {}); err == nil {
+		t.Errorf("expected error registering duplicate route")
+	}
+}
+
+func TestAppErrorBodies(t *testing.T) {
+
+	app := newTestAppInstance()
+	app.HandleMethodNotAllowed = true
+	app.Body404 = "nothing here"
+	app.Body405 = "try another method"
+	app.Body500 = "something went wrong"
+	app.GET("/fail", func(ctx *Context) {
+		ctx.ServeError(http.StatusInternalServerError, errors.New("database password leaked"))
+	})
+	app.GET("/bad", func(ctx *Context) {
+		ctx.ServeError(http.StatusBadRequest, errors.New("invalid id"))
+	})
+
+	w := performRequest(app, "GET", "/missing")
+	if w.Code != http.StatusNotFound || w.Body.String() != "nothing here" {
+		t.Errorf("unexpected not found response %d %q", w.Code, w.Body.String())
+	}
+
+	w = performRequest(app, "POST", "/fail")
+	if w.Code != http.StatusMethodNotAllowed || w.Body.String() != "try another method" {
+		t.Errorf("unexpected method not allowed response %d %q", w.Code, w.Body.String())
+	}
+
+	w = performRequest(app, "GET", "/fail")
+	if w.Code != http.StatusInternalServerError || w.Body.String() != "something went wrong" {
+		t.Errorf("unexpected internal error response %d %q", w.Code, w.Body.String())
+	}
+
+	w = performRequest(app, "GET", "/bad")
+	if w.Code != http.StatusBadRequest || w.Body.String() != "invalid id" {
+		t.Errorf("unexpected bad request response %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestAppGRPCShutdownTimeout(t *testing.T) {
+
+	socket := filepath.Join(t.TempDir(), "grpc.sock")
+	app := newTestAppInstance()
+	app.GRPCAddr = "unix:" + socket
+	app.ShutdownDrainTimeout = 10 * time.Second
+	app.GRPCShutdownTimeout = 100 * time.Millisecond
+
+	inFlight := make(chan struct{})
+	err := app.AddGRPCUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		close(inFlight)
+		// graceful stop does not cancel in-flight requests, hard stop does
+		<-ctx.Done()
+		return handler(ctx, req)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.RegisterServiceHandler(&healthService{health.NewServer()})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- app.StartWithContext(ctx)
+	}()
+
+	conn, err := grpc.Dial("grpc.sock",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		}),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	go healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{}, grpc.WaitForReady(true))
+	<-inFlight
+
+	start := time.Now()
+	cancel()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Errorf("StartWithContext returned error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("StartWithContext did not return")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("gRPC server stopped after %v", elapsed)
+	}
+}
+
+type ordersController struct{}
+
+func (ctrl *ordersController) Prefix() string {
+	return "/orders"
+}
+
+func (ctrl *ordersController) Routes() *Router {
+	r := NewRouter()
+	r.GET("/", func(c *Context) {
+		c.String(http.StatusOK, "orders")
+	})
+	return r
+}
+
+type ordersGroup struct{}
+
+func (g *ordersGroup) Services() []interface{} {
+	return []interface{}{&healthService{health.NewServer()}, &ordersController{}}
+}
+
+func (g *ordersGroup) Routes() *Router {
+	r := NewRouter()
+	r.GET("/orders-status", func(c *Context) {
+		c.String(http.StatusOK, "up")
+	})
+	return r
+}
+
+func TestAppRegisterServiceGroup(t *testing.T) {
+
+	app := newTestAppInstance()
+	app.ControllerPackage = "cucumber"
+	app.RegisterServiceGroup(&ordersGroup{})
+
+	w := performRequest(app, "GET", "/orders/")
+	if w.Code != http.StatusOK || w.Body.String() != "orders" {
+		t.Errorf("unexpected controller response %d %q", w.Code, w.Body.String())
+	}
+
+	w = performRequest(app, "GET", "/orders-status")
+	if w.Code != http.StatusOK || w.Body.String() != "up" {
+		t.Errorf("unexpected group route response %d %q", w.Code, w.Body.String())
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	go app.server.Serve(lis)
+	defer app.server.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	res, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("unexpected health status %v", res.Status)
+	}
+}
+
+type auditService struct {
+	Repo *testMemoryUserRepo
+}
+
+func (s *auditService) Autowired() {}
+
+type auditController struct {
+	ordersController
+	Audit *auditService
+}
+
+func TestAppDIStats(t *testing.T) {
+
+	app := newTestAppInstance()
+	app.ControllerPackage = "cucumber"
+	app.Register(&testMemoryUserRepo{})
+	app.Register(&auditService{})
+	if stats := app.DIStats(); stats.Injections != 0 {
+		t.Errorf("unexpected injections without DebugDI %d", stats.Injections)
+	}
+
+	app.DebugDI = true
+	audit := &auditService{}
+	app.Register(audit)
+	ctrl := &auditController{}
+	app.RegisterController(ctrl)
+
+	if audit.Repo == nil {
+		t.Error("service dependency not injected")
+	}
+	stats := app.DIStats()
+	if stats.Injections != 2 {
+		t.Errorf("unexpected injections %d", stats.Injections)
+	}
+	for _, name := range []string{"cucumber.auditService", "cucumber.auditController"} {
+		if _, ok := stats.ByName[name]; !ok {
+			t.Errorf("missing injection stats of %s in %v", name, stats.ByName)
+		}
+	}
+	if stats.Total < stats.ByName["cucumber.auditService"] {
+		t.Errorf("unexpected total %v", stats.Total)
+	}
+}
+
+type inventoryController struct {
+	prefix string
+	Repo   *testMemoryUserRepo
+	Audit  *auditService
+}
+
+func (ctrl *inventoryController) Prefix() string {
+	return ctrl.prefix
+}
+
+func (ctrl *inventoryController) Routes() *Router {
+	r := NewRouter()
+	r.GET("/", func(c *Context) {
+		c.String(http.StatusOK, ctrl.Repo.Find(ctrl.prefix))
+	})
+	return r
+}
+
+func newInventoryApp(n int) (*App, []interface{}) {
+	app := newTestAppInstance()
+	app.ControllerPackage = "cucumber"
+	app.Register(&testMemoryUserRepo{})
+	app.RegisterPackage(&auditService{})
+
+	ctrls := make([]interface{}, n)
+	for i := range ctrls {
+		ctrls[i] = &inventoryController{prefix: fmt.Sprintf("/inventory%d", i)}
+	}
+	return app, ctrls
+}
+
+func TestAppRegisterControllers(t *testing.T) {
+
+	app, ctrls := newInventoryApp(20)
+	app.RegisterControllers(ctrls...)
+
+	for i, ctrl := range ctrls {
+		if ctrl.(*inventoryController).Repo == nil || ctrl.(*inventoryController).Audit == nil {
+			t.Errorf("dependencies not injected into controller %d", i)
+		}
+		path := fmt.Sprintf("/inventory%d/", i)
+		w := performRequest(app, "GET", path)
+		if w.Code != http.StatusOK || w.Body.String() != "user "+path[:len(path)-1] {
+			t.Errorf("unexpected response of %s %d %q", path, w.Code, w.Body.String())
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("invalid controller registration did not panic")
+		}
+	}()
+	app.RegisterControllers(&inventoryController{prefix: "/valid"}, inventoryController{})
+}
+
+func BenchmarkAppRegisterController(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		app, ctrls := newInventoryApp(50)
+		b.StartTimer()
+		for _, ctrl := range ctrls {
+			app.RegisterController(ctrl)
+		}
+	}
+}
+
+func BenchmarkAppRegisterControllers(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		app, ctrls := newInventoryApp(50)
+		b.StartTimer()
+		app.RegisterControllers(ctrls...)
+	}
+}
+
+type shippingController struct {
+	ordersController
+}
+
+func TestAppRegisterControllerPathCollision(t *testing.T) {
+
+	app := newTestAppInstance()
+	app.ControllerPackage = "cucumber"
+	app.RegisterController(&ordersController{})
+
+	defer func() {
+		msg := fmt.Sprint(recover())
+		if !strings.Contains(msg, "`cucumber.shippingController`") || !strings.Contains(msg, "`/orders`") || !strings.Contains(msg, "`cucumber.ordersController`") {
+			t.Errorf("unexpected collision panic %q", msg)
+		}
+	}()
+	app.RegisterController(&shippingController{})
+}
+
+// clockService is a non-pointer service
+type clockService func() time.Time
+
+func (s clockService) Service() {}
+
+type clockAuditService struct {
+	Clock clockService
+}
+
+func (s clockAuditService) Autowired() {}
+
+type clockConsumer struct {
+	Clock clockService
+}
+
+func (s *clockConsumer) Autowired() {}
+
+func TestAppRegisterNonPointer(t *testing.T) {
+
+	app := newTestAppInstance()
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	app.Register(clockService(func() time.Time { return now }))
+
+	consumer := &clockConsumer{}
+	app.Register(consumer)
+	if consumer.Clock == nil || !consumer.Clock().Equal(now) {
+		t.Error("non-pointer service not injected")
+	}
+
+	tt := []struct {
+		Name    string
+		Service interface{}
+		Panic   string
+	}{
+		{Name: "autowired", Service: clockAuditService{}, Panic: "Autowired service `cucumber.clockAuditService` has to be pointer"},
+		{Name: "plain", Service: "value", Panic: "Service `string` has to be pointer or implement Service, Initer or DeferredIniter"},
+		{Name: "nil", Service: nil, Panic: "Service can not be nil"},
+	}
+
+	for _, tc := range tt {
+		func() {
+			defer func() {
+				if msg := fmt.Sprint(recover()); !strings.HasPrefix(msg, tc.Panic) {
+					t.Errorf("%s: unexpected panic %q", tc.Name, msg)
+				}
+			}()
+			app.Register(tc.Service)
+		}()
+	}
+}
+
+type orderedService struct {
+	name  string
+	order int
+	inits *[]string
+}
+
+func (s *orderedService) Init(app *App)  { *s.inits = append(*s.inits, s.name) }
+func (s *orderedService) InitOrder() int { return s.order }
+
+type plainIniter struct {
+	inits *[]string
+}
+
+func (s *plainIniter) Init(app *App) { *s.inits = append(*s.inits, "plain") }
+
+func TestAppInitOrder(t *testing.T) {
+
+	inits := []string{}
+	app := newTestAppInstance()
+	app.Register(&orderedService{name: "cache", order: 2, inits: &inits})
+	app.Register(&orderedService{name: "metrics", order: 2, inits: &inits})
+	app.Register(&plainIniter{inits: &inits})
+	app.Register(&orderedService{name: "config", order: 1, inits: &inits})
+
+	if len(inits) != 1 || inits[0] != "plain" {
+		t.Errorf("ordered services initialized during registration: %v", inits)
+	}
+
+	if err := app.DeferredInit(); err != nil {
+		t.Fatal(err)
+	}
+	app.Register(&orderedService{name: "late", inits: &inits})
+
+	expected := []string{"plain", "config", "cache", "metrics", "late"}
+	if strings.Join(inits, ",") != strings.Join(expected, ",") {
+		t.Errorf("unexpected init order %v, expected %v", inits, expected)
+	}
+}
+
+func TestAppWire(t *testing.T) {
+
+	app := newTestAppInstance()
+	audit := &auditService{}
+	app.Register(audit)
+	repo := &testMemoryUserRepo{}
+	app.container.Add(repo)
+
+	if audit.Repo != nil {
+		t.Fatal("dependency added to container injected before wiring")
+	}
+
+	if err := app.DeferredInit(); err != nil {
+		t.Fatal(err)
+	}
+	if audit.Repo != repo {
+		t.Error("dependency added to container not injected on start")
+	}
+}
+
+type ledgerController struct {
+	ordersController
+	Repo *testMemoryUserRepo
+}
+
+func TestAppRegisterControllerBeforeDependencies(t *testing.T) {
+
+	app := newTestAppInstance()
+	app.ControllerPackage = "cucumber"
+	ctrl := &ledgerController{}
+	app.RegisterController(ctrl)
+
+	audit := &auditService{}
+	app.Register(audit)
+	repo := &testMemoryUserRepo{}
+	app.Register(repo)
+
+	if ctrl.Repo != repo {
+		t.Error("dependency registered after controller not injected")
+	}
+	if audit.Repo != repo {
+		t.Error("dependency registered after service not injected")
+	}
+
+	// injected fields are not replaced
+	app.Register(&testMemoryUserRepo{})
+	if ctrl.Repo != repo || audit.Repo != repo {
+		t.Error("injected field replaced by later registered dependency")
+	}
+}
+
+type statusRoutes struct{}
+
+func (ctrl *statusRoutes) Routes() *Router {
+	r := NewRouter()
+	r.GET("/status", func(c *Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	return r
+}
+
+type IndexController struct {
+	statusRoutes
+}
+
+type V2IndexController struct {
+	statusRoutes
+}
+
+func (ctrl *V2IndexController) Prefix() string {
+	return "/"
+}
+
+func TestAppControllerIndexPrefix(t *testing.T) {
+
+	app := newTestAppInstance()
+	app.ControllerPackage = "cucumber"
+	app.ControllerIndexPrefix = "/api"
+	app.RegisterController(&IndexController{})
+	// Prefix override of Index controller is honored
+	app.RegisterController(&V2IndexController{})
+
+	for _, path := range []string{"/api/status", "/v2/status"} {
+		if w := performRequest(app, "GET", path); w.Code != http.StatusOK {
+			t.Errorf("unexpected Index controller response of %s %d", path, w.Code)
+		}
+	}
+}
+
+func TestAppDeregister(t *testing.T) {
+
+	app := newTestAppInstance()
+	app.GET("/", func(ctx *Context) {
+		ctx.Status(http.StatusOK)
+	})
+	app.GET("/plugin/:name", func(ctx *Context) {
+		ctx.String(http.StatusOK, "plugin "+ctx.Param("name"))
+	})
+
+	if w := performRequest(app, "GET", "/plugin/pdf"); w.Code != http.StatusOK || w.Body.String() != "plugin pdf" {
+		t.Errorf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	// serve requests while route is deregistered
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			resp, err := http.Get(srv.URL + "/plugin/pdf")
+			if err != nil {
+				t.Errorf("An error occured. %v", err)
+				return
+			}
+			resp.Body.Close()
+		}
+	}()
+
+	if err := app.Deregister("GET", "/plugin/:name"); err != nil {
+		t.Fatalf("Deregister returned error: %v", err)
+	}
+	<-done
+
+	if w := performRequest(app, "GET", "/plugin/pdf"); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for deregistered route, got %d", w.Code)
+	}
+	if w := performRequest(app, "GET", "/"); w.Code != http.StatusOK {
+		t.Errorf("expected 200 for remaining route, got %d", w.Code)
+	}
+
+	// unknown route is reported as error
+	if err := app.Deregister("GET", "/plugin/:name"); !errors.Is(err, ErrRouteNotFound) {
+		t.Errorf("expected ErrRouteNotFound, got %v", err)
+	}
+	if err := app.Deregister("POST", "/"); !errors.Is(err, ErrRouteNotFound) {
+		t.Errorf("expected ErrRouteNotFound, got %v", err)
+	}
+}
+
+func TestAppBasePath(t *testing.T) {
+
+	staticDir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(staticDir, "app.js"), []byte("js"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := NewOptions()
+	opts.UseRequestLogger = false
+	opts.BasePath = "myservice/"
+	opts.ServeStatic = true
+	opts.StaticDir = staticDir
+	opts.ControllerPackage = "cucumber"
+	app := NewWithOptions(opts)
+
+	app.GET("/users", func(ctx *Context) {
+		ctx.String(http.StatusOK, "users")
+	})
+	app.Router().Group("/admin").GET("/stats", func(ctx *Context) {
+		ctx.String(http.StatusOK, "stats")
+	})
+	app.RegisterController(&ordersController{})
+
+	for _, path := range []string{"/myservice/users", "/myservice/admin/stats", "/myservice/orders/", "/myservice/static/app.js"} {
+		if w := performRequest(app, "GET", path); w.Code != http.StatusOK {
+			t.Errorf("expected 200 for %s, got %d", path, w.Code)
+		}
+	}
+	if w := performRequest(app, "GET", "/users"); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 outside base path, got %d", w.Code)
+	}
+
+	// trailing slash redirect keeps base path
+	w := performRequest(app, "GET", "/myservice/users/")
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/myservice/users" {
+		t.Errorf("unexpected redirect %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	if url := app.URL("/users"); url != "/myservice/users" {
+		t.Errorf("unexpected url %q", url)
+	}
+}
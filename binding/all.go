@@ -0,0 +1,41 @@
+package binding
+
+import (
+	"io"
+	"net/http"
+	"strings"
+)
+
+// BindAll binds data from multiple sources of the request into obj.
+//
+// Sources are applied in following order, so that later sources override
+// values set by earlier ones:
+//
+//  1. JSON body (`json` tags), only when Content-Type is application/json
+//  2. query string (`form` tags)
+//  3. route params (`uri` tags)
+//
+// Validation runs once after all sources are merged.
+func BindAll(req *http.Request, params map[string][]string, obj interface{}) error {
+	if req != nil && req.Body != nil && req.Body != http.NoBody && isJSON(req.Header.Get("Content-Type")) {
+		if err := decodeJSONBody(req.Body, obj); err != nil && err != io.EOF {
+			return err
+		}
+	}
+	if req != nil && req.URL != nil {
+		if err := mapForm(obj, req.URL.Query()); err != nil {
+			return err
+		}
+	}
+	if err := mapURI(obj, params); err != nil {
+		return err
+	}
+	return validate(obj)
+}
+
+func isJSON(contentType string) bool {
+	if i := strings.IndexAny(contentType, "; "); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return contentType == MIMEJSON
+}
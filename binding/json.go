@@ -37,6 +37,14 @@ func (jsonBinding) BindBody(body []byte, obj interface{}) error {
 }
 
 func decodeJSON(r io.Reader, obj interface{}) error {
+	if err := decodeJSONBody(r, obj); err != nil {
+		return err
+	}
+	return validate(obj)
+}
+
+// decodeJSONBody decodes JSON from reader into obj without validating it
+func decodeJSONBody(r io.Reader, obj interface{}) error {
 	decoder := json.NewDecoder(r)
 	if EnableDecoderUseNumber {
 		decoder.UseNumber()
@@ -44,8 +52,5 @@ func decodeJSON(r io.Reader, obj interface{}) error {
 	if EnableDecoderDisallowUnknownFields {
 		decoder.DisallowUnknownFields()
 	}
-	if err := decoder.Decode(obj); err != nil {
-		return err
-	}
-	return validate(obj)
+	return decoder.Decode(obj)
 }
@@ -0,0 +1,126 @@
+package cucumber
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"text/tabwriter"
+)
+
+// ErrUnknownCommand is returned by CLI.Run for commands which are not registered
+var ErrUnknownCommand = errors.New("unknown command")
+
+// CLI runs administrative commands of the application, so the same binary
+// can serve requests and run management tasks
+//
+//	func main() {
+//		app := cucumber.New()
+//		...
+//		if err := app.CLI().Run(os.Args[1:]); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+type CLI struct {
+	// Out receives output of built-in commands, os.Stdout by default
+	Out io.Writer
+
+	app      *App
+	commands map[string]cliCommand
+	names    []string
+}
+
+type cliCommand struct {
+	description string
+	fn          func(args []string) error
+}
+
+// CLI creates command line interface with built-in commands "serve",
+// "routes" and "version"
+func (a *App) CLI() *CLI {
+	cli := &CLI{
+		Out:      os.Stdout,
+		app:      a,
+		commands: make(map[string]cliCommand),
+	}
+	cli.AddCommand("serve", "Start HTTP and gRPC servers", cli.serve)
+	cli.AddCommand("routes", "List registered routes", cli.routes)
+	cli.AddCommand("version", "Print application version", cli.version)
+	return cli
+}
+
+// AddCommand registers command, replacing command with the same name
+func (cli *CLI) AddCommand(name string, description string, fn func(args []string) error) *CLI {
+	if name == "" {
+		panic("command name can not be empty")
+	}
+	if fn == nil {
+		panic("command " + name + " has no function")
+	}
+	if _, ok := cli.commands[name]; !ok {
+		cli.names = append(cli.names, name)
+	}
+	cli.commands[name] = cliCommand{description: description, fn: fn}
+	return cli
+}
+
+// Run runs command named by the first argument with the remaining arguments,
+// usage is printed when no command is given
+func (cli *CLI) Run(args []string) error {
+	if len(args) == 0 || args[0] == "help" {
+		cli.usage()
+		return nil
+	}
+	cmd, ok := cli.commands[args[0]]
+	if !ok {
+		cli.usage()
+		return fmt.Errorf("%w: %s", ErrUnknownCommand, args[0])
+	}
+	return cmd.fn(args[1:])
+}
+
+func (cli *CLI) usage() {
+	fmt.Fprintf(cli.Out, "Usage: %s <command> [arguments]\n\nCommands:\n", cli.app.Name)
+	w := tabwriter.NewWriter(cli.Out, 0, 4, 2, ' ', 0)
+	for _, name := range cli.names {
+		fmt.Fprintf(w, "  %s\t%s\n", name, cli.commands[name].description)
+	}
+	w.Flush()
+}
+
+// serve starts application until interrupted
+func (cli *CLI) serve(args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+	return cli.app.StartWithContext(ctx)
+}
+
+// routes lists routes sorted by path, method and version
+func (cli *CLI) routes(args []string) error {
+	routes := cli.app.Router().Routes()
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		if routes[i].Method != routes[j].Method {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Version < routes[j].Version
+	})
+
+	w := tabwriter.NewWriter(cli.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATH\tVERSION\tHANDLER")
+	for _, route := range routes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", route.Method, route.Path, route.Version, route.HandlerName)
+	}
+	return w.Flush()
+}
+
+func (cli *CLI) version(args []string) error {
+	_, err := fmt.Fprintf(cli.Out, "%s %s\n", cli.app.Name, cli.app.Version)
+	return err
+}
@@ -0,0 +1,40 @@
+package cucumber
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCLI(t *testing.T) {
+	app := newTestAppInstance()
+	app.GET("/orders", func(c *Context) { c.Status(http.StatusOK) })
+	app.POST("/orders/:id/cancel", func(c *Context) { c.Status(http.StatusOK) })
+
+	var out bytes.Buffer
+	cli := app.CLI()
+	cli.Out = &out
+
+	assert.NoError(t, cli.Run([]string{"routes"}))
+	assert.Contains(t, out.String(), "GET     /orders")
+	assert.Contains(t, out.String(), "POST    /orders/:id/cancel")
+
+	out.Reset()
+	assert.NoError(t, cli.Run([]string{"version"}))
+	assert.Equal(t, app.Name+" "+app.Version+"\n", out.String())
+
+	var migrated []string
+	cli.AddCommand("migrate", "Run database migrations", func(args []string) error {
+		migrated = args
+		return errors.New("migration failed")
+	})
+	assert.EqualError(t, cli.Run([]string{"migrate", "up"}), "migration failed")
+	assert.Equal(t, []string{"up"}, migrated)
+
+	out.Reset()
+	assert.ErrorIs(t, cli.Run([]string{"seed"}), ErrUnknownCommand)
+	assert.Contains(t, out.String(), "migrate  Run database migrations")
+}
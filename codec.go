@@ -0,0 +1,240 @@
+package cucumber
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AjdinHalac/cucumber/binding"
+	"github.com/AjdinHalac/cucumber/render"
+)
+
+var (
+	// ErrUnsupportedMediaType is served when there is no decoder registered
+	// for request Content-Type
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+	// ErrNotAcceptable is served when there is no encoder registered
+	// for any of the types accepted by client
+	ErrNotAcceptable = errors.New("not acceptable")
+)
+
+// BodyDecoder decodes request body into obj
+type BodyDecoder interface {
+	Decode(req *http.Request, obj interface{}) error
+}
+
+// BodyDecoderFunc is an adapter to allow the use of ordinary functions as BodyDecoder
+type BodyDecoderFunc func(req *http.Request, obj interface{}) error
+
+// Decode calls f(req, obj)
+func (f BodyDecoderFunc) Decode(req *http.Request, obj interface{}) error {
+	return f(req, obj)
+}
+
+// BodyEncoder encodes obj into response body
+type BodyEncoder interface {
+	Encode(w io.Writer, obj interface{}) error
+}
+
+// BodyEncoderFunc is an adapter to allow the use of ordinary functions as BodyEncoder
+type BodyEncoderFunc func(w io.Writer, obj interface{}) error
+
+// Encode calls f(w, obj)
+func (f BodyEncoderFunc) Encode(w io.Writer, obj interface{}) error {
+	return f(w, obj)
+}
+
+// binderDecoder adapts binding.Binder to BodyDecoder, binder validates obj by itself
+type binderDecoder struct {
+	binding.Binder
+}
+
+func (d binderDecoder) Decode(req *http.Request, obj interface{}) error {
+	return d.Bind(req, obj)
+}
+
+// codecs holds body decoders and encoders registered per content type
+type codecs struct {
+	decoders map[string]BodyDecoder
+	encoders map[string]BodyEncoder
+
+	// decompressors of request body per Content-Encoding, see DecompressBody
+	decompressors map[string]DecompressorFunc
+
+	// encoder content types in registration order, first one is used
+	// when client accepts any content type
+	encoderTypes []string
+}
+
+func newCodecs() *codecs {
+	c := &codecs{
+		decoders: make(map[string]BodyDecoder),
+		encoders: make(map[string]BodyEncoder),
+
+		decompressors: map[string]DecompressorFunc{
+			"gzip":    gzipDecompressor,
+			"x-gzip":  gzipDecompressor,
+			"deflate": deflateDecompressor,
+		},
+	}
+
+	c.decoders[binding.MIMEJSON] = binderDecoder{binding.JSON}
+	c.decoders[binding.MIMEXML] = binderDecoder{binding.XML}
+	c.decoders[binding.MIMEXML2] = binderDecoder{binding.XML}
+	c.decoders[binding.MIMEYAML] = binderDecoder{binding.YAML}
+	c.decoders[binding.MIMEPOSTForm] = binderDecoder{binding.Form}
+	c.decoders[binding.MIMEMultipartPOSTForm] = binderDecoder{binding.FormMultipart}
+
+	c.addEncoder(binding.MIMEJSON, BodyEncoderFunc(func(w io.Writer, obj interface{}) error {
+		return json.NewEncoder(w).Encode(obj)
+	}))
+	c.addEncoder(binding.MIMEXML, BodyEncoderFunc(func(w io.Writer, obj interface{}) error {
+		return render.XML{Data: obj}.Render(w)
+	}))
+
+	return c
+}
+
+func (c *codecs) addEncoder(contentType string, encoder BodyEncoder) {
+	contentType = normalizeContentType(contentType)
+	if _, ok := c.encoders[contentType]; !ok {
+		c.encoderTypes = append(c.encoderTypes, contentType)
+	}
+	c.encoders[contentType] = encoder
+}
+
+// negotiate returns content type and encoder matching one of accepted types
+func (c *codecs) negotiate(accepted []string) (string, BodyEncoder, bool) {
+	for _, accept := range accepted {
+		if accept == "*/*" || accept == "" {
+			contentType := c.encoderTypes[0]
+			return contentType, c.encoders[contentType], true
+		}
+		if strings.HasSuffix(accept, "/*") {
+			for _, contentType := range c.encoderTypes {
+				if strings.HasPrefix(contentType, accept[:len(accept)-1]) {
+					return contentType, c.encoders[contentType], true
+				}
+			}
+			continue
+		}
+		if encoder, ok := c.encoders[accept]; ok {
+			return accept, encoder, true
+		}
+	}
+	return "", nil, false
+}
+
+// RegisterDecoder registers decoder used by Context.Bind for requests with given Content-Type
+//
+// Decoders for JSON, XML, YAML and form content types are registered by default.
+// Objects decoded by custom decoders are validated with binding.Validator.
+func (a *App) RegisterDecoder(contentType string, decoder BodyDecoder) *App {
+	if decoder == nil {
+		panic("nil decoder registered for '" + contentType + "'")
+	}
+	a.codecs.decoders[normalizeContentType(contentType)] = decoder
+	return a
+}
+
+// RegisterEncoder registers encoder used by Context.Negotiate for clients accepting given content type
+//
+// Encoders for JSON and XML are registered by default, JSON is used
+// when client accepts any content type.
+func (a *App) RegisterEncoder(contentType string, encoder BodyEncoder) *App {
+	if encoder == nil {
+		panic("nil encoder registered for '" + contentType + "'")
+	}
+	a.codecs.addEncoder(contentType, encoder)
+	return a
+}
+
+// Negotiate serializes obj with encoder matching Accept header of the request,
+// or Context.Accepted when set, and writes it into the response body.
+//
+// It responds with 406 Not Acceptable when no registered encoder matches.
+func (c *Context) Negotiate(code int, obj interface{}) {
+	accepted := c.Accepted
+	if len(accepted) == 0 {
+		accepted = parseAccept(c.requestHeader("Accept"))
+	}
+
+	contentType, encoder, ok := c.app.codecs.negotiate(accepted)
+	if !ok {
+		c.Abort()
+		c.ServeError(http.StatusNotAcceptable, ErrNotAcceptable)
+		return
+	}
+
+	r := encoderRenderer{
+		encoder:     encoder,
+		contentType: contentType,
+		data:        obj,
+	}
+	c.SetContentType(r.ContentType())
+	c.Render(code, r)
+}
+
+// encoderRenderer renders data using BodyEncoder
+type encoderRenderer struct {
+	encoder     BodyEncoder
+	contentType string
+	data        interface{}
+}
+
+func (r encoderRenderer) Render(out io.Writer) error {
+	return r.encoder.Encode(out, r.data)
+}
+
+func (r encoderRenderer) ContentType() []string {
+	return []string{r.contentType}
+}
+
+// parseAccept returns media types from Accept header sorted by quality
+func parseAccept(header string) []string {
+	if header == "" {
+		return []string{"*/*"}
+	}
+
+	type mediaRange struct {
+		value   string
+		quality float64
+	}
+
+	parts := strings.Split(header, ",")
+	ranges := make([]mediaRange, 0, len(parts))
+	for _, part := range parts {
+		params := strings.Split(part, ";")
+		r := mediaRange{value: normalizeContentType(params[0]), quality: 1}
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					r.quality = q
+				}
+			}
+		}
+		if r.quality > 0 {
+			ranges = append(ranges, r)
+		}
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	accepted := make([]string, len(ranges))
+	for i, r := range ranges {
+		accepted[i] = r.value
+	}
+	return accepted
+}
+
+func normalizeContentType(contentType string) string {
+	return strings.ToLower(strings.TrimSpace(filterFlags(strings.TrimSpace(contentType))))
+}
@@ -0,0 +1,113 @@
+package cucumber
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type csvRecord struct {
+	Name  string `json:"name" xml:"name" binding:"required"`
+	Email string `json:"email" xml:"email"`
+}
+
+func TestAppRegisterDecoder(t *testing.T) {
+	app := newTestAppInstance()
+	app.RegisterDecoder("text/csv", BodyDecoderFunc(func(req *http.Request, obj interface{}) error {
+		fields, err := csv.NewReader(req.Body).Read()
+		if err != nil {
+			return err
+		}
+		record := obj.(*csvRecord)
+		record.Name, record.Email = fields[0], fields[1]
+		return nil
+	}))
+
+	var bound csvRecord
+	executed := false
+	app.POST("/records", func(c *Context) {
+		bound = csvRecord{}
+		if !c.MustBind(&bound) {
+			return
+		}
+		c.Status(http.StatusCreated)
+	}, func(c *Context) {
+		executed = true
+	})
+
+	send := func(contentType, body string) int {
+		req, _ := http.NewRequest("POST", "/records", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusCreated, send("text/csv; charset=utf-8", "cucumber,cucumber@example.com"))
+	assert.Equal(t, csvRecord{Name: "cucumber", Email: "cucumber@example.com"}, bound)
+
+	// custom decoders are validated
+	assert.Equal(t, http.StatusBadRequest, send("text/csv", ",cucumber@example.com"))
+
+	assert.Equal(t, http.StatusCreated, send("application/json", `{"name":"json"}`))
+	assert.Equal(t, "json", bound.Name)
+
+	executed = false
+	assert.Equal(t, http.StatusUnsupportedMediaType, send("application/msgpack", "\x81"))
+	assert.Equal(t, http.StatusBadRequest, send("application/json", `{"name":`))
+	// chain is aborted on bind failure
+	assert.False(t, executed)
+}
+
+func TestContextNegotiate(t *testing.T) {
+	app := newTestAppInstance()
+	app.RegisterEncoder("text/csv", BodyEncoderFunc(func(w io.Writer, obj interface{}) error {
+		record := obj.(csvRecord)
+		writer := csv.NewWriter(w)
+		if err := writer.Write([]string{record.Name, record.Email}); err != nil {
+			return err
+		}
+		writer.Flush()
+		return writer.Error()
+	}))
+	executed := false
+	app.GET("/record", func(c *Context) {
+		c.Negotiate(http.StatusOK, csvRecord{Name: "cucumber", Email: "cucumber@example.com"})
+	}, func(c *Context) {
+		executed = true
+	})
+
+	get := func(accept string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/record", nil)
+		req.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, `{"name":"cucumber","email":"cucumber@example.com"}`+"\n", w.Body.String())
+
+	w = get("application/json;q=0.5, text/csv")
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	assert.Equal(t, "cucumber,cucumber@example.com\n", w.Body.String())
+
+	w = get("text/html, application/*;q=0.8")
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	w = get("application/xml")
+	assert.Equal(t, xml.Header+"<csvRecord><name>cucumber</name><email>cucumber@example.com</email></csvRecord>", w.Body.String())
+
+	executed = false
+	w = get("text/html")
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	assert.False(t, executed)
+}
@@ -0,0 +1,35 @@
+package cucumber
+
+import "strings"
+
+// When returns a middleware running given middleware only for requests
+// matching condition, other requests continue with the next handler
+//
+//	app.Use(cucumber.When(func(c *cucumber.Context) bool {
+//		return c.Header("X-Debug") != ""
+//	}, debugMiddleware))
+func When(condition func(*Context) bool, middleware HandlerFunc) HandlerFunc {
+	return func(c *Context) {
+		if condition(c) {
+			middleware(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+// WhenEnv returns a middleware running given middleware only when
+// application runs in env, see Options.Env
+func WhenEnv(env string, middleware HandlerFunc) HandlerFunc {
+	return When(func(c *Context) bool {
+		return c.app.Env == env
+	}, middleware)
+}
+
+// WhenPath returns a middleware running given middleware only for
+// requests which path starts with prefix
+func WhenPath(prefix string, middleware HandlerFunc) HandlerFunc {
+	return When(func(c *Context) bool {
+		return strings.HasPrefix(c.Request.URL.Path, prefix)
+	}, middleware)
+}
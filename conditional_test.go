@@ -0,0 +1,69 @@
+package cucumber
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// signatureMiddleware records its signature for each request it handles
+func signatureMiddleware(signature string, executed *[]string) HandlerFunc {
+	return func(c *Context) {
+		*executed = append(*executed, signature)
+		c.Next()
+	}
+}
+
+func TestWhenEnv(t *testing.T) {
+	for _, env := range []string{"development", "production", "staging"} {
+		var executed []string
+		app := newTestAppInstance()
+		app.Env = env
+		app.Use(WhenEnv("development", signatureMiddleware("detailed", &executed)))
+		app.Use(WhenEnv("production", signatureMiddleware("sampled", &executed)))
+		app.GET("/", func(c *Context) {
+			executed = append(executed, "handler")
+			c.Status(http.StatusOK)
+		})
+
+		w := performRequest(app, "GET", "/")
+		assert.Equal(t, http.StatusOK, w.Code, env)
+		switch env {
+		case "development":
+			assert.Equal(t, []string{"detailed", "handler"}, executed, env)
+		case "production":
+			assert.Equal(t, []string{"sampled", "handler"}, executed, env)
+		default:
+			assert.Equal(t, []string{"handler"}, executed, env)
+		}
+	}
+}
+
+func TestWhenPath(t *testing.T) {
+	var executed []string
+	app := newTestAppInstance()
+	app.Use(WhenPath("/admin", signatureMiddleware("admin", &executed)))
+	app.Use(When(func(c *Context) bool {
+		return c.Header("X-Debug") != ""
+	}, func(c *Context) {
+		c.Abort()
+		c.String(http.StatusTeapot, "debug")
+	}))
+	app.GET("/admin/users", func(c *Context) { c.Status(http.StatusOK) })
+	app.GET("/users", func(c *Context) { c.Status(http.StatusOK) })
+
+	performRequest(app, "GET", "/users")
+	assert.Empty(t, executed)
+
+	performRequest(app, "GET", "/admin/users")
+	assert.Equal(t, []string{"admin"}, executed)
+
+	// middleware can abort matching requests
+	req, _ := http.NewRequest("GET", "/users", nil)
+	req.Header.Set("X-Debug", "1")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusTeapot, w.Code)
+}
@@ -0,0 +1,29 @@
+package cucumber
+
+import "net/http"
+
+// RequireContentType returns a middleware that responds with 415 Unsupported Media Type
+// when request with body has Content-Type which is not one of given types
+//
+// Content-Type parameters, e.g. charset, are ignored. Requests without body are passed through:
+//
+//	api := router.Group("/api", cucumber.RequireContentType("application/json"))
+func RequireContentType(types ...string) HandlerFunc {
+	allowed := make(map[string]bool, len(types))
+	for _, t := range types {
+		allowed[normalizeContentType(t)] = true
+	}
+
+	return func(c *Context) {
+		if c.Request.ContentLength == 0 && len(c.Request.TransferEncoding) == 0 {
+			c.Next()
+			return
+		}
+		if !allowed[normalizeContentType(c.ContentType())] {
+			c.Abort()
+			c.ServeError(http.StatusUnsupportedMediaType, ErrUnsupportedMediaType)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,43 @@
+package cucumber
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireContentType(t *testing.T) {
+	handled := false
+	app := newTestAppInstance()
+	api := app.Router().Group("/api", RequireContentType("application/json"))
+	api.Any("/users", func(c *Context) {
+		handled = true
+		c.Status(http.StatusOK)
+	})
+
+	send := func(method, contentType, body string) int {
+		handled = false
+		req, _ := http.NewRequest(method, "/api/users", strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send("POST", "application/json; charset=utf-8", `{}`))
+	assert.True(t, handled)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, send("POST", "", `{}`))
+	assert.False(t, handled)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, send("PUT", "text/plain", `{}`))
+	assert.False(t, handled)
+
+	assert.Equal(t, http.StatusOK, send("GET", "", ""))
+	assert.True(t, handled)
+}
@@ -7,6 +7,7 @@ import (
 	"html/template"
 	"io"
 	"io/ioutil"
+	"math"
 	"mime/multipart"
 	"net"
 	"net/http"
@@ -16,7 +17,6 @@ import (
 	"time"
 
 	"github.com/AjdinHalac/cucumber/binding"
-	"github.com/AjdinHalac/cucumber/i18n"
 	"github.com/AjdinHalac/cucumber/log"
 	"github.com/AjdinHalac/cucumber/render"
 )
@@ -47,6 +47,35 @@ type Context struct {
 	Accepted []string
 
 	logger log.Logger
+
+	// logFields holds fields added with LogField not yet added to logger
+	logFields []logField
+
+	// vars is a per-request map shared by middlewares and handlers, see Vars
+	vars map[string]interface{}
+
+	// clientIP caches ClientIP of current request
+	clientIP       string
+	clientIPCached bool
+
+	// paramAliases maps old path parameter names of matched route, see Router.ParamAlias
+	paramAliases map[string]string
+
+	// flags caches feature flag evaluations of current request
+	flags map[string]bool
+
+	// queryCache caches the query result from c.Request.URL.Query().
+	queryCache url.Values
+
+	// formCache caches c.Request.PostForm, which contains the parsed form data from POST, PATCH,
+	// or PUT body parameters.
+	formCache url.Values
+}
+
+// logField is a structured logging field added with Context.LogField
+type logField struct {
+	key   string
+	value interface{}
 }
 
 /************************************/
@@ -62,6 +91,16 @@ func (c *Context) reset() {
 	c.Errors = c.Errors[0:0]
 	c.Accepted = nil
 	c.logger = nil
+	c.logFields = c.logFields[0:0]
+	for key := range c.vars {
+		delete(c.vars, key)
+	}
+	c.clientIP = ""
+	c.clientIPCached = false
+	c.paramAliases = nil
+	c.flags = nil
+	c.queryCache = nil
+	c.formCache = nil
 }
 
 // Copy returns a copy of the current context that can be safely used outside the request's scope.
@@ -72,6 +111,18 @@ func (c *Context) Copy() *Context {
 	cp.Response = &cp.writermem
 	cp.index = abortIndex
 	cp.handlers = nil
+	// params backing array is reused by the pooled context
+	cp.Params = make(Params, len(c.Params))
+	copy(cp.Params, c.Params)
+	cp.logFields = append([]logField(nil), c.logFields...)
+	cp.vars = make(map[string]interface{}, len(c.vars))
+	for key, value := range c.vars {
+		cp.vars[key] = value
+	}
+	cp.flags = make(map[string]bool, len(c.flags))
+	for name, enabled := range c.flags {
+		cp.flags[name] = enabled
+	}
 	return &cp
 }
 
@@ -116,7 +167,17 @@ func (c *Context) IsAborted() bool {
 /************************************/
 
 // Logger gets application Logger instance
+//
+// Fields added with LogField are added to the logger on first call.
 func (c *Context) Logger() log.Logger {
+	if len(c.logFields) > 0 {
+		fields := make(log.Fields, len(c.logFields))
+		for _, f := range c.logFields {
+			fields[f.key] = f.value
+		}
+		c.logFields = c.logFields[0:0]
+		c.logger = c.Logger().WithFields(fields)
+	}
 	if c.logger != nil {
 		return c.logger
 	}
@@ -131,11 +192,30 @@ func (c *Context) LogFields(fields log.Fields) {
 	c.logger = c.Logger().WithFields(fields)
 }
 
+// LogField adds single field to context Logger
+//
+// Unlike LogFields it does not allocate logger for each call, fields are
+// accumulated on pooled context and added to the logger at once by Logger.
+func (c *Context) LogField(key string, value interface{}) {
+	c.logFields = append(c.logFields, logField{key: key, value: value})
+}
+
 // AppOptions returns copy of application Options object
 func (c *Context) AppOptions() Options {
 	return c.app.Options
 }
 
+// Service fills value pointed by ptr with service registered in app container,
+// e.g. implementation of the pointed interface:
+//
+//	var repo UserRepo
+//	if err := c.Service(&repo); err != nil {
+//		...
+//	}
+func (c *Context) Service(ptr interface{}) error {
+	return c.app.container.Resolve(ptr)
+}
+
 /************************************/
 /********* ERROR MANAGEMENT *********/
 /************************************/
@@ -274,6 +354,56 @@ func (c *Context) GetStringMapStringSlice(key string) (smss map[string][]string)
 	return
 }
 
+// Vars returns mutable map of request variables, used to pass values
+// from middlewares to handlers
+//
+//	c.Vars()["userID"] = user.ID
+func (c *Context) Vars() map[string]interface{} {
+	if c.vars == nil {
+		c.vars = make(map[string]interface{})
+	}
+	return c.vars
+}
+
+// VarString returns request variable as a string, ie: (value, true).
+// If the variable does not exist or is not a string it returns ("", false)
+func (c *Context) VarString(key string) (string, bool) {
+	s, ok := c.vars[key].(string)
+	return s, ok
+}
+
+// VarInt64 returns request variable of any integer type as an int64, ie: (value, true).
+// If the variable does not exist or is not an integer fitting int64 it returns (0, false)
+func (c *Context) VarInt64(key string) (int64, bool) {
+	switch v := c.vars[key].(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint:
+		if uint64(v) <= math.MaxInt64 {
+			return int64(v), true
+		}
+	case uint64:
+		if v <= math.MaxInt64 {
+			return int64(v), true
+		}
+	}
+	return 0, false
+}
+
 /************************************/
 /************ INPUT DATA ************/
 /************************************/
@@ -282,7 +412,13 @@ func (c *Context) GetStringMapStringSlice(key string) (smss map[string][]string)
 //
 // It is a shortcut for c.Params.ByName(key)
 func (c *Context) Param(key string) string {
-	return c.Params.ByName(key)
+	if value, ok := c.Params.Get(key); ok {
+		return value
+	}
+	if name, ok := c.paramAliases[key]; ok {
+		return c.Params.ByName(name)
+	}
+	return ""
 }
 
 // Query returns the keyed url query value if it exists,
@@ -327,13 +463,28 @@ func (c *Context) QueryArray(key string) []string {
 // GetQueryArray returns a slice of strings for a given query key, plus
 // a boolean value whether at least one value exists for the given key.
 func (c *Context) GetQueryArray(key string) ([]string, bool) {
-	if values, ok := c.Request.URL.Query()[key]; ok && len(values) > 0 {
+	c.initQueryCache()
+	if values, ok := c.queryCache[key]; ok && len(values) > 0 {
 		return values, true
 	}
 	return []string{}, false
 }
 
+// initQueryCache parses request query once per request
+func (c *Context) initQueryCache() {
+	if c.queryCache == nil {
+		if c.Request != nil && c.Request.URL != nil {
+			c.queryCache = c.Request.URL.Query()
+		} else {
+			c.queryCache = url.Values{}
+		}
+	}
+}
+
 // QueryMap returns a map for a given query key.
+//
+// Keys are parsed from `key[name]=value` style query parameters,
+// e.g. `?ids[a]=1&ids[b]=2` with key "ids" returns {"a": "1", "b": "2"}
 func (c *Context) QueryMap(key string) map[string]string {
 	dicts, _ := c.GetQueryMap(key)
 	return dicts
@@ -342,7 +493,8 @@ func (c *Context) QueryMap(key string) map[string]string {
 // GetQueryMap returns a map for a given query key, plus a boolean value
 // whether at least one value exists for the given key.
 func (c *Context) GetQueryMap(key string) (map[string]string, bool) {
-	return c.get(c.Request.URL.Query(), key)
+	c.initQueryCache()
+	return c.get(c.queryCache, key)
 }
 
 // PostForm returns the specified key from a POST urlencoded form or multipart form
@@ -382,21 +534,32 @@ func (c *Context) PostFormArray(key string) []string {
 // GetPostFormArray returns a slice of strings for a given form key, plus
 // a boolean value whether at least one value exists for the given key.
 func (c *Context) GetPostFormArray(key string) ([]string, bool) {
-	req := c.Request
-	_ = req.ParseMultipartForm(c.app.MaxMultipartMemory)
-
-	if values := req.PostForm[key]; len(values) > 0 {
+	c.initFormCache()
+	if values := c.formCache[key]; len(values) > 0 {
 		return values, true
 	}
-	if req.MultipartForm != nil && req.MultipartForm.File != nil {
-		if values := req.MultipartForm.Value[key]; len(values) > 0 {
-			return values, true
+	return []string{}, false
+}
+
+// initFormCache parses request form once per request,
+// respecting MaxMultipartMemory for multipart forms
+func (c *Context) initFormCache() {
+	if c.formCache == nil {
+		c.formCache = make(url.Values)
+		req := c.Request
+		if err := req.ParseMultipartForm(c.app.MaxMultipartMemory); err != nil && err != http.ErrNotMultipart {
+			c.Logger().Warn(fmt.Sprintf("error on parse multipart form array: %v", err))
+		}
+		// PostForm holds both urlencoded and multipart form values
+		if req.PostForm != nil {
+			c.formCache = req.PostForm
 		}
 	}
-	return []string{}, false
 }
 
 // PostFormMap returns a map for a given form key.
+//
+// Keys are parsed from `key[name]=value` style form fields, see QueryMap
 func (c *Context) PostFormMap(key string) map[string]string {
 	dicts, _ := c.GetPostFormMap(key)
 	return dicts
@@ -405,19 +568,8 @@ func (c *Context) PostFormMap(key string) map[string]string {
 // GetPostFormMap returns a map for a given form key, plus a boolean value
 // whether at least one value exists for the given key.
 func (c *Context) GetPostFormMap(key string) (map[string]string, bool) {
-	req := c.Request
-	err := req.ParseMultipartForm(c.app.MaxMultipartMemory)
-	if err != nil {
-		panic(err)
-	}
-
-	dicts, exist := c.get(req.PostForm, key)
-
-	if !exist && req.MultipartForm != nil && req.MultipartForm.File != nil {
-		dicts, exist = c.get(req.MultipartForm.Value, key)
-	}
-
-	return dicts, exist
+	c.initFormCache()
+	return c.get(c.formCache, key)
 }
 
 // get is an internal method and returns a map which satisfy conditions.
@@ -470,10 +622,29 @@ func (c *Context) SaveUploadedFile(file *multipart.FileHeader, dest string) erro
 	return nil
 }
 
-// Bind checks the Content-Type to select a binding engine automatically,
+// Bind checks the Content-Type to select a decoder registered with
+// App.RegisterDecoder automatically. GET requests and requests without
+// Content-Type are bound as form.
+//
+// When there is no decoder for Content-Type, it returns ErrUnsupportedMediaType.
+// Like other Bind methods it does not write response, see MustBind.
 func (c *Context) Bind(obj interface{}) error {
-	b := binding.Default(c.Request.Method, c.ContentType())
-	return c.BindWith(obj, b)
+	contentType := normalizeContentType(c.ContentType())
+	if c.Request.Method == "GET" || contentType == "" {
+		return c.BindWith(obj, binding.Form)
+	}
+
+	decoder, ok := c.app.codecs.decoders[contentType]
+	if !ok {
+		return ErrUnsupportedMediaType
+	}
+	if err := decoder.Decode(c.Request, obj); err != nil {
+		return err
+	}
+	if _, ok := decoder.(binderDecoder); ok || binding.Validator == nil {
+		return nil
+	}
+	return binding.Validator.ValidateStruct(obj)
 }
 
 // BindJSON binds the passed struct pointer using JSON binding engine.
@@ -491,13 +662,39 @@ func (c *Context) BindQuery(obj interface{}) error {
 	return c.BindWith(obj, binding.Query)
 }
 
-// BindURI binds the passed struct pointer using URI binding engine.
+// BindURI binds route params into the passed struct pointer using `uri` tags.
 func (c *Context) BindURI(obj interface{}) error {
-	m := make(map[string][]string)
+	return binding.URI.BindURI(c.paramsMap(), obj)
+}
+
+// MustBindURI binds route params like BindURI.
+//
+// When params can not be converted or validated, it aborts the chain,
+// responds with 400 Bad Request and returns false.
+func (c *Context) MustBindURI(obj interface{}) bool {
+	if err := c.BindURI(obj); err != nil {
+		c.Abort()
+		c.ServeError(http.StatusBadRequest, err)
+		return false
+	}
+	return true
+}
+
+// BindAll binds JSON body, query string and route params into the passed
+// struct pointer using `json`, `form` and `uri` tags respectively.
+//
+// Route params take precedence over query string, which takes precedence
+// over JSON body. Validation runs once after all sources are merged.
+func (c *Context) BindAll(obj interface{}) error {
+	return binding.BindAll(c.Request, c.paramsMap(), obj)
+}
+
+func (c *Context) paramsMap() map[string][]string {
+	m := make(map[string][]string, len(c.Params))
 	for _, v := range c.Params {
 		m[v.Key] = []string{v.Value}
 	}
-	return binding.URI.BindURI(m, obj)
+	return m
 }
 
 // BindWith binds the passed struct pointer using the specified binding engine.
@@ -506,15 +703,80 @@ func (c *Context) BindWith(obj interface{}, b binding.Binder) error {
 	return b.Bind(c.Request, obj)
 }
 
+// MustBind binds the passed struct pointer like Bind.
+//
+// On failure it aborts the chain, responds with 415 Unsupported Media Type
+// when there is no decoder for Content-Type or 400 Bad Request otherwise,
+// and returns false, so the handler should return immediately.
+func (c *Context) MustBind(obj interface{}) bool {
+	if err := c.Bind(obj); err != nil {
+		code := http.StatusBadRequest
+		if errors.Is(err, ErrUnsupportedMediaType) {
+			code = http.StatusUnsupportedMediaType
+		}
+		c.Abort()
+		c.ServeError(code, err)
+		return false
+	}
+	return true
+}
+
+// MustBindJSON binds the passed struct pointer using JSON binding engine.
+//
+// On failure it aborts the chain, responds with 400 Bad Request and returns
+// false, so the handler should return immediately:
+//
+//	if !c.MustBindJSON(&order) {
+//		return
+//	}
+func (c *Context) MustBindJSON(obj interface{}) bool {
+	return c.MustBindWith(obj, binding.JSON)
+}
+
+// MustBindXML is like MustBindJSON using XML binding engine.
+func (c *Context) MustBindXML(obj interface{}) bool {
+	return c.MustBindWith(obj, binding.XML)
+}
+
+// MustBindForm is like MustBindJSON using Form binding engine.
+func (c *Context) MustBindForm(obj interface{}) bool {
+	return c.MustBindWith(obj, binding.Form)
+}
+
+// MustBindWith binds the passed struct pointer using the specified binding engine,
+// on failure it aborts the chain, responds with 400 Bad Request and returns false.
+func (c *Context) MustBindWith(obj interface{}, b binding.Binder) bool {
+	if err := c.BindWith(obj, b); err != nil {
+		c.Abort()
+		c.ServeError(http.StatusBadRequest, err)
+		return false
+	}
+	return true
+}
+
 // ClientIP implements a best effort algorithm to return the real client IP
 //
 // it parses X-Real-IP and X-Forwarded-For in order to work properly
 // with reverse-proxies such us: nginx or haproxy.
 // Use X-Forwarded-For before X-Real-Ip as nginx uses X-Real-Ip with the proxy's IP.
+//
+// Result is cached for the request lifetime.
 func (c *Context) ClientIP() string {
+	if !c.clientIPCached {
+		c.clientIP = c.clientIPFromRequest()
+		c.clientIPCached = true
+	}
+	return c.clientIP
+}
+
+func (c *Context) clientIPFromRequest() string {
 
+	// first address of X-Forwarded-For, without splitting whole header
 	clientIP := c.requestHeader("X-Forwarded-For")
-	clientIP = strings.TrimSpace(strings.Split(clientIP, ",")[0])
+	if i := strings.IndexByte(clientIP, ','); i >= 0 {
+		clientIP = clientIP[:i]
+	}
+	clientIP = strings.TrimSpace(clientIP)
 	if clientIP == "" {
 		clientIP = strings.TrimSpace(c.requestHeader("X-Real-Ip"))
 	}
@@ -539,7 +801,7 @@ func (c *Context) ClientIP() string {
 // and X-Amzn-Trace-Id which is automatically added by Amazon loadbalancers
 func (c *Context) RequestID() string {
 	// check if request ID exists in headers
-	requestID := c.requestHeader("X-Request-ID")
+	requestID := c.requestHeader(RequestIDHeader)
 
 	if requestID == "" {
 		//check if  X-Amzn-Trace-Id exists
@@ -627,6 +889,11 @@ func (c *Context) HTML(code int, name string, obj interface{}) {
 	// request scoped view Helpers
 	helpers := make(template.FuncMap)
 
+	// feature flags of current request
+	helpers["flag"] = c.Flag
+	// route paths including application base path
+	helpers["url"] = c.app.URL
+
 	// request scoped data
 	data := make(map[string]interface{})
 
@@ -646,7 +913,7 @@ func (c *Context) HTML(code int, name string, obj interface{}) {
 	data["model"] = obj
 
 	// check if we use translations
-	translator := c.app.Translator
+	translator := c.Translator()
 	if translator != nil {
 		// reload translations during development
 		if c.AppOptions().Env == "development" {
@@ -658,16 +925,21 @@ func (c *Context) HTML(code int, name string, obj interface{}) {
 
 		// get languages
 		langs := translator.ExtractLanguage(c)
-		// define translation function
-		transFunc, err := i18n.Tfunc(langs[0], langs[1:]...)
-		if err != nil {
-			c.Logger().Warn(err.Error())
+		// prefer language negotiated by Localize middleware
+		if lang := c.GetString(LanguageKey); lang != "" {
+			langs = append([]string{lang}, langs...)
+		}
+		// pick language, missing translations are looked up in its fallback chain
+		lang := translator.SupportedLanguage(langs...)
+		if lang == "" {
+			c.Logger().Warn(fmt.Sprintf("no supported languages found %#v", langs))
 			c.Logger().Warn("Your locale files are probably empty or missing")
+			lang = translator.DefaultLanguage
 		}
 
 		// create viewHelper function
 		helpers[translator.HelperName] = func(translationID string, args ...interface{}) string {
-			return transFunc(translationID, args...)
+			return translator.Translate(lang, translationID, args...)
 		}
 	}
 
@@ -681,8 +953,8 @@ func (c *Context) HTML(code int, name string, obj interface{}) {
 	// set render contentType
 	c.SetContentType(r.ContentType())
 
-	// render
-	c.Render(code, r)
+	// render, timed as Server-Timing phase
+	c.Render(code, timedRenderer{Renderer: r, c: c, name: "template", description: name})
 }
 
 // JSON serializes the given struct as JSON into the response body.
@@ -701,6 +973,14 @@ func (c *Context) XML(code int, obj interface{}) {
 	c.Render(code, r)
 }
 
+// XMLIndent serializes the given struct as pretty-printed XML into the response body.
+// It also sets the Content-Type as "application/xml".
+func (c *Context) XMLIndent(code int, obj interface{}, prefix, indent string) {
+	r := render.XML{Data: obj, Prefix: prefix, Indent: indent}
+	c.SetContentType(r.ContentType())
+	c.Render(code, r)
+}
+
 // String writes the given string into the response body.
 func (c *Context) String(code int, data string) {
 	r := render.Text{Data: data}
@@ -822,8 +1102,13 @@ func (c *Context) ServeError(code int, err error) {
 	} else if c.app.errorHandler != nil {
 		c.app.errorHandler(c)
 	} else {
+		body := err.Error()
+		// do not expose internal error details
+		if code == http.StatusInternalServerError && c.app.Body500 != "" {
+			body = c.app.Body500
+		}
 		c.SetContentType([]string{"text/plain"})
-		_, _ = c.Response.Write([]byte(err.Error()))
+		_, _ = c.Response.Write([]byte(body))
 		return
 	}
 	c.Response.WriteHeaderNow()
@@ -2,15 +2,21 @@ package cucumber
 
 import (
 	"bytes"
+	"encoding/xml"
 	"errors"
+	"fmt"
+	"math"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/AjdinHalac/cucumber/binding"
+	"github.com/AjdinHalac/cucumber/di"
+	"github.com/AjdinHalac/cucumber/log"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -170,6 +176,24 @@ func TestContextSetGetValues(t *testing.T) {
 
 }
 
+func TestContextNamespace(t *testing.T) {
+	c, _ := createTestContext(httptest.NewRecorder())
+
+	auth := c.NS("auth")
+	session := c.NS("session")
+	auth.Set("userID", 1)
+	session.Set("userID", 2)
+
+	assert.Equal(t, 1, auth.Get("userID"))
+	assert.Equal(t, 2, session.Get("userID"))
+	assert.Equal(t, 1, c.NSMust("auth:userID"))
+	assert.Equal(t, 2, c.NS("session").Must("userID"))
+	assert.Nil(t, auth.Get("missing"))
+	_, exists := auth.Lookup("missing")
+	assert.False(t, exists)
+	assert.Panics(t, func() { c.NSMust("auth:missing") })
+}
+
 func TestContextGetString(t *testing.T) {
 	c, _ := createTestContext(httptest.NewRecorder())
 	c.Set("string", "this is a string")
@@ -266,6 +290,63 @@ func TestContextCopy(t *testing.T) {
 	assert.Equal(t, cp.Keys, c.Keys)
 	assert.Equal(t, cp.app, c.app)
 	assert.Equal(t, cp.Params, c.Params)
+
+	// params backing array of pooled context is not shared
+	c.Params[0].Value = "baz"
+	assert.Equal(t, "bar", cp.Param("foo"))
+}
+
+func TestContextVars(t *testing.T) {
+	app := newTestAppInstance()
+	app.Use(func(c *Context) {
+		c.Vars()["userID"] = 123
+		c.Vars()["role"] = "admin"
+		c.Next()
+		// vars set by handler are visible after Next
+		id, _ := c.VarInt64("orderID")
+		c.SetHeader("X-Order", fmt.Sprint(id))
+	})
+	app.GET("/orders", func(c *Context) {
+		id, ok := c.VarInt64("userID")
+		assert.True(t, ok)
+		assert.Equal(t, int64(123), id)
+
+		role, ok := c.VarString("role")
+		assert.True(t, ok)
+		assert.Equal(t, "admin", role)
+
+		_, ok = c.VarString("userID")
+		assert.False(t, ok)
+		_, ok = c.VarInt64("role")
+		assert.False(t, ok)
+		_, ok = c.VarString("missing")
+		assert.False(t, ok)
+
+		c.Vars()["orderID"] = uint32(7)
+		c.Status(http.StatusOK)
+	})
+	app.GET("/empty", func(c *Context) {
+		assert.Len(t, c.Vars(), 2)
+		c.Status(http.StatusOK)
+	})
+
+	w := performRequest(app, http.MethodGet, "/orders")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "7", w.Header().Get("X-Order"))
+
+	// vars do not leak between pooled contexts
+	w = performRequest(app, http.MethodGet, "/empty")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	c, _ := createTestContext(httptest.NewRecorder())
+	c.Vars()["big"] = uint64(math.MaxUint64)
+	_, ok := c.VarInt64("big")
+	assert.False(t, ok)
+	c.Vars()["id"] = int8(-1)
+	cp := c.Copy()
+	c.Vars()["id"] = 2
+	id, _ := cp.VarInt64("id")
+	assert.Equal(t, int64(-1), id)
 }
 
 var handlerTest HandlerFunc = func(c *Context) {
@@ -416,6 +497,22 @@ func TestContextQueryAndPostForm(t *testing.T) {
 	assert.Equal(t, 0, len(dicts))
 }
 
+func TestContextPostFormMapURLEncoded(t *testing.T) {
+	c, _ := createTestContext(httptest.NewRecorder())
+	body := bytes.NewBufferString("names[a]=thinkerou&names[b]=tianou&foo=bar")
+	c.Request, _ = http.NewRequest("POST", "/?names[c]=query", body)
+	c.Request.Header.Add("Content-Type", binding.MIMEPOSTForm)
+
+	dicts, ok := c.GetPostFormMap("names")
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"a": "thinkerou", "b": "tianou"}, dicts)
+
+	// form is parsed once per request
+	c.Request.PostForm = nil
+	assert.Equal(t, "bar", c.PostForm("foo"))
+	assert.Equal(t, map[string]string{"c": "query"}, c.QueryMap("names"))
+}
+
 func TestContextPostFormMultipart(t *testing.T) {
 	c, _ := createTestContext(httptest.NewRecorder())
 	c.Request = createMultipartRequest()
@@ -507,3 +604,166 @@ func TestContextPostFormMultipart(t *testing.T) {
 	dicts = c.PostFormMap("nokey")
 	assert.Equal(t, 0, len(dicts))
 }
+
+type testUserRepo interface {
+	Find(id string) string
+}
+
+type testMemoryUserRepo struct{}
+
+func (r *testMemoryUserRepo) Service() {}
+
+func (r *testMemoryUserRepo) Find(id string) string {
+	return "user " + id
+}
+
+func TestContextService(t *testing.T) {
+	app := newTestAppInstance()
+	impl := &testMemoryUserRepo{}
+	app.Register(impl)
+
+	var (
+		repo    testUserRepo
+		missing *Router
+		err     error
+	)
+	app.GET("/users/:id", func(c *Context) {
+		err = c.Service(&repo)
+		c.String(http.StatusOK, repo.Find(c.Param("id")))
+	})
+	app.GET("/missing", func(c *Context) {
+		assert.ErrorIs(t, c.Service(&missing), di.ErrNotFound)
+		assert.Error(t, c.Service(repo))
+		c.Status(http.StatusOK)
+	})
+
+	w := performRequest(app, http.MethodGet, "/users/42")
+	assert.NoError(t, err)
+	assert.Same(t, impl, repo)
+	assert.Equal(t, "user 42", w.Body.String())
+
+	performRequest(app, http.MethodGet, "/missing")
+	assert.Nil(t, missing)
+}
+
+type xmlOrderLine struct {
+	SKU      string `xml:"sku,attr"`
+	Quantity int    `xml:"quantity"`
+}
+
+type xmlOrder struct {
+	XMLName  xml.Name `xml:"order"`
+	ID       string   `xml:"id"`
+	Customer struct {
+		Name string `xml:"name"`
+	} `xml:"customer"`
+	Lines []xmlOrderLine `xml:"lines>line"`
+}
+
+func TestContextXMLRoundTrip(t *testing.T) {
+	order := xmlOrder{XMLName: xml.Name{Local: "order"}, ID: "42", Lines: []xmlOrderLine{{SKU: "A1", Quantity: 2}, {SKU: "B2", Quantity: 1}}}
+	order.Customer.Name = "cucumber"
+	body, _ := xml.Marshal(order)
+
+	app := newTestAppInstance()
+	app.POST("/orders", func(c *Context) {
+		var bound xmlOrder
+		if assert.NoError(t, c.BindXML(&bound)) {
+			c.XML(http.StatusCreated, bound)
+		}
+	})
+	app.GET("/orders", func(c *Context) {
+		c.XMLIndent(http.StatusOK, xmlOrderLine{SKU: "A1", Quantity: 2}, "", "  ")
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", bytes.NewReader(body))
+	req.Header.Set("Content-Type", binding.MIMEXML)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(w.Body.String(), xml.Header))
+
+	var echoed xmlOrder
+	assert.NoError(t, xml.Unmarshal(w.Body.Bytes(), &echoed))
+	assert.Equal(t, order, echoed)
+
+	w = performRequest(app, http.MethodGet, "/orders")
+	assert.Equal(t, xml.Header+"<xmlOrderLine sku=\"A1\">\n  <quantity>2</quantity>\n</xmlOrderLine>", w.Body.String())
+}
+
+func TestContextClientIP(t *testing.T) {
+	c, _ := createTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	c.Request.RemoteAddr = "  40.40.40.40:42123 "
+
+	tt := []struct {
+		Name   string
+		Header map[string]string
+		IP     string
+	}{
+		{Name: "forwarded for", Header: map[string]string{"X-Forwarded-For": " 20.20.20.20, 30.30.30.30", "X-Real-IP": "10.10.10.10"}, IP: "20.20.20.20"},
+		{Name: "single forwarded for", Header: map[string]string{"X-Forwarded-For": "30.30.30.30 "}, IP: "30.30.30.30"},
+		{Name: "real ip", Header: map[string]string{"X-Forwarded-For": ",", "X-Real-IP": " 10.10.10.10 "}, IP: "10.10.10.10"},
+		{Name: "appengine", Header: map[string]string{"X-Appengine-Remote-Addr": "50.50.50.50"}, IP: "50.50.50.50"},
+		{Name: "remote addr", IP: "40.40.40.40"},
+	}
+
+	for _, tc := range tt {
+		c.reset()
+		c.Request.Header = http.Header{}
+		for k, v := range tc.Header {
+			c.Request.Header.Set(k, v)
+		}
+		assert.Equal(t, tc.IP, c.ClientIP(), tc.Name)
+	}
+
+	// cached for request lifetime
+	c.Request.Header.Set("X-Real-IP", "10.10.10.10")
+	assert.Equal(t, "40.40.40.40", c.ClientIP())
+	c.reset()
+	assert.Equal(t, "10.10.10.10", c.ClientIP())
+}
+
+func BenchmarkContextClientIP(b *testing.B) {
+	c, _ := createTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	c.Request.Header.Set("X-Forwarded-For", " 20.20.20.20, 30.30.30.30")
+	c.Request.RemoteAddr = "40.40.40.40:42123"
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.reset()
+		c.ClientIP()
+		c.ClientIP()
+	}
+}
+
+func TestContextLogField(t *testing.T) {
+	c, app := createTestContext(httptest.NewRecorder())
+	logger := newLevelLogger()
+	app.Logger = logger
+
+	c.LogField("request_id", "req-1")
+	c.LogFields(log.Fields{"user": "alice"})
+	c.LogField("user", "bob")
+	c.LogField("status", 200)
+	c.Logger().Info("request")
+
+	assert.Equal(t, log.Fields{"request_id": "req-1", "user": "bob", "status": 200}, *logger.last)
+
+	// copy does not share pending fields
+	c.LogField("a", 1)
+	cp := c.Copy()
+	c.reset()
+	c.LogField("b", 2)
+	cp.Logger().Info("copy")
+	assert.Equal(t, log.Fields{"request_id": "req-1", "user": "bob", "status": 200, "a": 1}, *logger.last)
+
+	// reset drops pending fields
+	c.reset()
+	c.Logger().Info("reset")
+	assert.Equal(t, log.Fields{}, *logger.last)
+}
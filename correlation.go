@@ -0,0 +1,101 @@
+package cucumber
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDHeader is the HTTP header holding the request correlation ID
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDMetadataKey is the gRPC metadata key holding the request correlation ID
+const RequestIDMetadataKey = "x-request-id"
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the given request ID
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext extracts request ID from context
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDKey{}).(string)
+	return requestID, ok && requestID != ""
+}
+
+// CorrelationPropagator returns a middleware that stores the request ID
+// in the request context so it can be propagated to outbound gRPC calls
+func CorrelationPropagator() HandlerFunc {
+	return func(c *Context) {
+		if requestID := c.RequestID(); requestID != "" {
+			c.Request = c.Request.WithContext(ContextWithRequestID(c.Request.Context(), requestID))
+		}
+		c.Next()
+	}
+}
+
+// PropagatedGRPCCallOptions returns call options which inject correlation
+// metadata of the current request into an outbound gRPC call
+//
+// Options are applied by the client interceptor created with
+// NewUnaryClientCorrelationInterceptor
+func (c *Context) PropagatedGRPCCallOptions() []grpc.CallOption {
+	requestID, ok := RequestIDFromContext(c.Request.Context())
+	if !ok {
+		requestID = c.RequestID()
+	}
+	if requestID == "" {
+		return nil
+	}
+	return []grpc.CallOption{correlationCallOption{requestID: requestID}}
+}
+
+// correlationCallOption carries request ID to the client interceptor
+type correlationCallOption struct {
+	grpc.EmptyCallOption
+	requestID string
+}
+
+// NewUnaryCorrelationInterceptor creates UnaryInterceptor that restores
+// request ID from incoming metadata into the handler context
+func NewUnaryCorrelationInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if requestID, ok := requestIDFromIncomingContext(ctx); ok {
+			ctx = ContextWithRequestID(ctx, requestID)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// requestIDFromIncomingContext extracts request ID from incoming gRPC metadata
+func requestIDFromIncomingContext(ctx context.Context) (string, bool) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDMetadataKey); len(values) > 0 && values[0] != "" {
+			return values[0], true
+		}
+	}
+	return "", false
+}
+
+// NewUnaryClientCorrelationInterceptor creates client UnaryInterceptor that
+// injects request ID into outgoing metadata
+//
+// Request ID is taken from correlation call options or from the call context
+func NewUnaryClientCorrelationInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		requestID, _ := RequestIDFromContext(ctx)
+		for _, opt := range opts {
+			if co, ok := opt.(correlationCallOption); ok {
+				requestID = co.requestID
+			}
+		}
+
+		if requestID != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, RequestIDMetadataKey, requestID)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
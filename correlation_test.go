@@ -0,0 +1,114 @@
+package cucumber
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestCorrelationHTTPToGRPC(t *testing.T) {
+	grpcRequestID := ""
+
+	// gRPC leg
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(WithUnaryServerChain(
+		NewUnaryCorrelationInterceptor(),
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			grpcRequestID, _ = RequestIDFromContext(ctx)
+			return handler(ctx, req)
+		},
+	))
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithInsecure(),
+		grpc.WithUnaryInterceptor(NewUnaryClientCorrelationInterceptor()),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	// HTTP leg
+	app := newTestAppInstance()
+	app.Use(CorrelationPropagator())
+	app.GET("/", func(c *Context) {
+		_, err := client.Check(c.Request.Context(), &healthpb.HealthCheckRequest{}, c.PropagatedGRPCCallOptions()...)
+		if err != nil {
+			c.ServeError(http.StatusInternalServerError, err)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set(RequestIDHeader, "correlation-id")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "correlation-id", grpcRequestID)
+}
+
+func TestCorrelationRequestLoggers(t *testing.T) {
+	logger := newLevelLogger()
+
+	opts := NewOptions()
+	opts.UseViewEngine = false
+	opts.UseSession = false
+	opts.UseTranslator = false
+	opts.Logger = logger
+
+	app := NewWithOptions(opts)
+	app.RegisterServiceHandler(&healthService{health.NewServer()})
+
+	// in-process gRPC service
+	lis := bufconn.Listen(1 << 20)
+	go app.server.Serve(lis)
+	defer app.server.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithInsecure(),
+		grpc.WithUnaryInterceptor(NewUnaryClientCorrelationInterceptor()),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	app.GET("/", func(c *Context) {
+		if _, err := client.Check(c.Request.Context(), &healthpb.HealthCheckRequest{}); err != nil {
+			c.ServeError(http.StatusInternalServerError, err)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set(RequestIDHeader, "correlation-id")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// gRPC request log followed by HTTP request log
+	history := *logger.history
+	if assert.Len(t, history, 2) {
+		assert.Equal(t, "Check", history[0]["grpc.method"])
+		assert.Equal(t, "correlation-id", history[0]["request_id"])
+		assert.Equal(t, "correlation-id", history[1]["request_id"])
+	}
+}
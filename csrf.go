@@ -0,0 +1,136 @@
+package cucumber
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// CSRFTokenKey is the context key holding CSRF token of the request, see Context.CSRFToken
+const CSRFTokenKey = "csrfToken"
+
+// ErrCSRFToken is served when CSRF token is missing or invalid
+var ErrCSRFToken = errors.New("invalid CSRF token")
+
+// csrfNonceSize is the number of random bytes in CSRF token
+const csrfNonceSize = 32
+
+// CSRFConfig configures CSRF middleware
+type CSRFConfig struct {
+	// Secret is the key used to sign CSRF tokens
+	Secret []byte
+
+	// CookieName is the name of cookie holding CSRF token. Defaults to _csrf
+	CookieName string
+
+	// HeaderName is the header holding submitted CSRF token. Defaults to X-CSRF-Token
+	HeaderName string
+
+	// FormFieldName is the form field holding submitted CSRF token. Defaults to csrf_token
+	FormFieldName string
+
+	// SameSite is SameSite attribute of CSRF cookie. Defaults to http.SameSiteLaxMode
+	SameSite http.SameSite
+
+	// Secure marks CSRF cookie to be sent over HTTPS only
+	Secure bool
+}
+
+// CSRF returns a middleware protecting against cross-site request forgery
+// using signed double submit cookie
+//
+// Signed token is stored in a cookie and exposed by Context.CSRFToken to be
+// embedded in forms or templates. POST, PUT, PATCH and DELETE requests have
+// to submit the same token in header or form field, otherwise they are
+// aborted with 403 Forbidden.
+func CSRF(cfg CSRFConfig) HandlerFunc {
+	if len(cfg.Secret) == 0 {
+		panic("CSRF secret can not be empty")
+	}
+	if cfg.CookieName == "" {
+		cfg.CookieName = "_csrf"
+	}
+	if cfg.HeaderName == "" {
+		cfg.HeaderName = "X-CSRF-Token"
+	}
+	if cfg.FormFieldName == "" {
+		cfg.FormFieldName = "csrf_token"
+	}
+	if cfg.SameSite == 0 {
+		cfg.SameSite = http.SameSiteLaxMode
+	}
+
+	return func(c *Context) {
+		token, err := c.Cookie(cfg.CookieName)
+		if err != nil || !validCSRFToken(cfg.Secret, token) {
+			token = ""
+		}
+
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			submitted := c.Header(cfg.HeaderName)
+			if submitted == "" {
+				submitted = c.Request.PostFormValue(cfg.FormFieldName)
+			}
+			if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(submitted)) != 1 {
+				c.Abort()
+				c.ServeError(http.StatusForbidden, ErrCSRFToken)
+				return
+			}
+		}
+
+		if token == "" {
+			if token, err = newCSRFToken(cfg.Secret); err != nil {
+				c.Abort()
+				c.ServeError(http.StatusInternalServerError, err)
+				return
+			}
+			http.SetCookie(c.Response, &http.Cookie{
+				Name:     cfg.CookieName,
+				Value:    token,
+				Path:     "/",
+				Secure:   cfg.Secure,
+				HttpOnly: true,
+				SameSite: cfg.SameSite,
+			})
+		}
+
+		c.Set(CSRFTokenKey, token)
+		c.Next()
+	}
+}
+
+// CSRFToken returns CSRF token of the request set by CSRF middleware
+func (c *Context) CSRFToken() string {
+	return c.GetString(CSRFTokenKey)
+}
+
+// newCSRFToken returns random nonce and its signature as "<nonce>.<signature>"
+func newCSRFToken(secret []byte) (string, error) {
+	nonce := make([]byte, csrfNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(nonce)
+	return encoded + "." + signCSRFNonce(secret, encoded), nil
+}
+
+// validCSRFToken reports whether token was signed with secret
+func validCSRFToken(secret []byte, token string) bool {
+	i := strings.IndexByte(token, '.')
+	if i < 0 {
+		return false
+	}
+	return hmac.Equal([]byte(signCSRFNonce(secret, token[:i])), []byte(token[i+1:]))
+}
+
+func signCSRFNonce(secret []byte, nonce string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(nonce))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,100 @@
+package cucumber
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newCSRFTestApp() *App {
+	app := newTestAppInstance()
+	app.Use(CSRF(CSRFConfig{Secret: []byte("secret"), Secure: true}))
+	app.GET("/form", func(c *Context) {
+		c.String(http.StatusOK, c.CSRFToken())
+	})
+	app.POST("/form", func(c *Context) {
+		c.String(http.StatusOK, "saved")
+	})
+	return app
+}
+
+func TestCSRF(t *testing.T) {
+	app := newCSRFTestApp()
+
+	// GET sets token cookie
+	w := performRequest(app, http.MethodGet, "/form")
+	assert.Equal(t, http.StatusOK, w.Code)
+	cookies := w.Result().Cookies()
+	if assert.Len(t, cookies, 1) {
+		assert.Equal(t, "_csrf", cookies[0].Name)
+		assert.True(t, cookies[0].HttpOnly)
+		assert.True(t, cookies[0].Secure)
+		assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+	}
+	cookie := cookies[0]
+	token := w.Body.String()
+	assert.Equal(t, cookie.Value, token)
+
+	// valid token cookie is reused
+	req, _ := http.NewRequest(http.MethodGet, "/form", nil)
+	req.AddCookie(cookie)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	assert.Equal(t, token, w.Body.String())
+	assert.Empty(t, w.Result().Cookies())
+
+	// POST with token in header
+	req, _ = http.NewRequest(http.MethodPost, "/form", nil)
+	req.AddCookie(cookie)
+	req.Header.Set("X-CSRF-Token", token)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "saved", w.Body.String())
+
+	// POST with token in form
+	req, _ = http.NewRequest(http.MethodPost, "/form", strings.NewReader(url.Values{"csrf_token": {token}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(cookie)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCSRFInvalidToken(t *testing.T) {
+	app := newCSRFTestApp()
+	token, _ := newCSRFToken([]byte("secret"))
+	forged, _ := newCSRFToken([]byte("other"))
+
+	tt := []struct {
+		Name      string
+		Cookie    string
+		Submitted string
+	}{
+		{Name: "missing"},
+		{Name: "missing submitted", Cookie: token},
+		{Name: "missing cookie", Submitted: token},
+		{Name: "mismatch", Cookie: token, Submitted: token + "x"},
+		{Name: "forged", Cookie: forged, Submitted: forged},
+	}
+
+	for _, tc := range tt {
+		req, _ := http.NewRequest(http.MethodPost, "/form", nil)
+		if tc.Cookie != "" {
+			req.AddCookie(&http.Cookie{Name: "_csrf", Value: tc.Cookie})
+		}
+		req.Header.Set("X-CSRF-Token", tc.Submitted)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code, tc.Name)
+		assert.NotEqual(t, "saved", w.Body.String(), tc.Name)
+	}
+
+	assert.Panics(t, func() {
+		CSRF(CSRFConfig{})
+	})
+}
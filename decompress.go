@@ -0,0 +1,155 @@
+package cucumber
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrUnsupportedContentEncoding is served when request body is compressed
+// with encoding which has no registered decompressor
+var ErrUnsupportedContentEncoding = errors.New("unsupported content encoding")
+
+// DecompressorFunc wraps compressed reader with reader of decompressed data
+type DecompressorFunc func(r io.Reader) (io.ReadCloser, error)
+
+// ErrDecompressedBodyTooLarge is returned when reading decompressed request
+// body exceeding Options.MaxDecompressedBodySize
+var ErrDecompressedBodyTooLarge = errors.New("decompressed body too large")
+
+func gzipDecompressor(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+func deflateDecompressor(r io.Reader) (io.ReadCloser, error) {
+	return zlib.NewReader(r)
+}
+
+// RegisterDecompressor registers decompressor used by DecompressBody for given
+// Content-Encoding, e.g. zstd
+func (a *App) RegisterDecompressor(encoding string, fn DecompressorFunc) *App {
+	if fn == nil {
+		panic("nil decompressor registered for '" + encoding + "'")
+	}
+	a.codecs.decompressors[strings.ToLower(encoding)] = fn
+	return a
+}
+
+// DecompressBody returns a middleware which decompresses request body according
+// to Content-Encoding header, so downstream handlers see plain body
+//
+// gzip and deflate are supported by default, other encodings can be added with
+// App.RegisterDecompressor. It responds with 400 Bad Request when body can not be
+// decompressed and 415 Unsupported Media Type for unknown encodings.
+//
+// Decompressed body is limited to Options.MaxDecompressedBodySize, reading
+// beyond it fails with ErrDecompressedBodyTooLarge and request is responded
+// with 413 Request Entity Too Large.
+func DecompressBody() HandlerFunc {
+	return func(c *Context) {
+		header := c.Request.Header.Get("Content-Encoding")
+		if header == "" || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		encodings := strings.Split(header, ",")
+		body := &decompressedBody{closers: []io.Closer{c.Request.Body}}
+		var reader io.Reader = c.Request.Body
+
+		// encodings are listed in the order in which they were applied
+		for i := len(encodings) - 1; i >= 0; i-- {
+			encoding := strings.ToLower(strings.TrimSpace(encodings[i]))
+			if encoding == "identity" {
+				continue
+			}
+
+			fn, ok := c.app.codecs.decompressors[encoding]
+			if !ok {
+				_ = body.Close()
+				c.Abort()
+				c.ServeError(http.StatusUnsupportedMediaType, ErrUnsupportedContentEncoding)
+				return
+			}
+
+			decompressed, err := fn(reader)
+			if err != nil {
+				_ = body.Close()
+				c.Abort()
+				c.ServeError(http.StatusBadRequest, err)
+				return
+			}
+			body.closers = append(body.closers, decompressed)
+			reader = decompressed
+		}
+
+		body.Reader = reader
+		if limit := c.app.MaxDecompressedBodySize; limit > 0 {
+			body.Reader = &limitedReader{r: reader, remaining: limit}
+		}
+		c.Request.Body = body
+		c.Request.ContentLength = -1
+		c.Request.Header.Del("Content-Encoding")
+		c.Request.Header.Del("Content-Length")
+
+		// handlers usually respond to read errors with 400 Bad Request
+		c.writermem.onBeforeWriteHeader(func() {
+			if body.exceeded() {
+				c.Response.WriteHeader(http.StatusRequestEntityTooLarge)
+			}
+		})
+		c.Next()
+		if body.exceeded() && !c.Response.Written() {
+			c.ServeError(http.StatusRequestEntityTooLarge, ErrDecompressedBodyTooLarge)
+		}
+	}
+}
+
+// limitedReader fails with ErrDecompressedBodyTooLarge once more than remaining bytes are read
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	exceeded  bool
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.exceeded {
+		return 0, ErrDecompressedBodyTooLarge
+	}
+	// read one byte over the limit to tell body of exactly limit size from larger one
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	if int64(n) > l.remaining {
+		l.exceeded = true
+		return int(l.remaining), ErrDecompressedBodyTooLarge
+	}
+	l.remaining -= int64(n)
+	return n, err
+}
+
+// decompressedBody closes decompressors together with original request body
+type decompressedBody struct {
+	io.Reader
+	closers []io.Closer
+}
+
+// exceeded reports whether reading body exceeded decompressed size limit
+func (b *decompressedBody) exceeded() bool {
+	l, ok := b.Reader.(*limitedReader)
+	return ok && l.exceeded
+}
+
+func (b *decompressedBody) Close() error {
+	var err error
+	for i := len(b.closers) - 1; i >= 0; i-- {
+		if cerr := b.closers[i].Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,125 @@
+package cucumber
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecompressBody(t *testing.T) {
+	opts := NewOptions()
+	opts.UseViewEngine = false
+	opts.UseRequestLogger = false
+	opts.UseSession = false
+	opts.UseTranslator = false
+	opts.DecompressBody = true
+
+	app := NewWithOptions(opts)
+	app.POST("/users", func(c *Context) {
+		assert.Empty(t, c.Request.Header.Get("Content-Encoding"))
+		data, err := c.GetRawData()
+		if err != nil {
+			c.ServeError(http.StatusBadRequest, err)
+			return
+		}
+		var user struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(data, &user); err != nil {
+			c.ServeError(http.StatusBadRequest, err)
+			return
+		}
+		c.String(http.StatusOK, user.Name)
+	})
+
+	send := func(encoding string, body io.Reader) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/users", body)
+		req.Header.Set("Content-Type", "application/json")
+		if encoding != "" {
+			req.Header.Set("Content-Encoding", encoding)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, _ = gz.Write([]byte(`{"name":"cucumber"}`))
+	_ = gz.Close()
+
+	w := send("gzip", bytes.NewReader(compressed.Bytes()))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "cucumber", w.Body.String())
+
+	w = send("", strings.NewReader(`{"name":"plain"}`))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "plain", w.Body.String())
+
+	w = send("gzip", strings.NewReader(`{"name":"plain"}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = send("br", strings.NewReader(`{"name":"plain"}`))
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+	// pluggable decompressor
+	app.RegisterDecompressor("br", func(r io.Reader) (io.ReadCloser, error) {
+		return ioutil.NopCloser(r), nil
+	})
+	w = send("br", strings.NewReader(`{"name":"brotli"}`))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "brotli", w.Body.String())
+
+	// decompressors are registered per app
+	other := NewWithOptions(opts)
+	_, ok := other.codecs.decompressors["br"]
+	assert.False(t, ok)
+}
+
+func TestDecompressBodyLimit(t *testing.T) {
+	opts := NewOptions()
+	opts.UseRequestLogger = false
+	opts.DecompressBody = true
+	opts.MaxDecompressedBodySize = 1 << 10
+
+	app := NewWithOptions(opts)
+	app.POST("/upload", func(c *Context) {
+		data, err := c.GetRawData()
+		if err != nil {
+			c.ServeError(http.StatusBadRequest, err)
+			return
+		}
+		c.String(http.StatusOK, strconv.Itoa(len(data)))
+	})
+
+	send := func(size int) *httptest.ResponseRecorder {
+		var compressed bytes.Buffer
+		gz := gzip.NewWriter(&compressed)
+		_, _ = gz.Write(bytes.Repeat([]byte{'a'}, size))
+		_ = gz.Close()
+
+		req, _ := http.NewRequest("POST", "/upload", &compressed)
+		req.Header.Set("Content-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send(1 << 10)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1024", w.Body.String())
+
+	// small compressed body expanding beyond the limit
+	w = send(10 << 20)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), ErrDecompressedBodyTooLarge.Error())
+}
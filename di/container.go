@@ -1,9 +1,14 @@
 package di
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
 )
 
+// ErrNotFound is returned by Resolve when no registered value is assignable to destination
+var ErrNotFound = errors.New("dependency not found")
+
 // Container is a shortcut for []reflect.Value
 type Container []reflect.Value
 
@@ -33,6 +38,27 @@ func (c Container) CloneWithFieldsOf(i interface{}) Container {
 	return values
 }
 
+// Override returns a copy of container in which stub shadows registered
+// values, e.g. to inject a mock instead of real service in tests
+//
+// Stub is resolved before any other value assignable to the same field
+// and values of stub's type are dropped. The container itself is not modified.
+func Override(container Container, stub interface{}) Container {
+	val := ValueOf(stub)
+	if !goodVal(val) {
+		return container.Clone()
+	}
+
+	values := make(Container, 0, len(container)+1)
+	values = append(values, val)
+	for _, in := range container {
+		if in.Type() != val.Type() {
+			values = append(values, in)
+		}
+	}
+	return values
+}
+
 // Len returns Length of current Container slice
 func (c Container) Len() int {
 	return len(c)
@@ -123,3 +149,21 @@ func (c *Container) addIfNotExists(val reflect.Value) bool {
 	c.Add(val)
 	return true
 }
+
+// Resolve fills value pointed by ptr with the first registered value
+// assignable to it, e.g. an implementation of the pointed interface.
+func (c Container) Resolve(ptr interface{}) error {
+	dest := reflect.ValueOf(ptr)
+	if dest.Kind() != reflect.Ptr || dest.IsNil() {
+		return fmt.Errorf("resolve destination has to be non-nil pointer, got %T", ptr)
+	}
+
+	elem := dest.Elem()
+	for _, in := range c {
+		if equalTypes(in.Type(), elem.Type()) {
+			elem.Set(in)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrNotFound, elem.Type())
+}
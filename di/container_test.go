@@ -0,0 +1,58 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ordersDB interface {
+	Orders() []string
+}
+
+type postgresDB struct{}
+
+func (db *postgresDB) Orders() []string { return []string{"from postgres"} }
+
+type mockDB struct{}
+
+func (db *mockDB) Orders() []string { return []string{"from mock"} }
+
+type ordersController struct {
+	DB ordersDB
+}
+
+func TestOverride(t *testing.T) {
+	container := NewContainer()
+	container.Add(&postgresDB{})
+
+	overridden := Override(container, &mockDB{})
+
+	ctrl := &ordersController{}
+	Struct(ctrl, overridden...).Inject(ctrl)
+	assert.IsType(t, &mockDB{}, ctrl.DB)
+
+	// parent container keeps real implementation
+	ctrl = &ordersController{}
+	Struct(ctrl, container...).Inject(ctrl)
+	assert.IsType(t, &postgresDB{}, ctrl.DB)
+	assert.Equal(t, 1, container.Len())
+
+	// stub of the same type replaces registered value
+	stub := &postgresDB{}
+	overridden = Override(container, stub)
+	assert.Equal(t, 1, overridden.Len())
+	var db *postgresDB
+	assert.NoError(t, overridden.Resolve(&db))
+	assert.Same(t, stub, db)
+}
+
+func TestInjectZero(t *testing.T) {
+	mock := &mockDB{}
+	ctrl := &ordersController{}
+	assert.Equal(t, 1, InjectZero(ctrl, ValueOf(mock)))
+	assert.Same(t, mock, ctrl.DB)
+
+	assert.Equal(t, 0, InjectZero(ctrl, ValueOf(&postgresDB{})))
+	assert.Same(t, mock, ctrl.DB)
+}
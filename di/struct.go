@@ -203,3 +203,42 @@ func (s *StructInjector) AcquireSlice() []reflect.Value {
 	}
 	return []reflect.Value{reflect.New(s.elemType)}
 }
+
+// InjectZero sets exported fields of dest struct pointer which are still zero
+// to the first assignable value, fields which are already set are kept.
+// It returns the number of injected fields.
+func InjectZero(dest interface{}, values ...reflect.Value) (n int) {
+	v := IndirectValue(ValueOf(dest))
+	if v.Kind() != reflect.Struct || !v.CanSet() {
+		return 0
+	}
+
+	for _, f := range lookupFields(v.Type(), true, nil) {
+		field, ok := fieldByIndex(v, f.Index)
+		if !ok || !field.CanSet() || !IsZero(field) {
+			continue
+		}
+		for _, val := range values {
+			if equalTypes(val.Type(), f.Type) {
+				field.Set(val)
+				n++
+				break
+			}
+		}
+	}
+	return n
+}
+
+// fieldByIndex returns nested field, it reports false when embedded pointer on the way is nil
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
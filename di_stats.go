@@ -0,0 +1,55 @@
+package cucumber
+
+import (
+	"fmt"
+	"time"
+)
+
+// DIStats holds cumulative time spent injecting dependencies into registered
+// services and controllers, collected when Options.DebugDI is set
+type DIStats struct {
+	// Injections is the number of timed injections
+	Injections int
+	// Total is the time spent in all injections
+	Total time.Duration
+	// ByName is the time spent injecting each service or controller type
+	ByName map[string]time.Duration
+}
+
+// DIStats returns dependency injection timing collected so far
+func (a *App) DIStats() DIStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	stats := DIStats{
+		Injections: a.diStats.Injections,
+		Total:      a.diStats.Total,
+		ByName:     make(map[string]time.Duration, len(a.diStats.ByName)),
+	}
+	for name, d := range a.diStats.ByName {
+		stats.ByName[name] = d
+	}
+	return stats
+}
+
+// injectDeps injects registered dependencies into dest, timing it when Options.DebugDI is set
+func (a *App) injectDeps(name string, inject func()) {
+	if !a.DebugDI {
+		inject()
+		return
+	}
+
+	start := time.Now()
+	inject()
+	d := time.Since(start)
+
+	a.mu.Lock()
+	if a.diStats.ByName == nil {
+		a.diStats.ByName = make(map[string]time.Duration)
+	}
+	a.diStats.Injections++
+	a.diStats.Total += d
+	a.diStats.ByName[name] += d
+	a.mu.Unlock()
+
+	a.Logger.Debug(fmt.Sprintf("Injected dependencies into `%s` in %s", name, d))
+}
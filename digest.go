@@ -0,0 +1,311 @@
+package cucumber
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DigestUserKey is the context key holding username authenticated by DigestAuth
+const DigestUserKey = "digestUser"
+
+var (
+	// ErrDigestAuth is served when Digest credentials are missing or invalid
+	ErrDigestAuth = errors.New("invalid digest credentials")
+
+	// ErrDigestUserNotFound should be returned by DigestUserStore for unknown users
+	ErrDigestUserNotFound = errors.New("digest user not found")
+
+	// ErrDigestNonceTTL is served by DigestAuth when Options.DigestNonceTTL is negative
+	ErrDigestNonceTTL = errors.New("digest nonce TTL must be positive")
+)
+
+// DigestUserStore provides credentials of users authenticated by DigestAuth
+type DigestUserStore interface {
+	// GetHA1 returns hex encoded MD5 hash of "username:realm:password"
+	GetHA1(username string) (string, error)
+}
+
+// DigestUserStoreFunc is an adapter to allow the use of ordinary functions as DigestUserStore
+type DigestUserStoreFunc func(username string) (string, error)
+
+// GetHA1 calls f(username)
+func (f DigestUserStoreFunc) GetHA1(username string) (string, error) {
+	return f(username)
+}
+
+// NonceStore issues nonces of DigestAuth challenges and tracks their use
+type NonceStore interface {
+	// New issues a new nonce
+	New() (string, error)
+
+	// Use records nonce count nc sent with nonce, it returns false when nonce
+	// is unknown or nc was already used, and stale when nonce expired
+	Use(nonce string, nc uint64) (ok bool, stale bool)
+}
+
+// MemoryNonceStore is a NonceStore issuing stateless nonces signed with
+// HMAC, only nonces used by authenticated requests are kept in memory
+type MemoryNonceStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	key       []byte
+	used      map[string]*digestNonce
+	lastSweep time.Time
+}
+
+type digestNonce struct {
+	issued time.Time
+	nc     uint64
+}
+
+const (
+	// nonceDataSize is the size of nonce issue time and random part
+	nonceDataSize = 16
+	// nonceMACSize is the size of truncated nonce signature
+	nonceMACSize = 16
+)
+
+// InMemoryNonceStore returns a MemoryNonceStore which nonces expire after ttl
+func InMemoryNonceStore(ttl time.Duration) *MemoryNonceStore {
+	if ttl <= 0 {
+		panic(ErrDigestNonceTTL.Error())
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("can not generate digest nonce key: %v", err))
+	}
+	return &MemoryNonceStore{
+		ttl:       ttl,
+		key:       key,
+		used:      make(map[string]*digestNonce),
+		lastSweep: time.Now(),
+	}
+}
+
+// New issues a new nonce holding its issue time signed with HMAC
+func (s *MemoryNonceStore) New() (string, error) {
+	data := make([]byte, nonceDataSize, nonceDataSize+nonceMACSize)
+	binary.BigEndian.PutUint64(data, uint64(time.Now().UnixNano()))
+	if _, err := rand.Read(data[8:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(append(data, s.sign(data)...)), nil
+}
+
+// Use verifies nonce signature and records nonce count, which must grow
+// with each request using the nonce
+func (s *MemoryNonceStore) Use(nonce string, nc uint64) (bool, bool) {
+	raw, err := hex.DecodeString(nonce)
+	if err != nil || len(raw) != nonceDataSize+nonceMACSize ||
+		!hmac.Equal(raw[nonceDataSize:], s.sign(raw[:nonceDataSize])) {
+		return false, false
+	}
+	issued := time.Unix(0, int64(binary.BigEndian.Uint64(raw)))
+	now := time.Now()
+	if now.Sub(issued) > s.ttl {
+		return false, true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// drop expired nonces, they are reported as stale by their issue time
+	if now.Sub(s.lastSweep) > s.ttl {
+		for k, n := range s.used {
+			if now.Sub(n.issued) > s.ttl {
+				delete(s.used, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	n, ok := s.used[nonce]
+	if !ok {
+		n = &digestNonce{issued: issued}
+		s.used[nonce] = n
+	}
+	if nc <= n.nc {
+		return false, false
+	}
+	n.nc = nc
+	return true, false
+}
+
+// sign returns truncated HMAC of nonce data
+func (s *MemoryNonceStore) sign(data []byte) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(data)
+	return mac.Sum(nil)[:nonceMACSize]
+}
+
+// DigestAuth returns a middleware authenticating requests with HTTP Digest
+// Authentication (RFC 7616) using MD5 algorithm and "auth" quality of protection
+//
+// Nonces are issued by Options.DigestNonceStore, or by InMemoryNonceStore
+// expiring them after Options.DigestNonceTTL when not set. Requests reusing
+// nonce count are rejected to prevent replay. Authenticated username is
+// stored under DigestUserKey, see Context.DigestUser.
+func DigestAuth(realm string, store DigestUserStore) HandlerFunc {
+	var (
+		once     sync.Once
+		nonces   NonceStore
+		nonceErr error
+	)
+
+	challenge := func(c *Context, stale bool) {
+		nonce, err := nonces.New()
+		if err != nil {
+			c.Abort()
+			c.ServeError(http.StatusInternalServerError, err)
+			return
+		}
+		header := fmt.Sprintf(`Digest realm=%q, qop="auth", algorithm=MD5, nonce=%q`, realm, nonce)
+		if stale {
+			header += ", stale=true"
+		}
+		c.SetHeader("WWW-Authenticate", header)
+		c.Abort()
+		c.ServeError(http.StatusUnauthorized, ErrDigestAuth)
+	}
+
+	return func(c *Context) {
+		// nonce store is created on first request as it is configured by app options
+		once.Do(func() {
+			nonces = c.app.DigestNonceStore
+			if nonces != nil {
+				return
+			}
+			if c.app.DigestNonceTTL <= 0 {
+				nonceErr = ErrDigestNonceTTL
+				return
+			}
+			nonces = InMemoryNonceStore(c.app.DigestNonceTTL)
+		})
+		if nonceErr != nil {
+			c.Abort()
+			c.ServeError(http.StatusInternalServerError, nonceErr)
+			return
+		}
+
+		params, ok := parseDigestAuthorization(c.Header("Authorization"))
+		if !ok || params["realm"] != realm || params["qop"] != "auth" ||
+			params["cnonce"] == "" || params["uri"] != c.Request.RequestURI ||
+			(params["algorithm"] != "" && params["algorithm"] != "MD5") {
+			challenge(c, false)
+			return
+		}
+
+		nc, err := strconv.ParseUint(params["nc"], 16, 64)
+		if err != nil {
+			challenge(c, false)
+			return
+		}
+
+		ha1, err := store.GetHA1(params["username"])
+		if err != nil {
+			if !errors.Is(err, ErrDigestUserNotFound) {
+				c.Abort()
+				c.ServeError(http.StatusInternalServerError, err)
+				return
+			}
+			challenge(c, false)
+			return
+		}
+
+		ha2 := md5Hex(c.Request.Method + ":" + params["uri"])
+		expected := md5Hex(strings.Join([]string{ha1, params["nonce"], params["nc"], params["cnonce"], "auth", ha2}, ":"))
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(params["response"])) != 1 {
+			challenge(c, false)
+			return
+		}
+
+		// nonce is checked once response is valid, so forged requests can not use it up
+		if ok, stale := nonces.Use(params["nonce"], nc); !ok {
+			challenge(c, stale)
+			return
+		}
+
+		c.Set(DigestUserKey, params["username"])
+		c.Next()
+	}
+}
+
+// DigestUser returns username authenticated by DigestAuth middleware
+func (c *Context) DigestUser() string {
+	return c.GetString(DigestUserKey)
+}
+
+// DigestHA1 returns HA1 hash of user credentials expected from DigestUserStore
+func DigestHA1(username, realm, password string) string {
+	return md5Hex(username + ":" + realm + ":" + password)
+}
+
+// parseDigestAuthorization parses parameters of Digest Authorization header
+func parseDigestAuthorization(header string) (map[string]string, bool) {
+	const scheme = "Digest "
+	if len(header) < len(scheme) || !strings.EqualFold(header[:len(scheme)], scheme) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	s := header[len(scheme):]
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		if s == "" {
+			break
+		}
+
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return nil, false
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = strings.TrimLeft(s[eq+1:], " \t")
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			var b strings.Builder
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				b.WriteByte(s[i])
+			}
+			if i == len(s) {
+				return nil, false
+			}
+			value, s = b.String(), s[i+1:]
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				end = len(s)
+			}
+			value, s = strings.TrimSpace(s[:end]), s[end:]
+		}
+		params[key] = value
+	}
+
+	for _, key := range []string{"username", "nonce", "uri", "response"} {
+		if params[key] == "" {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,88 @@
+package cucumber
+
+import (
+	"net"
+	"strings"
+)
+
+// domainRoutes holds routes of domain-based groups shared between router groups
+type domainRoutes struct {
+	// routing tree nodes per host
+	trees map[string]map[string]*node
+	// wildcard handles requests to hosts without domain-based group
+	wildcard HandlersChain
+}
+
+// SubdomainRouter attaches routes of router which are matched only for requests
+// with given Host, e.g. "api.example.com"
+//
+// Requests to the host are routed exclusively to its routes, other requests are
+// routed to routes registered without host.
+func (r *Router) SubdomainRouter(host string, router *Router) *Router {
+	host = normalizeHost(host)
+	if host == "" {
+		panic("host can not be empty")
+	}
+	trees := r.domains.trees[host]
+	if trees == nil {
+		trees = make(map[string]*node)
+		r.domains.trees[host] = trees
+	}
+	r.attachStacks(router)
+	for _, route := range router.Routes() {
+		if route.Version != "" {
+			panic("versioned route " + route.Method + " " + route.Path + " can not be routed by host")
+		}
+		r.handle(trees, route.Method, route.Path, route.HandlersChain)
+	}
+	return r
+}
+
+// WildcardDomain registers handler for requests to any host which is not matched
+// by SubdomainRouter and has no route registered without host
+func (r *Router) WildcardDomain(handler HandlerFunc) *Router {
+	assertHandlers(HandlersChain{handler}, "wildcard domain")
+	r.domains.wildcard = r.combineHandlers(HandlersChain{handler})
+	return r
+}
+
+// Hostname returns request host without port
+func (c *Context) Hostname() string {
+	host, _ := splitHostPort(c.Request.Host)
+	return host
+}
+
+// Port returns request host port, or empty string when it is not specified
+func (c *Context) Port() string {
+	_, port := splitHostPort(c.Request.Host)
+	return port
+}
+
+// lookupDomain returns handlers of domain route matching request, ok reports
+// whether request host has domain-based group
+func (a *App) lookupDomain(c *Context) (handlers HandlersChain, ps Params, ok bool) {
+	if len(a.router.domains.trees) == 0 {
+		return nil, nil, false
+	}
+	trees, ok := a.router.domains.trees[normalizeHost(c.Hostname())]
+	if !ok {
+		return nil, nil, false
+	}
+	if root := trees[c.Request.Method]; root != nil {
+		handlers, ps, _ = root.getValue(c.Request.URL.Path, c.Params)
+	}
+	return handlers, ps, true
+}
+
+func splitHostPort(hostport string) (host, port string) {
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		// host without port
+		return strings.Trim(hostport, "[]"), ""
+	}
+	return host, port
+}
+
+func normalizeHost(host string) string {
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
@@ -0,0 +1,74 @@
+package cucumber
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func performHostRequest(app *App, host, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Host = host
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	return w
+}
+
+func TestDomainRouting(t *testing.T) {
+	apiRouter := NewRouter()
+	apiRouter.GET("/users", func(c *Context) {
+		c.String(http.StatusOK, "api users")
+	})
+	adminRouter := NewRouter()
+	adminRouter.GET("/users", func(c *Context) {
+		c.String(http.StatusOK, "admin users on "+c.Hostname()+":"+c.Port())
+	})
+
+	app := newTestAppInstance()
+	app.Router().
+		SubdomainRouter("api.example.com", apiRouter).
+		SubdomainRouter("Admin.Example.com", adminRouter).
+		WildcardDomain(func(c *Context) {
+			c.String(http.StatusOK, "default "+c.Hostname())
+		})
+	app.GET("/health", func(c *Context) {
+		c.String(http.StatusOK, "healthy")
+	})
+
+	tt := []struct {
+		Host string
+		Path string
+		Code int
+		Body string
+	}{
+		{"api.example.com", "/users", http.StatusOK, "api users"},
+		{"admin.example.com:8443", "/users", http.StatusOK, "admin users on admin.example.com:8443"},
+		{"shop.example.com", "/users", http.StatusOK, "default shop.example.com"},
+		{"example.com", "/", http.StatusOK, "default example.com"},
+		{"example.com", "/health", http.StatusOK, "healthy"},
+		{"api.example.com", "/health", http.StatusNotFound, default404Body},
+	}
+
+	for _, tc := range tt {
+		w := performHostRequest(app, tc.Host, tc.Path)
+		assert.Equal(t, tc.Code, w.Code, tc.Host+tc.Path)
+		assert.Equal(t, tc.Body, w.Body.String(), tc.Host+tc.Path)
+	}
+}
+
+func TestContextHostnamePort(t *testing.T) {
+	c, _ := createTestContext(httptest.NewRecorder())
+	for host, expected := range map[string][2]string{
+		"example.com":      {"example.com", ""},
+		"example.com:8080": {"example.com", "8080"},
+		"[::1]:443":        {"::1", "443"},
+		"[::1]":            {"::1", ""},
+	} {
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		c.Request.Host = host
+		assert.Equal(t, expected[0], c.Hostname(), host)
+		assert.Equal(t, expected[1], c.Port(), host)
+	}
+}
@@ -0,0 +1,62 @@
+package cucumber
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppDrain(t *testing.T) {
+	app := newTestAppInstance()
+	app.HTTPAddr = "127.0.0.1:0"
+
+	var started sync.WaitGroup
+	var completed int32
+	started.Add(3)
+	app.GET("/slow", func(c *Context) {
+		started.Done()
+		time.Sleep(100 * time.Millisecond)
+		atomic.AddInt32(&completed, 1)
+		c.Status(http.StatusOK)
+	})
+
+	// no server to drain yet
+	assert.NoError(t, app.Drain(context.Background()))
+
+	lis, err := app.listenHTTP()
+	if !assert.NoError(t, err) {
+		return
+	}
+	srv := app.newHTTPServer()
+	go srv.Serve(lis)
+	url := "http://" + lis.Addr().String() + "/slow"
+
+	var responses sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		responses.Add(1)
+		go func() {
+			defer responses.Done()
+			res, err := http.Get(url)
+			if assert.NoError(t, err) {
+				assert.Equal(t, http.StatusOK, res.StatusCode)
+				res.Body.Close()
+			}
+		}()
+	}
+	started.Wait()
+
+	assert.NoError(t, app.Drain(context.Background()))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&completed))
+	responses.Wait()
+
+	// new requests are not accepted
+	_, err = http.Get(url)
+	assert.Error(t, err)
+
+	assert.NoError(t, app.Drain(context.Background()))
+}
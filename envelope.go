@@ -0,0 +1,59 @@
+package cucumber
+
+// PaginatorContextKey is the context key holding request Paginator
+const PaginatorContextKey = "paginator"
+
+// ResponseEnvelope builds response body from response data and meta
+type ResponseEnvelope func(data, meta interface{}) interface{}
+
+// Envelope is default success response envelope
+type Envelope struct {
+	Data interface{} `json:"data"`
+	Meta interface{} `json:"meta,omitempty"`
+}
+
+func defaultResponseEnvelope(data, meta interface{}) interface{} {
+	return Envelope{Data: data, Meta: meta}
+}
+
+// SetPaginator stores Paginator in context so it is included in success responses
+func (c *Context) SetPaginator(p *Paginator) {
+	c.Set(PaginatorContextKey, p)
+}
+
+// Paginator returns Paginator stored in context
+func (c *Context) Paginator() (*Paginator, bool) {
+	if val, ok := c.Get(PaginatorContextKey); ok && val != nil {
+		p, ok := val.(*Paginator)
+		return p, ok
+	}
+	return nil, false
+}
+
+// Success serializes data wrapped in response envelope as JSON into the response body.
+func (c *Context) Success(code int, data interface{}) {
+	c.SuccessWithMeta(code, data, nil)
+}
+
+// SuccessWithMeta serializes data and meta wrapped in response envelope
+// as JSON into the response body.
+//
+// When Paginator is present in context, it is added to meta under `pagination`
+// key if meta is nil or map[string]interface{}.
+func (c *Context) SuccessWithMeta(code int, data, meta interface{}) {
+	if p, ok := c.Paginator(); ok {
+		switch m := meta.(type) {
+		case nil:
+			meta = map[string]interface{}{"pagination": p}
+		case map[string]interface{}:
+			merged := make(map[string]interface{}, len(m)+1)
+			for k, v := range m {
+				merged[k] = v
+			}
+			merged["pagination"] = p
+			meta = merged
+		}
+	}
+
+	c.JSON(code, c.app.ResponseEnvelope(data, meta))
+}
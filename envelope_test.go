@@ -0,0 +1,43 @@
+package cucumber
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextSuccess(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := createTestContext(w)
+
+	c.Success(http.StatusOK, map[string]string{"name": "cucumber"})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"data":{"name":"cucumber"}}`, w.Body.String())
+}
+
+func TestContextSuccessWithPaginator(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := createTestContext(w)
+	c.SetPaginator(&Paginator{Page: 2, PerPage: 10})
+
+	c.SuccessWithMeta(http.StatusOK, []int{1}, map[string]interface{}{"version": 1})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data":[1],"meta":{"version":1,"pagination":{"page":2,"perPage":10,"offset":0,"totalEntriesSize":0,"currentEntriesSize":0,"totalPages":0,"orderBy":"","orderDir":"","filter":""}}}`, w.Body.String())
+}
+
+func TestContextSuccessCustomEnvelope(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, app := createTestContext(w)
+	app.ResponseEnvelope = func(data, meta interface{}) interface{} {
+		return map[string]interface{}{"result": data, "ok": true}
+	}
+
+	c.Success(http.StatusCreated, "done")
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"result":"done","ok":true}`, w.Body.String())
+}
@@ -0,0 +1,54 @@
+package cucumber
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+const (
+	// EnvoyTraceIDKey is the context key holding trace ID read by EnvoyCompat
+	EnvoyTraceIDKey = "envoyTraceID"
+
+	// EnvoyExpectedTimeoutKey is the context key holding request timeout read by EnvoyCompat
+	EnvoyExpectedTimeoutKey = "envoyExpectedTimeout"
+)
+
+// EnvoyCompat returns a middleware mapping headers set by Envoy (and Istio
+// sidecars) on the request
+//
+// x-envoy-expected-rq-timeout-ms applies deadline on the request context and
+// x-b3-traceid is stored as trace ID, see Context.EnvoyTraceID. Request ID set
+// by Envoy in x-request-id is returned by Context.RequestID.
+//
+// x-forwarded-proto is not mapped, as it can be set by any client and the
+// framework has no trusted proxy configuration to verify it against.
+func EnvoyCompat() HandlerFunc {
+	return func(c *Context) {
+		if traceID := c.requestHeader("X-B3-Traceid"); traceID != "" {
+			c.Set(EnvoyTraceIDKey, traceID)
+		}
+
+		if ms, err := strconv.ParseInt(c.requestHeader("X-Envoy-Expected-Rq-Timeout-Ms"), 10, 64); err == nil && ms > 0 {
+			timeout := time.Duration(ms) * time.Millisecond
+			c.Set(EnvoyExpectedTimeoutKey, timeout)
+			ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+			defer cancel()
+			c.Request = c.Request.WithContext(ctx)
+		}
+
+		c.Next()
+	}
+}
+
+// EnvoyTraceID returns B3 trace ID read by EnvoyCompat middleware
+func (c *Context) EnvoyTraceID() string {
+	return c.GetString(EnvoyTraceIDKey)
+}
+
+// EnvoyExpectedTimeout returns request timeout expected by Envoy, read by EnvoyCompat middleware
+func (c *Context) EnvoyExpectedTimeout() (time.Duration, bool) {
+	val, _ := c.Get(EnvoyExpectedTimeoutKey)
+	timeout, ok := val.(time.Duration)
+	return timeout, ok
+}
@@ -0,0 +1,53 @@
+package cucumber
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvoyCompat(t *testing.T) {
+	app := newTestAppInstance()
+	app.Use(EnvoyCompat())
+
+	var (
+		traceID, requestID, scheme string
+		timeout                    time.Duration
+		hasTimeout, hasDeadline    bool
+	)
+	app.GET("/orders", func(c *Context) {
+		traceID = c.EnvoyTraceID()
+		requestID = c.RequestID()
+		scheme = c.Request.URL.Scheme
+		timeout, hasTimeout = c.EnvoyExpectedTimeout()
+		_, hasDeadline = c.Request.Context().Deadline()
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("x-request-id", "2f9c1c3e-envoy")
+	req.Header.Set("x-b3-traceid", "80f198ee56343ba864fe8b2a57d3eff7")
+	req.Header.Set("x-forwarded-proto", "https")
+	req.Header.Set("x-envoy-expected-rq-timeout-ms", "1500")
+	app.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "80f198ee56343ba864fe8b2a57d3eff7", traceID)
+	assert.Equal(t, "2f9c1c3e-envoy", requestID)
+	// client supplied x-forwarded-proto is not trusted
+	assert.Empty(t, scheme)
+	assert.True(t, hasTimeout)
+	assert.Equal(t, 1500*time.Millisecond, timeout)
+	assert.True(t, hasDeadline)
+
+	// without Envoy headers
+	req = httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("x-envoy-expected-rq-timeout-ms", "soon")
+	app.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Empty(t, traceID)
+	assert.Empty(t, scheme)
+	assert.False(t, hasTimeout)
+	assert.False(t, hasDeadline)
+}
@@ -0,0 +1,55 @@
+package cucumber
+
+import "hash/fnv"
+
+// FlagProvider evaluates feature flags for the current request
+type FlagProvider interface {
+	Flag(c *Context, name string) bool
+}
+
+// noFlagProvider is default FlagProvider with all flags disabled
+type noFlagProvider struct{}
+
+func (noFlagProvider) Flag(c *Context, name string) bool {
+	return false
+}
+
+// PercentageFlags is FlagProvider which enables each flag for given
+// percentage of requests
+//
+// Requests are bucketed by hashing the request ID, so the same request
+// always gets the same evaluation
+type PercentageFlags map[string]uint32
+
+// Flag implements FlagProvider interface
+func (p PercentageFlags) Flag(c *Context, name string) bool {
+	percentage, ok := p[name]
+	if !ok {
+		return false
+	}
+	return FlagBucket(name, c.RequestID()) < percentage
+}
+
+// FlagBucket returns stable bucket in range [0, 100) for given flag and key
+func FlagBucket(name, key string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(key))
+	return h.Sum32() % 100
+}
+
+// Flag reports whether feature flag is enabled for current request
+//
+// Evaluations are cached for the lifetime of the request
+func (c *Context) Flag(name string) bool {
+	if enabled, ok := c.flags[name]; ok {
+		return enabled
+	}
+	if c.flags == nil {
+		c.flags = make(map[string]bool)
+	}
+	enabled := c.app.FlagProvider.Flag(c, name)
+	c.flags[name] = enabled
+	return enabled
+}
@@ -0,0 +1,53 @@
+package cucumber
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingFlagProvider struct {
+	calls int
+}
+
+func (p *countingFlagProvider) Flag(c *Context, name string) bool {
+	p.calls++
+	return name == "enabled"
+}
+
+func TestContextFlagDefault(t *testing.T) {
+	c, _ := createTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+
+	assert.False(t, c.Flag("anything"))
+}
+
+func TestContextFlagCache(t *testing.T) {
+	provider := &countingFlagProvider{}
+	c, app := createTestContext(httptest.NewRecorder())
+	app.FlagProvider = provider
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+
+	assert.True(t, c.Flag("enabled"))
+	assert.True(t, c.Flag("enabled"))
+	assert.False(t, c.Flag("disabled"))
+	assert.Equal(t, 2, provider.calls)
+
+	c.reset()
+	assert.True(t, c.Flag("enabled"))
+	assert.Equal(t, 3, provider.calls)
+}
+
+func TestPercentageFlags(t *testing.T) {
+	c, app := createTestContext(httptest.NewRecorder())
+	app.FlagProvider = PercentageFlags{"all": 100, "none": 0}
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	c.Request.Header.Set(RequestIDHeader, "bucket-id")
+
+	assert.True(t, c.Flag("all"))
+	assert.False(t, c.Flag("none"))
+	assert.False(t, c.Flag("missing"))
+	assert.Equal(t, FlagBucket("experiment", "bucket-id"), FlagBucket("experiment", "bucket-id"))
+}
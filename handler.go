@@ -1,5 +1,7 @@
 package cucumber
 
+import "net/http"
+
 // HandlerFunc defines the handler used by router.
 type HandlerFunc func(*Context)
 
@@ -13,3 +15,18 @@ func (c HandlersChain) Last() HandlerFunc {
 	}
 	return nil
 }
+
+// WrapH adapts http.Handler into HandlerFunc
+//
+// Handler writes through context Response, so response status
+// and size are tracked as for any other HandlerFunc
+func WrapH(h http.Handler) HandlerFunc {
+	return func(c *Context) {
+		h.ServeHTTP(c.Response, c.Request)
+	}
+}
+
+// WrapF adapts http.HandlerFunc into HandlerFunc
+func WrapF(f http.HandlerFunc) HandlerFunc {
+	return WrapH(f)
+}
@@ -0,0 +1,37 @@
+package cucumber
+
+import (
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// registerHealthService registers grpc.health.v1 health server, all services
+// registered so far are reported as serving
+func (a *App) registerHealthService() {
+	a.health = health.NewServer()
+	healthpb.RegisterHealthServer(a.server, a.health)
+	a.setServicesServing(nil)
+}
+
+// setServicesServing marks services registered on gRPC server which are not in known as serving
+func (a *App) setServicesServing(known map[string]bool) {
+	if a.health == nil {
+		return
+	}
+	for name := range a.server.GetServiceInfo() {
+		if !known[name] {
+			a.health.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+		}
+	}
+}
+
+// SetGRPCServiceHealth updates serving status of service reported by gRPC health service,
+// empty service name sets overall server health
+//
+// It panics unless Options.RegisterGRPCHealthService is enabled.
+func (a *App) SetGRPCServiceHealth(service string, status healthpb.HealthCheckResponse_ServingStatus) {
+	if a.health == nil {
+		panic("gRPC health service is not registered, enable Options.RegisterGRPCHealthService")
+	}
+	a.health.SetServingStatus(service, status)
+}
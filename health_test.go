@@ -0,0 +1,81 @@
+package cucumber
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestAppGRPCHealthService(t *testing.T) {
+	opts := NewOptions()
+	opts.UseViewEngine = false
+	opts.UseRequestLogger = false
+	opts.UseSession = false
+	opts.UseTranslator = false
+	opts.RegisterGRPCHealthService = true
+
+	app := NewWithOptions(opts)
+
+	lis := bufconn.Listen(1 << 20)
+	go app.server.Serve(lis)
+	defer app.server.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithInsecure(),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	check := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		if !assert.NoError(t, err) {
+			return healthpb.HealthCheckResponse_UNKNOWN
+		}
+		return resp.Status
+	}
+
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(""))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(healthpb.Health_ServiceDesc.ServiceName))
+
+	app.SetGRPCServiceHealth(healthpb.Health_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_NOT_SERVING)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(healthpb.Health_ServiceDesc.ServiceName))
+
+	assert.Panics(t, func() {
+		newTestAppInstance().SetGRPCServiceHealth("", healthpb.HealthCheckResponse_NOT_SERVING)
+	})
+}
+
+func TestAppConfigureGRPC(t *testing.T) {
+	opts := NewOptions()
+	opts.UseRequestLogger = false
+	opts.RegisterGRPCHealthService = true
+
+	app := NewWithOptions(opts)
+	assert.Same(t, app.server, app.GRPCServer())
+
+	calls := 0
+	app.ConfigureGRPC(func(s *grpc.Server) {
+		calls++
+		s.RegisterService(&grpc.ServiceDesc{ServiceName: "plugin.Plugin", HandlerType: (*interface{})(nil)}, struct{}{})
+	})
+	assert.Equal(t, 0, calls)
+
+	// callbacks run right before serving
+	app.configureGRPC()
+	app.configureGRPC()
+	assert.Equal(t, 1, calls)
+	assert.Contains(t, app.GRPCServer().GetServiceInfo(), "plugin.Plugin")
+
+	resp, err := app.health.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "plugin.Plugin"})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+}
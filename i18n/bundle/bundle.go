@@ -244,6 +244,17 @@ func (b *Bundle) LanguageTranslationIDs(languageTag string) []string {
 	return ids
 }
 
+// HasTranslation reports whether translation with given id exists for a language,
+// translations of more specific languages are considered as well.
+func (b *Bundle) HasTranslation(languageTag, translationID string) bool {
+	for _, lang := range language.Parse(languageTag) {
+		if b.translation(lang, translationID) != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // MustTfunc is similar to Tfunc except it panics if an error happens.
 func (b *Bundle) MustTfunc(pref string, prefs ...string) TranslateFunc {
 	tfunc, err := b.Tfunc(pref, prefs...)
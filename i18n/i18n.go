@@ -37,6 +37,11 @@ func IdentityTfunc() TranslateFunc {
 
 var defaultBundle = bundle.New()
 
+// DefaultBundle returns bundle used by package level functions
+func DefaultBundle() *bundle.Bundle {
+	return defaultBundle
+}
+
 // MustLoadTranslationFile is similar to LoadTranslationFile
 // except it panics if an error happens.
 func MustLoadTranslationFile(filename string) {
@@ -1,6 +1,49 @@
 package cucumber
 
+import "context"
+
 // Initer allows to init service during registration
+//
+// Init is called right away by Register, so it can use only services
+// registered before. Services which also implement InitOrderer are
+// initialized later by App.Init.
 type Initer interface {
 	Init(app *App)
 }
+
+// InitOrderer defers Init of service until App.Init, which runs when
+// application starts, so Init can use services registered later
+//
+// Services are initialized in ascending InitOrder, services with the same
+// order in registration order, and before any DeferredInit.
+type InitOrderer interface {
+	InitOrder() int
+}
+
+// orderedIniter is Initer waiting for App.Init
+type orderedIniter struct {
+	Initer
+	order int
+}
+
+// DeferredIniter allows to defer service initialization until application start
+//
+// DeferredInit is called after all services are registered,
+// but before the first request is served
+type DeferredIniter interface {
+	DeferredInit(app *App) error
+}
+
+// Starter allows to start service background work (e.g. message consumer)
+// when application starts, after deferred initialization
+//
+// Context passed to Start is cancelled when application shuts down.
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// Stopper allows to release service resources (e.g. connection pool)
+// on application shutdown, once servers finished in-flight requests
+type Stopper interface {
+	Stop(ctx context.Context) error
+}
@@ -0,0 +1,122 @@
+package cucumber
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"google.golang.org/grpc"
+)
+
+// Priorities of built-in unary interceptors
+//
+// Interceptors with lower priority run first
+const (
+	InterceptorPriorityAPM           = 100
+	InterceptorPriorityRequestLogger = 200
+	InterceptorPriorityPanicRecovery = 300
+)
+
+// ErrGRPCServiceRegistered is returned when interceptor is added
+// after gRPC service is already registered
+var ErrGRPCServiceRegistered = errors.New("gRPC interceptors can not be added after service is registered")
+
+type prioritizedInterceptor struct {
+	priority    int
+	interceptor grpc.UnaryServerInterceptor
+}
+
+// AddInterceptor adds unary interceptor into the chain at position
+// defined by its priority
+//
+// Interceptors with the same priority run in the order they were added.
+// Interceptors from Options.UnaryInterceptors have priority 0. It panics
+// when called after RegisterServiceHandler.
+func (a *App) AddInterceptor(priority int, interceptor grpc.UnaryServerInterceptor) *App {
+	a.mu.Lock()
+	registered := a.servicesRegistered
+	a.mu.Unlock()
+	if registered {
+		panic(ErrGRPCServiceRegistered.Error())
+	}
+	a.addInterceptor(priority, interceptor)
+	return a
+}
+
+func (a *App) addInterceptor(priority int, interceptor grpc.UnaryServerInterceptor) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	i := sort.Search(len(a.unaryInterceptors), func(i int) bool {
+		return a.unaryInterceptors[i].priority > priority
+	})
+
+	interceptors := make([]prioritizedInterceptor, 0, len(a.unaryInterceptors)+1)
+	interceptors = append(interceptors, a.unaryInterceptors[:i]...)
+	interceptors = append(interceptors, prioritizedInterceptor{priority: priority, interceptor: interceptor})
+	interceptors = append(interceptors, a.unaryInterceptors[i:]...)
+	a.unaryInterceptors = interceptors
+
+	chain := make([]grpc.UnaryServerInterceptor, len(interceptors))
+	for i, pi := range interceptors {
+		chain[i] = pi.interceptor
+	}
+	a.unaryChain.Store(ChainUnaryServer(chain...))
+}
+
+// interceptUnary executes current unary interceptors chain
+func (a *App) interceptUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	chain := a.unaryChain.Load().(grpc.UnaryServerInterceptor)
+	return chain(ctx, req, info, handler)
+}
+
+// AddGRPCUnaryInterceptor appends unary interceptor to the end of the chain
+//
+// It returns ErrGRPCServiceRegistered if called after RegisterServiceHandler.
+func (a *App) AddGRPCUnaryInterceptor(interceptor grpc.UnaryServerInterceptor) error {
+	a.mu.Lock()
+	registered := a.servicesRegistered
+	priority := 0
+	if n := len(a.unaryInterceptors); n > 0 {
+		priority = a.unaryInterceptors[n-1].priority
+	}
+	a.mu.Unlock()
+
+	if registered {
+		return ErrGRPCServiceRegistered
+	}
+	a.addInterceptor(priority, interceptor)
+	return nil
+}
+
+// AddGRPCStreamInterceptor appends stream interceptor to the end of the chain
+//
+// It returns ErrGRPCServiceRegistered if called after RegisterServiceHandler.
+func (a *App) AddGRPCStreamInterceptor(interceptor grpc.StreamServerInterceptor) error {
+	a.mu.Lock()
+	registered := a.servicesRegistered
+	a.mu.Unlock()
+
+	if registered {
+		return ErrGRPCServiceRegistered
+	}
+	a.addStreamInterceptor(interceptor)
+	return nil
+}
+
+func (a *App) addStreamInterceptor(interceptor grpc.StreamServerInterceptor) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	interceptors := make([]grpc.StreamServerInterceptor, 0, len(a.streamInterceptors)+1)
+	interceptors = append(interceptors, a.streamInterceptors...)
+	interceptors = append(interceptors, interceptor)
+	a.streamInterceptors = interceptors
+	a.streamChain.Store(ChainStreamServer(interceptors...))
+}
+
+// interceptStream executes current stream interceptors chain
+func (a *App) interceptStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	chain := a.streamChain.Load().(grpc.StreamServerInterceptor)
+	return chain(srv, ss, info, handler)
+}
@@ -0,0 +1,108 @@
+package cucumber
+
+import (
+	"context"
+	"testing"
+
+	"github.com/AjdinHalac/cucumber/log"
+	"github.com/stretchr/testify/assert"
+	"go.elastic.co/apm"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestAppAddInterceptor(t *testing.T) {
+	signature := ""
+
+	opts := NewOptions()
+	opts.UseViewEngine = false
+	opts.UseSession = false
+	opts.UseTranslator = false
+	opts.UnaryInterceptors = []grpc.UnaryServerInterceptor{
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			signature += "A"
+			return handler(ctx, req)
+		},
+	}
+
+	app := NewWithOptions(opts)
+	app.AddInterceptor(150, func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		// runs after APM and before request logger
+		_, hasLogger := log.FromContext(ctx)
+		if apm.TransactionFromContext(ctx) != nil && !hasLogger {
+			signature += "C"
+		}
+		return handler(ctx, req)
+	})
+	app.AddInterceptor(50, func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		signature += "B"
+		return handler(ctx, req)
+	})
+	app.AddInterceptor(400, func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if _, hasLogger := log.FromContext(ctx); hasLogger {
+			signature += "D"
+		}
+		return handler(ctx, req)
+	})
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/cucumber.Test/Method"}
+	_, err := app.interceptUnary(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		signature += "E"
+		return nil, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ABCDE", signature)
+}
+
+type healthService struct {
+	*health.Server
+}
+
+func (s *healthService) RegisterProtoServer(srv *grpc.Server) {
+	healthpb.RegisterHealthServer(srv, s.Server)
+}
+
+func TestAppAddGRPCInterceptors(t *testing.T) {
+	signature := ""
+	app := newTestAppInstance()
+
+	err := app.AddGRPCUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		signature += "U"
+		return handler(ctx, req)
+	})
+	assert.NoError(t, err)
+
+	err = app.AddGRPCStreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		signature += "S"
+		return handler(srv, ss)
+	})
+	assert.NoError(t, err)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/cucumber.Test/Method"}
+	_, err = app.interceptUnary(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "U", signature)
+
+	app.RegisterServiceHandler(&healthService{health.NewServer()})
+
+	err = app.AddGRPCUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(ctx, req)
+	})
+	assert.Equal(t, ErrGRPCServiceRegistered, err)
+
+	err = app.AddGRPCStreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, ss)
+	})
+	assert.Equal(t, ErrGRPCServiceRegistered, err)
+	assert.Len(t, app.streamInterceptors, 2)
+
+	assert.PanicsWithValue(t, ErrGRPCServiceRegistered.Error(), func() {
+		app.AddInterceptor(0, func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			return handler(ctx, req)
+		})
+	})
+}
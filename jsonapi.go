@@ -0,0 +1,143 @@
+package cucumber
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/AjdinHalac/cucumber/render"
+)
+
+// JSONAPIContentType is the media type of JSON:API documents (jsonapi.org)
+const JSONAPIContentType = "application/vnd.api+json"
+
+// ErrJSONAPIDocument is returned when document has neither data nor errors, or has both
+var ErrJSONAPIDocument = errors.New("invalid JSON:API document")
+
+// JSONAPIDocument is top level JSON:API document
+type JSONAPIDocument struct {
+	Data   interface{}            `json:"data,omitempty"`
+	Errors []JSONAPIError         `json:"errors,omitempty"`
+	Meta   map[string]interface{} `json:"meta,omitempty"`
+	Links  map[string]string      `json:"links,omitempty"`
+}
+
+// JSONAPIError is JSON:API error object
+type JSONAPIError struct {
+	ID     string                 `json:"id,omitempty"`
+	Status string                 `json:"status,omitempty"`
+	Code   string                 `json:"code,omitempty"`
+	Title  string                 `json:"title,omitempty"`
+	Detail string                 `json:"detail,omitempty"`
+	Source map[string]string      `json:"source,omitempty"`
+	Meta   map[string]interface{} `json:"meta,omitempty"`
+}
+
+// JSONAPI serializes JSON:API document into the response body with
+// application/vnd.api+json content type
+func (c *Context) JSONAPI(code int, doc JSONAPIDocument) error {
+	if (doc.Data == nil) == (len(doc.Errors) == 0) {
+		return ErrJSONAPIDocument
+	}
+	c.SetContentType([]string{JSONAPIContentType})
+	c.Render(code, render.JSON{Data: doc})
+	return nil
+}
+
+// JSONAPIMiddleware returns a middleware validating responses with JSON:API
+// content type
+//
+// Responses are buffered and sent with exact application/vnd.api+json content
+// type, without media type parameters. Body without top level "data" or
+// "errors" member is replaced with 500 Internal Server Error JSON:API document.
+func JSONAPIMiddleware() HandlerFunc {
+	return func(c *Context) {
+		writer := &jsonAPIWriter{ResponseWriter: c.writermem.ResponseWriter}
+		c.writermem.ResponseWriter = writer
+		c.writermem.onBeforeWriteHeader(func() {
+			header := c.writermem.Header()
+			if filterFlags(header.Get(ContentTypeHeader)) == JSONAPIContentType {
+				header.Set(ContentTypeHeader, JSONAPIContentType)
+			}
+		})
+
+		c.Next()
+
+		c.writermem.ResponseWriter = writer.ResponseWriter
+		if !writer.buffering {
+			return
+		}
+
+		body := writer.body.Bytes()
+		if err := validateJSONAPIDocument(body); err != nil {
+			c.Error(err)
+			writer.status = http.StatusInternalServerError
+			body, _ = json.Marshal(JSONAPIDocument{Errors: []JSONAPIError{{
+				Status: strconv.Itoa(http.StatusInternalServerError),
+				Title:  http.StatusText(http.StatusInternalServerError),
+			}}})
+		}
+		writer.Header().Del("Content-Length")
+		writer.ResponseWriter.WriteHeader(writer.status)
+		writer.ResponseWriter.Write(body)
+	}
+}
+
+// validateJSONAPIDocument checks body is JSON object with "data" or "errors" member
+func validateJSONAPIDocument(body []byte) error {
+	if len(body) == 0 {
+		return nil
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return ErrJSONAPIDocument
+	}
+	_, hasData := doc["data"]
+	_, hasErrors := doc["errors"]
+	if hasData == hasErrors {
+		return ErrJSONAPIDocument
+	}
+	return nil
+}
+
+// jsonAPIWriter buffers responses with JSON:API content type
+type jsonAPIWriter struct {
+	http.ResponseWriter
+	body      bytes.Buffer
+	status    int
+	buffering bool
+}
+
+func (w *jsonAPIWriter) WriteHeader(code int) {
+	if w.Header().Get(ContentTypeHeader) == JSONAPIContentType {
+		w.status = code
+		w.buffering = true
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *jsonAPIWriter) Write(data []byte) (int, error) {
+	if w.buffering {
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *jsonAPIWriter) Flush() {
+	if !w.buffering {
+		w.ResponseWriter.(http.Flusher).Flush()
+	}
+}
+
+func (w *jsonAPIWriter) CloseNotify() <-chan bool {
+	return w.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+func (w *jsonAPIWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
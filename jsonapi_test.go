@@ -0,0 +1,65 @@
+package cucumber
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type jsonAPIArticle struct {
+	Type       string            `json:"type"`
+	ID         string            `json:"id"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+func TestJSONAPI(t *testing.T) {
+	app := newTestAppInstance()
+	app.Use(JSONAPIMiddleware())
+	app.GET("/articles/1", func(c *Context) {
+		err := c.JSONAPI(http.StatusOK, JSONAPIDocument{
+			Data:  jsonAPIArticle{Type: "articles", ID: "1", Attributes: map[string]string{"title": "Rails is Omakase"}},
+			Meta:  map[string]interface{}{"version": 2},
+			Links: map[string]string{"self": "/articles/1"},
+		})
+		assert.NoError(t, err)
+	})
+	app.GET("/articles/2", func(c *Context) {
+		c.JSONAPI(http.StatusNotFound, JSONAPIDocument{Errors: []JSONAPIError{{Status: "404", Title: "Not Found"}}})
+	})
+	app.GET("/charset", func(c *Context) {
+		c.Response.Header().Set(ContentTypeHeader, JSONAPIContentType+"; charset=utf-8")
+		c.Data(http.StatusOK, []byte(`{"meta":{}}`))
+	})
+	app.GET("/plain", func(c *Context) {
+		c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+	})
+
+	w := performRequest(app, http.MethodGet, "/articles/1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, JSONAPIContentType, w.Header().Get(ContentTypeHeader))
+	var doc map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, map[string]interface{}{"title": "Rails is Omakase"}, doc["data"].(map[string]interface{})["attributes"])
+	assert.Equal(t, map[string]interface{}{"version": float64(2)}, doc["meta"])
+	assert.Equal(t, map[string]interface{}{"self": "/articles/1"}, doc["links"])
+	assert.NotContains(t, doc, "errors")
+
+	w = performRequest(app, http.MethodGet, "/articles/2")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"errors":[{"status":"404","title":"Not Found"}]}`, w.Body.String())
+
+	// document without data and errors is replaced
+	w = performRequest(app, http.MethodGet, "/charset")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, JSONAPIContentType, w.Header().Get(ContentTypeHeader))
+	assert.JSONEq(t, `{"errors":[{"status":"500","title":"Internal Server Error"}]}`, w.Body.String())
+
+	w = performRequest(app, http.MethodGet, "/plain")
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get(ContentTypeHeader))
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+
+	c, _ := createTestContext(nil)
+	assert.ErrorIs(t, c.JSONAPI(http.StatusOK, JSONAPIDocument{}), ErrJSONAPIDocument)
+}
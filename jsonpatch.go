@@ -0,0 +1,442 @@
+package cucumber
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MIMEJSONPatch is the content type of JSON Patch (RFC 6902) documents
+	MIMEJSONPatch = "application/json-patch+json"
+	// MIMEMergePatch is the content type of JSON Merge Patch (RFC 7386) documents
+	MIMEMergePatch = "application/merge-patch+json"
+)
+
+// ErrPatchMethod is returned when patch is read from request which is not PATCH
+var ErrPatchMethod = errors.New("patch document requires PATCH method")
+
+// patchOperation is single JSON Patch operation
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// JSONPatch reads JSON Patch (RFC 6902) document from request body and applies it to target
+//
+// Request has to use PATCH method and application/json-patch+json content type. Operations
+// referencing unknown paths, failed test operations and values which do not fit target
+// type are returned as errors. Target fields which are not serialized to JSON keep their values.
+func (c *Context) JSONPatch(target interface{}) error {
+	if err := c.assertPatch(MIMEJSONPatch); err != nil {
+		return err
+	}
+
+	var ops []patchOperation
+	if err := json.NewDecoder(c.Request.Body).Decode(&ops); err != nil {
+		return err
+	}
+
+	doc, err := patchDocument(target)
+	if err != nil {
+		return err
+	}
+	for _, op := range ops {
+		if doc, err = applyPatchOperation(doc, op); err != nil {
+			return err
+		}
+	}
+	return patchTarget(doc, target)
+}
+
+// JSONMergePatch reads JSON Merge Patch (RFC 7386) document from request body and applies it to target
+//
+// Request has to use PATCH method and application/merge-patch+json content type.
+func (c *Context) JSONMergePatch(target interface{}) error {
+	if err := c.assertPatch(MIMEMergePatch); err != nil {
+		return err
+	}
+
+	var patch interface{}
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&patch); err != nil {
+		return err
+	}
+
+	doc, err := patchDocument(target)
+	if err != nil {
+		return err
+	}
+	return patchTarget(mergePatch(doc, patch), target)
+}
+
+func (c *Context) assertPatch(contentType string) error {
+	if c.Request.Method != http.MethodPatch {
+		return ErrPatchMethod
+	}
+	if normalizeContentType(c.ContentType()) != contentType {
+		return ErrUnsupportedMediaType
+	}
+	return nil
+}
+
+// patchDocument returns generic JSON representation of target
+func patchDocument(target interface{}) (interface{}, error) {
+	data, err := json.Marshal(target)
+	if err != nil {
+		return nil, err
+	}
+	return decodePatchValue(data)
+}
+
+// patchTarget stores patched document into target
+//
+// Fields which are not serialized to JSON, e.g. unexported or tagged with
+// json:"-", keep their values, serialized fields removed by patch are reset.
+func patchTarget(doc interface{}, target interface{}) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return errors.New("patch target must be a non-nil pointer")
+	}
+	// decode into fresh value, so removed fields do not keep previous values
+	// and target is left untouched on error
+	decoded := reflect.New(value.Elem().Type())
+	if err := json.Unmarshal(data, decoded.Interface()); err != nil {
+		return err
+	}
+	patched := reflect.New(value.Elem().Type()).Elem()
+	patched.Set(value.Elem())
+	setJSONFields(patched, decoded.Elem())
+	value.Elem().Set(patched)
+	return nil
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// setJSONFields sets fields of dst serialized to JSON to values of decoded src
+func setJSONFields(dst, src reflect.Value) {
+	typ := dst.Type()
+	if reflect.PtrTo(typ).Implements(jsonUnmarshalerType) || reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		dst.Set(src)
+		return
+	}
+
+	switch dst.Kind() {
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if field.Tag.Get("json") == "-" {
+				continue
+			}
+			if field.PkgPath != "" {
+				// promoted fields of unexported embedded structs are serialized
+				if field.Anonymous && field.Type.Kind() == reflect.Struct {
+					setJSONFields(dst.Field(i), src.Field(i))
+				}
+				continue
+			}
+			setJSONFields(dst.Field(i), src.Field(i))
+		}
+	case reflect.Ptr:
+		if dst.IsNil() || src.IsNil() || typ.Elem().Kind() != reflect.Struct {
+			dst.Set(src)
+			return
+		}
+		// copy pointed struct, so target is not modified through shared pointer
+		elem := reflect.New(typ.Elem())
+		elem.Elem().Set(dst.Elem())
+		setJSONFields(elem.Elem(), src.Elem())
+		dst.Set(elem)
+	default:
+		dst.Set(src)
+	}
+}
+
+func decodePatchValue(data []byte) (interface{}, error) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err := decoder.Decode(&value)
+	return value, err
+}
+
+func applyPatchOperation(doc interface{}, op patchOperation) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, fmt.Errorf("json patch: %s operation on %q requires value", op.Op, op.Path)
+		}
+		if value, err = decodePatchValue(op.Value); err != nil {
+			return nil, err
+		}
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		if value, err = pointerGet(doc, from, op.From); err != nil {
+			return nil, err
+		}
+		if op.Op == "move" {
+			if doc, err = pointerUpdate(doc, from, op.From, removeValue); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// whole document is referenced
+	if len(path) == 0 {
+		switch op.Op {
+		case "add", "replace", "move", "copy":
+			return value, nil
+		case "remove":
+			return nil, nil
+		}
+	}
+
+	switch op.Op {
+	case "add", "move", "copy":
+		return pointerUpdate(doc, path, op.Path, addValue(value))
+	case "remove":
+		return pointerUpdate(doc, path, op.Path, removeValue)
+	case "replace":
+		return pointerUpdate(doc, path, op.Path, replaceValue(value))
+	case "test":
+		current, err := pointerGet(doc, path, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(current, value) {
+			return nil, fmt.Errorf("json patch: test operation on %q failed", op.Path)
+		}
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("json patch: unknown operation %q", op.Op)
+	}
+}
+
+// jsonEqual compares JSON values, numbers are compared by their value, e.g. 3 equals 3.0
+func jsonEqual(a, b interface{}) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, okA := new(big.Float).SetString(a.String())
+		y, okB := new(big.Float).SetString(b.String())
+		return okA && okB && x.Cmp(y) == 0
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for key, value := range a {
+			other, ok := b[key]
+			if !ok || !jsonEqual(value, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jsonEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+// parsePointer splits JSON Pointer (RFC 6901) into unescaped reference tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("json patch: invalid path %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+func pointerGet(doc interface{}, tokens []string, pointer string) (interface{}, error) {
+	for _, token := range tokens {
+		switch d := doc.(type) {
+		case map[string]interface{}:
+			value, ok := d[token]
+			if !ok {
+				return nil, pathNotFound(pointer)
+			}
+			doc = value
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(d) {
+				return nil, pathNotFound(pointer)
+			}
+			doc = d[i]
+		default:
+			return nil, pathNotFound(pointer)
+		}
+	}
+	return doc, nil
+}
+
+// containerUpdate changes value stored under key of map or slice container
+// and returns updated container
+type containerUpdate func(container interface{}, key string, pointer string) (interface{}, error)
+
+// pointerUpdate applies update on parent container of the value referenced by tokens
+func pointerUpdate(doc interface{}, tokens []string, pointer string, update containerUpdate) (interface{}, error) {
+	if len(tokens) == 1 {
+		return update(doc, tokens[0], pointer)
+	}
+
+	switch d := doc.(type) {
+	case map[string]interface{}:
+		child, ok := d[tokens[0]]
+		if !ok {
+			return nil, pathNotFound(pointer)
+		}
+		updated, err := pointerUpdate(child, tokens[1:], pointer, update)
+		if err != nil {
+			return nil, err
+		}
+		d[tokens[0]] = updated
+		return d, nil
+	case []interface{}:
+		i, err := strconv.Atoi(tokens[0])
+		if err != nil || i < 0 || i >= len(d) {
+			return nil, pathNotFound(pointer)
+		}
+		updated, err := pointerUpdate(d[i], tokens[1:], pointer, update)
+		if err != nil {
+			return nil, err
+		}
+		d[i] = updated
+		return d, nil
+	default:
+		return nil, pathNotFound(pointer)
+	}
+}
+
+func addValue(value interface{}) containerUpdate {
+	return func(container interface{}, key string, pointer string) (interface{}, error) {
+		switch d := container.(type) {
+		case map[string]interface{}:
+			d[key] = value
+			return d, nil
+		case []interface{}:
+			i := len(d)
+			if key != "-" {
+				var err error
+				if i, err = strconv.Atoi(key); err != nil || i < 0 || i > len(d) {
+					return nil, pathNotFound(pointer)
+				}
+			}
+			d = append(d, nil)
+			copy(d[i+1:], d[i:])
+			d[i] = value
+			return d, nil
+		default:
+			return nil, pathNotFound(pointer)
+		}
+	}
+}
+
+func removeValue(container interface{}, key string, pointer string) (interface{}, error) {
+	switch d := container.(type) {
+	case map[string]interface{}:
+		if _, ok := d[key]; !ok {
+			return nil, pathNotFound(pointer)
+		}
+		delete(d, key)
+		return d, nil
+	case []interface{}:
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= len(d) {
+			return nil, pathNotFound(pointer)
+		}
+		return append(d[:i], d[i+1:]...), nil
+	default:
+		return nil, pathNotFound(pointer)
+	}
+}
+
+func replaceValue(value interface{}) containerUpdate {
+	return func(container interface{}, key string, pointer string) (interface{}, error) {
+		switch d := container.(type) {
+		case map[string]interface{}:
+			if _, ok := d[key]; !ok {
+				return nil, pathNotFound(pointer)
+			}
+			d[key] = value
+			return d, nil
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(d) {
+				return nil, pathNotFound(pointer)
+			}
+			d[i] = value
+			return d, nil
+		default:
+			return nil, pathNotFound(pointer)
+		}
+	}
+}
+
+func pathNotFound(pointer string) error {
+	return fmt.Errorf("json patch: path %q does not exist", pointer)
+}
+
+// mergePatch applies JSON Merge Patch (RFC 7386) to doc
+func mergePatch(doc interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	docObject, ok := doc.(map[string]interface{})
+	if !ok {
+		docObject = make(map[string]interface{})
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(docObject, key)
+			continue
+		}
+		docObject[key] = mergePatch(docObject[key], value)
+	}
+	return docObject
+}
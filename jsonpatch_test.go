@@ -0,0 +1,125 @@
+package cucumber
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type patchUser struct {
+	Name  string   `json:"name"`
+	Email string   `json:"email,omitempty"`
+	Age   int      `json:"age"`
+	Tags  []string `json:"tags"`
+}
+
+func patchRequest(method, contentType, body string) *Context {
+	c, _ := createTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest(method, "/users/1", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", contentType)
+	return c
+}
+
+func TestContextJSONPatch(t *testing.T) {
+	user := patchUser{Name: "cucumber", Email: "cucumber@example.com", Age: 3, Tags: []string{"green"}}
+
+	c := patchRequest("PATCH", MIMEJSONPatch, `[
+		{"op": "replace", "path": "/name", "value": "pickle"},
+		{"op": "remove", "path": "/email"},
+		{"op": "add", "path": "/tags/-", "value": "sour"},
+		{"op": "test", "path": "/age", "value": 3}
+	]`)
+	assert.NoError(t, c.JSONPatch(&user))
+	assert.Equal(t, patchUser{Name: "pickle", Age: 3, Tags: []string{"green", "sour"}}, user)
+
+	// unknown path
+	c = patchRequest("PATCH", MIMEJSONPatch, `[{"op": "replace", "path": "/address", "value": "garden"}]`)
+	assert.EqualError(t, c.JSONPatch(&user), `json patch: path "/address" does not exist`)
+
+	// type mismatch
+	c = patchRequest("PATCH", MIMEJSONPatch, `[{"op": "replace", "path": "/age", "value": "old"}]`)
+	assert.Error(t, c.JSONPatch(&user))
+	assert.Equal(t, 3, user.Age)
+
+	// failed test
+	c = patchRequest("PATCH", MIMEJSONPatch, `[{"op": "test", "path": "/name", "value": "cucumber"}]`)
+	assert.Error(t, c.JSONPatch(&user))
+
+	c = patchRequest("PUT", MIMEJSONPatch, `[]`)
+	assert.Equal(t, ErrPatchMethod, c.JSONPatch(&user))
+
+	c = patchRequest("PATCH", "application/json", `[]`)
+	assert.Equal(t, ErrUnsupportedMediaType, c.JSONPatch(&user))
+}
+
+func TestContextJSONMergePatch(t *testing.T) {
+	user := patchUser{Name: "cucumber", Email: "cucumber@example.com", Age: 3, Tags: []string{"green"}}
+
+	c := patchRequest("PATCH", MIMEMergePatch, `{"name": "pickle", "email": null, "tags": ["sour"]}`)
+	assert.NoError(t, c.JSONMergePatch(&user))
+	assert.Equal(t, patchUser{Name: "pickle", Age: 3, Tags: []string{"sour"}}, user)
+
+	c = patchRequest("POST", MIMEMergePatch, `{}`)
+	assert.Equal(t, ErrPatchMethod, c.JSONMergePatch(&user))
+}
+
+type patchAccount struct {
+	patchUser
+	ID           int64     `json:"-"`
+	PasswordHash string    `json:"-"`
+	Balance      float64   `json:"balance"`
+	Created      time.Time `json:"created"`
+	Owner        *patchOwner
+	internal     string
+}
+
+type patchOwner struct {
+	Name  string `json:"name"`
+	Token string `json:"-"`
+}
+
+func TestContextJSONPatchKeepsHiddenFields(t *testing.T) {
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	owner := &patchOwner{Name: "farmer", Token: "t0ken"}
+	account := patchAccount{
+		patchUser:    patchUser{Name: "cucumber", Age: 3},
+		ID:           42,
+		PasswordHash: "$2a$10$hash",
+		Balance:      3,
+		Created:      created,
+		Owner:        owner,
+		internal:     "kept",
+	}
+
+	c := patchRequest("PATCH", MIMEJSONPatch, `[
+		{"op": "test", "path": "/balance", "value": 3.0},
+		{"op": "replace", "path": "/name", "value": "pickle"},
+		{"op": "replace", "path": "/Owner/name", "value": "gardener"},
+		{"op": "replace", "path": "/created", "value": "2021-01-02T03:04:05Z"}
+	]`)
+	assert.NoError(t, c.JSONPatch(&account))
+	assert.Equal(t, "pickle", account.Name)
+	assert.Equal(t, int64(42), account.ID)
+	assert.Equal(t, "$2a$10$hash", account.PasswordHash)
+	assert.Equal(t, "kept", account.internal)
+	assert.Equal(t, created.AddDate(1, 0, 0), account.Created)
+	assert.Equal(t, patchOwner{Name: "gardener", Token: "t0ken"}, *account.Owner)
+	// pointed struct is not modified in place
+	assert.Equal(t, "farmer", owner.Name)
+
+	c = patchRequest("PATCH", MIMEMergePatch, `{"age": null, "balance": 10}`)
+	assert.NoError(t, c.JSONMergePatch(&account))
+	assert.Equal(t, 0, account.Age)
+	assert.Equal(t, float64(10), account.Balance)
+	assert.Equal(t, int64(42), account.ID)
+
+	// numbers are compared by value
+	c = patchRequest("PATCH", MIMEJSONPatch, `[{"op": "test", "path": "/balance", "value": 1e1}]`)
+	assert.NoError(t, c.JSONPatch(&account))
+	c = patchRequest("PATCH", MIMEJSONPatch, `[{"op": "test", "path": "/balance", "value": "10"}]`)
+	assert.Error(t, c.JSONPatch(&account))
+}
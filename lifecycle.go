@@ -0,0 +1,91 @@
+package cucumber
+
+import (
+	"context"
+	"fmt"
+)
+
+// startServices starts registered Starter services in registration order,
+// it runs only once and stops on the first error
+//
+// When a service fails to start, services started before it are stopped
+// in reverse registration order.
+func (a *App) startServices(ctx context.Context) error {
+	a.startOnce.Do(func() {
+		ctx, cancel := context.WithCancel(ctx)
+		a.mu.Lock()
+		a.servicesCancel = cancel
+		a.mu.Unlock()
+
+		for i, s := range a.starters {
+			if err := s.Start(ctx); err != nil {
+				a.startErr = fmt.Errorf("start %T: %w", s, err)
+				a.stopOnce.Do(func() {
+					stopCtx := context.Background()
+					if a.ShutdownDrainTimeout > 0 {
+						var cancel context.CancelFunc
+						stopCtx, cancel = context.WithTimeout(stopCtx, a.ShutdownDrainTimeout)
+						defer cancel()
+					}
+					if a.stopErr = a.stopStarted(stopCtx, i); a.stopErr != nil {
+						a.Logger.Error(a.stopErr.Error())
+					}
+				})
+				return
+			}
+		}
+	})
+	return a.startErr
+}
+
+// stopServices stops registered Stopper services in reverse registration
+// order, it runs only once and returns the first error
+func (a *App) stopServices(ctx context.Context) error {
+	a.stopOnce.Do(func() {
+		a.stopErr = a.stopStarted(ctx, len(a.starters))
+	})
+	return a.stopErr
+}
+
+// stopStarted cancels services context and stops Stopper services in reverse
+// registration order, skipping Starter services past the first started ones
+func (a *App) stopStarted(ctx context.Context, started int) error {
+	a.mu.Lock()
+	cancel := a.servicesCancel
+	a.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+
+	// services implementing both interfaces are in the same order in both lists
+	startedStoppers := 0
+	for _, s := range a.starters[:started] {
+		if _, ok := s.(Stopper); ok {
+			startedStoppers++
+		}
+	}
+	stoppers := make([]Stopper, 0, len(a.stoppers))
+	for _, s := range a.stoppers {
+		if _, ok := s.(Starter); ok {
+			if startedStoppers == 0 {
+				continue
+			}
+			startedStoppers--
+		}
+		stoppers = append(stoppers, s)
+	}
+
+	var stopErr error
+	for i := len(stoppers) - 1; i >= 0; i-- {
+		s := stoppers[i]
+		if err := s.Stop(ctx); err != nil {
+			err = fmt.Errorf("stop %T: %w", s, err)
+			if stopErr == nil {
+				stopErr = err
+			} else {
+				a.Logger.Error(err.Error())
+			}
+		}
+	}
+	return stopErr
+}
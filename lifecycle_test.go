@@ -0,0 +1,91 @@
+package cucumber
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type lifecycleService struct {
+	name     string
+	events   *[]string
+	startErr error
+	ctx      context.Context
+	started  chan struct{}
+}
+
+func (s *lifecycleService) Start(ctx context.Context) error {
+	s.ctx = ctx
+	*s.events = append(*s.events, "start "+s.name)
+	if s.started != nil {
+		close(s.started)
+	}
+	return s.startErr
+}
+
+func (s *lifecycleService) Stop(ctx context.Context) error {
+	*s.events = append(*s.events, "stop "+s.name)
+	return nil
+}
+
+func TestAppServiceLifecycle(t *testing.T) {
+	events := []string{}
+	consumer := &lifecycleService{name: "consumer", events: &events, started: make(chan struct{})}
+	pool := &lifecycleService{name: "pool", events: &events}
+
+	app := newTestAppInstance()
+	app.HTTPAddr = "127.0.0.1:0"
+	app.Register(pool)
+	app.Register(consumer)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- app.StartWithContext(ctx)
+	}()
+
+	<-consumer.started
+	assert.Equal(t, []string{"start pool", "start consumer"}, events)
+	assert.NoError(t, consumer.ctx.Err())
+
+	cancel()
+	select {
+	case err := <-errCh:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("StartWithContext did not return")
+	}
+
+	assert.Equal(t, []string{"start pool", "start consumer", "stop consumer", "stop pool"}, events)
+	assert.Error(t, consumer.ctx.Err())
+}
+
+func TestAppServiceStartError(t *testing.T) {
+	events := []string{}
+	app := newTestAppInstance()
+	app.Register(&lifecycleService{name: "consumer", events: &events, startErr: errors.New("broker unavailable")})
+	app.Register(&lifecycleService{name: "pool", events: &events})
+
+	err := app.StartWithContext(context.Background())
+	assert.EqualError(t, err, "start *cucumber.lifecycleService: broker unavailable")
+	assert.Equal(t, []string{"start consumer"}, events)
+}
+
+func TestAppServiceStartErrorStopsStarted(t *testing.T) {
+	events := []string{}
+	app := newTestAppInstance()
+	app.Register(&lifecycleService{name: "pool", events: &events})
+	app.Register(&lifecycleService{name: "consumer", events: &events, startErr: errors.New("broker unavailable")})
+	app.Register(&lifecycleService{name: "cache", events: &events})
+
+	err := app.StartWithContext(context.Background())
+	assert.EqualError(t, err, "start *cucumber.lifecycleService: broker unavailable")
+	assert.Equal(t, []string{"start pool", "start consumer", "stop pool"}, events)
+
+	// services are not stopped again
+	assert.NoError(t, app.stop())
+	assert.Equal(t, []string{"start pool", "start consumer", "stop pool"}, events)
+}
@@ -0,0 +1,169 @@
+package cucumber
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LivenessGuard stops application when it looks deadlocked, so it can be
+// restarted by process supervisor
+//
+// Every interval once application is started, the guard checks:
+//
+//   - number of goroutines, not counting goroutines serving HTTP connections,
+//     does not exceed maxGoroutines (zero disables the check)
+//   - heartbeat sent on the previous check was answered by HTTP request
+//     handling, which answers it when a request completes; unanswered
+//     heartbeat fails only while a request is in flight for longer than
+//     Options.LivenessRequestDeadline
+//
+// Either failure is logged and App.Stop is called.
+//
+// Guard can report healthy process as deadlocked, when the request deadline
+// is shorter than legitimate long requests (uploads, SSE, reports) served with
+// no other traffic, or when handlers, HTTP/2 streams or gRPC calls run more
+// goroutines than maxGoroutines allows.
+func (a *App) LivenessGuard(interval time.Duration, maxGoroutines int) *App {
+	if interval <= 0 {
+		panic("liveness guard interval has to be positive")
+	}
+	requestDeadline := a.LivenessRequestDeadline
+	if requestDeadline <= 0 {
+		requestDeadline = 2 * interval
+	}
+	a.liveness = &livenessGuard{
+		app:             a,
+		interval:        interval,
+		requestDeadline: requestDeadline,
+		maxGoroutines:   maxGoroutines,
+		inFlight:        make(map[uint64]time.Time),
+		heartbeat:       make(chan struct{}, 1),
+		done:            make(chan struct{}),
+	}
+	return a.Register(a.liveness)
+}
+
+type livenessGuard struct {
+	app             *App
+	interval        time.Duration
+	requestDeadline time.Duration
+	maxGoroutines   int
+
+	// conns is the number of open HTTP connections
+	conns int64
+
+	mu sync.Mutex
+	// inFlight holds start time of HTTP requests being handled
+	inFlight map[uint64]time.Time
+	nextID   uint64
+
+	// heartbeat holds ping sent by check until a request handler answers it
+	heartbeat chan struct{}
+
+	done chan struct{}
+	once sync.Once
+}
+
+// Start runs liveness checks every interval until ctx is done or guard is stopped
+func (g *livenessGuard) Start(ctx context.Context) error {
+	go func() {
+		ticker := time.NewTicker(g.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := g.check(time.Now()); err != nil {
+					g.app.Logger.Error(fmt.Sprintf("Liveness check failed: %s, stopping application", err))
+					if err := g.app.Stop(); err != nil {
+						g.app.Logger.Error(err.Error())
+					}
+					return
+				}
+			case <-ctx.Done():
+				return
+			case <-g.done:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops liveness checks
+func (g *livenessGuard) Stop(ctx context.Context) error {
+	g.once.Do(func() { close(g.done) })
+	return nil
+}
+
+// check returns error when application looks deadlocked
+func (g *livenessGuard) check(now time.Time) error {
+	conns := int(atomic.LoadInt64(&g.conns))
+	if n := runtime.NumGoroutine() - conns; g.maxGoroutines > 0 && n > g.maxGoroutines {
+		return fmt.Errorf("%d goroutines exceed limit of %d", n, g.maxGoroutines)
+	}
+
+	select {
+	case <-g.heartbeat:
+		// previous heartbeat was not answered
+		if err := g.checkInFlight(now); err != nil {
+			return err
+		}
+	default:
+	}
+
+	select {
+	case g.heartbeat <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// checkInFlight returns error when some request is in flight past deadline
+func (g *livenessGuard) checkInFlight(now time.Time) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, start := range g.inFlight {
+		if age := now.Sub(start); age > g.requestDeadline {
+			return fmt.Errorf("HTTP request is in flight for %s and heartbeat was not answered within %s",
+				age.Round(time.Millisecond), g.interval)
+		}
+	}
+	return nil
+}
+
+// enter records request being handled and returns its id
+func (g *livenessGuard) enter() uint64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.nextID++
+	g.inFlight[g.nextID] = time.Now()
+	return g.nextID
+}
+
+// leave records completed request and answers pending heartbeat
+func (g *livenessGuard) leave(id uint64) {
+	g.mu.Lock()
+	delete(g.inFlight, id)
+	g.mu.Unlock()
+
+	select {
+	case <-g.heartbeat:
+	default:
+	}
+}
+
+// connState counts open HTTP connections, each served by its own goroutine
+func (g *livenessGuard) connState(conn net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		atomic.AddInt64(&g.conns, 1)
+	case http.StateHijacked, http.StateClosed:
+		atomic.AddInt64(&g.conns, -1)
+	}
+}
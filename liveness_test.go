@@ -0,0 +1,125 @@
+package cucumber
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// startLivenessTestApp starts app and returns channel receiving StartWithContext result
+func startLivenessTestApp(app *App) (<-chan error, context.CancelFunc) {
+	app.HTTPAddr = "127.0.0.1:0"
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- app.StartWithContext(ctx)
+	}()
+	return errCh, cancel
+}
+
+func TestLivenessGuardGoroutineLeak(t *testing.T) {
+	app := newTestAppInstance()
+	app.LivenessGuard(10*time.Millisecond, runtime.NumGoroutine()+50)
+
+	errCh, cancel := startLivenessTestApp(app)
+	defer cancel()
+
+	// healthy application keeps running
+	select {
+	case <-errCh:
+		t.Fatal("application stopped without goroutine leak")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	leak := make(chan struct{})
+	defer close(leak)
+	for i := 0; i < 100; i++ {
+		go func() { <-leak }()
+	}
+
+	select {
+	case err := <-errCh:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("application did not stop on goroutine leak")
+	}
+}
+
+func TestLivenessGuardStuckRequest(t *testing.T) {
+	app := newTestAppInstance()
+	app.LivenessRequestDeadline = 200 * time.Millisecond
+	app.LivenessGuard(20*time.Millisecond, 0)
+
+	deadlock := make(chan struct{})
+	defer close(deadlock)
+	app.GET("/slow", func(c *Context) {
+		time.Sleep(100 * time.Millisecond)
+		c.Status(http.StatusOK)
+	})
+	app.GET("/stuck", func(c *Context) { <-deadlock })
+
+	errCh, cancel := startLivenessTestApp(app)
+	defer cancel()
+
+	// single request longer than check interval, with no other traffic
+	w := performRequest(app, http.MethodGet, "/slow")
+	assert.Equal(t, http.StatusOK, w.Code)
+	select {
+	case <-errCh:
+		t.Fatal("application stopped while serving slow request")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	go app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stuck", nil))
+
+	select {
+	case err := <-errCh:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("application did not stop on deadlocked handler")
+	}
+}
+
+func TestLivenessGuardCheck(t *testing.T) {
+	g := &livenessGuard{
+		interval:        time.Second,
+		requestDeadline: time.Minute,
+		maxGoroutines:   runtime.NumGoroutine() + 10,
+		inFlight:        make(map[uint64]time.Time),
+		heartbeat:       make(chan struct{}, 1),
+	}
+
+	// goroutines serving HTTP connections are not counted
+	release := make(chan struct{})
+	defer close(release)
+	for i := 0; i < 50; i++ {
+		go func() { <-release }()
+	}
+	assert.Error(t, g.check(time.Now()))
+	g.conns = 50
+	assert.NoError(t, g.check(time.Now()))
+
+	// unanswered heartbeat fails once request is in flight longer than deadline
+	id := g.enter()
+	assert.NoError(t, g.check(time.Now().Add(30*time.Second)))
+	assert.Error(t, g.check(time.Now().Add(2*time.Minute)))
+
+	// completed request answers the heartbeat
+	assert.NoError(t, g.check(time.Now().Add(2*time.Minute)))
+	g.leave(g.enter())
+	assert.NoError(t, g.check(time.Now().Add(2*time.Minute)))
+	g.leave(id)
+	assert.NoError(t, g.check(time.Now().Add(2*time.Minute)))
+
+	// request deadline defaults to twice the interval
+	app := newTestAppInstance()
+	app.LivenessGuard(time.Second, 0)
+	assert.Equal(t, 2*time.Second, app.liveness.requestDeadline)
+
+	assert.Panics(t, func() { newTestAppInstance().LivenessGuard(0, 0) })
+}
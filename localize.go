@@ -0,0 +1,75 @@
+package cucumber
+
+import (
+	"errors"
+	"net/http"
+)
+
+// LanguageKey is the context key under which Localize stores negotiated language
+const LanguageKey = "language"
+
+// ErrUnsupportedLanguage is served with 406 by strict Localize middleware
+var ErrUnsupportedLanguage = errors.New("unsupported language")
+
+// Localize returns a middleware that negotiates request language with app
+// Translator and stores its tag under LanguageKey, see Context.Language
+//
+// Languages are taken from Translator.LanguageExtractors in order, Accept-Language
+// header honors quality values and region tags fall back to base language.
+// Translator.DefaultLanguage is used when no requested language is supported.
+//
+// In strict mode request is rejected with 406 Not Acceptable when language
+// explicitly requested by query string, e.g. ?lang=xx, has no translations.
+func Localize() HandlerFunc {
+	return func(c *Context) {
+		translator := c.Translator()
+		if translator == nil {
+			c.Next()
+			return
+		}
+
+		if translator.Strict {
+			if name, _ := translator.LanguageExtractorOptions["QueryStringName"].(string); name != "" {
+				if lang := c.Query(name); lang != "" && translator.SupportedLanguage(lang) == "" {
+					c.Abort()
+					c.ServeError(http.StatusNotAcceptable, ErrUnsupportedLanguage)
+					return
+				}
+			}
+		}
+
+		lang := translator.SupportedLanguage(translator.ExtractLanguage(c)...)
+		if lang == "" {
+			lang = translator.DefaultLanguage
+		}
+		c.Set(LanguageKey, lang)
+		c.Next()
+	}
+}
+
+// Language returns language negotiated by Localize middleware,
+// app default language is returned when middleware is not used
+func (c *Context) Language() string {
+	if lang := c.GetString(LanguageKey); lang != "" {
+		return lang
+	}
+	if translator := c.Translator(); translator != nil {
+		return translator.DefaultLanguage
+	}
+	return c.app.TranslatorDefaultLang
+}
+
+// Translator returns app translator, nil when translator is disabled
+func (c *Context) Translator() *Translator {
+	return c.app.Options.Translator
+}
+
+// T translates translationID into request language, see Context.Language
+// and Translator.Translate. translationID is returned when translator is disabled.
+func (c *Context) T(translationID string, args ...interface{}) string {
+	translator := c.Translator()
+	if translator == nil {
+		return translationID
+	}
+	return translator.Translate(c.Language(), translationID, args...)
+}
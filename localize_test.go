@@ -0,0 +1,99 @@
+package cucumber
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AjdinHalac/cucumber/i18n/language"
+	"github.com/AjdinHalac/cucumber/i18n/translation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tt := []struct {
+		Header string
+		Langs  []string
+	}{
+		{"de", []string{"de"}},
+		{"fr;q=0.5, de-AT, en;q=0.8", []string{"de-AT", "de", "en", "fr"}},
+		{"en-GB,en;q=0.9", []string{"en-GB", "en"}},
+		{"*, fr;q=0.1", []string{"fr"}},
+		{"de;q=0, fr;q=abc, ../etc;q=1, , it", []string{"it"}},
+	}
+
+	for _, tc := range tt {
+		assert.Equal(t, tc.Langs, parseAcceptLanguage(tc.Header), tc.Header)
+	}
+}
+
+func newLocalizedApp(t *testing.T, strict bool) *App {
+	app := newTestAppInstance()
+	tr := newTestTranslator(t, map[string]string{"hello": "Hello"})
+	tt, _ := translation.NewTranslation(map[string]interface{}{"id": "hello", "translation": "Hallo"})
+	tr.AddTranslation(language.MustParse("de")[0], tt)
+	tr.Strict = strict
+	app.Options.Translator = tr
+
+	app.Use(Localize())
+	app.GET("/", func(c *Context) {
+		c.String(http.StatusOK, c.Language())
+	})
+	return app
+}
+
+func TestLocalize(t *testing.T) {
+	tt := []struct {
+		Name   string
+		URL    string
+		Header string
+		Strict bool
+		Code   int
+		Lang   string
+	}{
+		{Name: "region fallback", URL: "/", Header: "de-AT", Code: http.StatusOK, Lang: "de"},
+		{Name: "quality", URL: "/", Header: "de;q=0.5, en-US", Code: http.StatusOK, Lang: "en-us"},
+		{Name: "default", URL: "/", Header: "*, fr", Code: http.StatusOK, Lang: "en-us"},
+		{Name: "query", URL: "/?lang=de", Header: "en-US", Code: http.StatusOK, Lang: "de"},
+		{Name: "lenient query", URL: "/?lang=fr", Header: "de", Code: http.StatusOK, Lang: "de"},
+		{Name: "strict query", URL: "/?lang=fr", Header: "de", Strict: true, Code: http.StatusNotAcceptable},
+		{Name: "strict region query", URL: "/?lang=de-CH", Strict: true, Code: http.StatusOK, Lang: "de"},
+	}
+
+	for _, tc := range tt {
+		app := newLocalizedApp(t, tc.Strict)
+		req := httptest.NewRequest(http.MethodGet, tc.URL, nil)
+		req.Header.Set("Accept-Language", tc.Header)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+
+		assert.Equal(t, tc.Code, w.Code, tc.Name)
+		if tc.Code == http.StatusOK {
+			assert.Equal(t, tc.Lang, w.Body.String(), tc.Name)
+		}
+	}
+}
+
+func TestContextTranslator(t *testing.T) {
+	app := newLocalizedApp(t, false)
+	app.GET("/hello", func(c *Context) {
+		assert.Same(t, app.Translator(), c.Translator())
+		assert.Contains(t, c.Translator().AvailableLanguages(), c.Language())
+		c.String(http.StatusOK, c.T("hello"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/hello", nil)
+	req.Header.Set("Accept-Language", "de-DE")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	assert.Equal(t, "Hallo", w.Body.String())
+
+	disabled := newTestAppInstance()
+	assert.Nil(t, disabled.Translator())
+	disabled.GET("/hello", func(c *Context) {
+		assert.Nil(t, c.Translator())
+		c.String(http.StatusOK, c.T("hello"))
+	})
+	w = performRequest(disabled, http.MethodGet, "/hello")
+	assert.Equal(t, "hello", w.Body.String())
+}
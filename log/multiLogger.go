@@ -0,0 +1,107 @@
+package log
+
+type multiLogger struct {
+	backends []Logger
+}
+
+// NewMultiLogger creates Logger which fans out every entry to all backends
+//
+// Backends are called sequentially in given order. Fatal entries reach
+// backends only until the first of them terminates the process.
+func NewMultiLogger(backends ...Logger) Logger {
+	return &multiLogger{backends: backends}
+}
+
+func (l *multiLogger) Debug(args ...interface{}) {
+	for _, b := range l.backends {
+		b.Debug(args...)
+	}
+}
+
+func (l *multiLogger) Debugf(format string, args ...interface{}) {
+	for _, b := range l.backends {
+		b.Debugf(format, args...)
+	}
+}
+
+func (l *multiLogger) Info(args ...interface{}) {
+	for _, b := range l.backends {
+		b.Info(args...)
+	}
+}
+
+func (l *multiLogger) Infof(format string, args ...interface{}) {
+	for _, b := range l.backends {
+		b.Infof(format, args...)
+	}
+}
+
+func (l *multiLogger) Warn(args ...interface{}) {
+	for _, b := range l.backends {
+		b.Warn(args...)
+	}
+}
+
+func (l *multiLogger) Warnf(format string, args ...interface{}) {
+	for _, b := range l.backends {
+		b.Warnf(format, args...)
+	}
+}
+
+func (l *multiLogger) Error(args ...interface{}) {
+	for _, b := range l.backends {
+		b.Error(args...)
+	}
+}
+
+func (l *multiLogger) Errorf(format string, args ...interface{}) {
+	for _, b := range l.backends {
+		b.Errorf(format, args...)
+	}
+}
+
+func (l *multiLogger) Fatal(args ...interface{}) {
+	for _, b := range l.backends {
+		b.Fatal(args...)
+	}
+}
+
+func (l *multiLogger) Fatalf(format string, args ...interface{}) {
+	for _, b := range l.backends {
+		b.Fatalf(format, args...)
+	}
+}
+
+func (l *multiLogger) Panic(args ...interface{}) {
+	l.panic(func(b Logger) { b.Panic(args...) })
+}
+
+func (l *multiLogger) Panicf(format string, args ...interface{}) {
+	l.panic(func(b Logger) { b.Panicf(format, args...) })
+}
+
+// panic delivers entry to every backend and re-panics with the first panic value
+func (l *multiLogger) panic(log func(b Logger)) {
+	var recovered interface{}
+	for _, b := range l.backends {
+		func() {
+			defer func() {
+				if r := recover(); r != nil && recovered == nil {
+					recovered = r
+				}
+			}()
+			log(b)
+		}()
+	}
+	if recovered != nil {
+		panic(recovered)
+	}
+}
+
+func (l *multiLogger) WithFields(fields Fields) Logger {
+	backends := make([]Logger, len(l.backends))
+	for i, b := range l.backends {
+		backends[i] = b.WithFields(fields)
+	}
+	return &multiLogger{backends: backends}
+}
@@ -0,0 +1,40 @@
+package cucumber
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// LongPoll calls poll until it returns data, which is written as JSON, or
+// timeout expires, when 204 No Content is written
+//
+// Poll has to block until data is available or its context is done, e.g.
+// on a channel select, as it is called again right after returning nil data.
+// Context of poll is canceled on timeout or when client disconnects, in which
+// case nothing is written and the request context error is returned. Errors
+// returned by poll before timeout are returned as well.
+func (c *Context) LongPoll(poll func(ctx context.Context) (interface{}, error), timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	for {
+		data, err := poll(ctx)
+		if data != nil && err == nil {
+			c.JSON(http.StatusOK, data)
+			return nil
+		}
+
+		// client disconnected
+		if err := c.Request.Context().Err(); err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			c.Status(http.StatusNoContent)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package cucumber
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// channelPoll returns poll function waiting for value sent on events
+func channelPoll(events <-chan map[string]string) func(ctx context.Context) (interface{}, error) {
+	return func(ctx context.Context) (interface{}, error) {
+		select {
+		case event := <-events:
+			return event, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func TestContextLongPoll(t *testing.T) {
+	events := make(chan map[string]string)
+	app := newTestAppInstance()
+	app.GET("/events", func(c *Context) {
+		assert.NoError(t, c.LongPoll(channelPoll(events), time.Second))
+	})
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		events <- map[string]string{"order": "shipped"}
+	}()
+
+	start := time.Now()
+	w := performRequest(app, http.MethodGet, "/events")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"order":"shipped"}`, w.Body.String())
+	assert.True(t, time.Since(start) >= 50*time.Millisecond)
+}
+
+func TestContextLongPollTimeout(t *testing.T) {
+	app := newTestAppInstance()
+	app.GET("/events", func(c *Context) {
+		assert.NoError(t, c.LongPoll(channelPoll(nil), 20*time.Millisecond))
+	})
+
+	w := performRequest(app, http.MethodGet, "/events")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestContextLongPollClientGone(t *testing.T) {
+	errPoll := errors.New("poll failed")
+	var pollErr, goneErr error
+	app := newTestAppInstance()
+	app.GET("/fail", func(c *Context) {
+		pollErr = c.LongPoll(func(ctx context.Context) (interface{}, error) {
+			return nil, errPoll
+		}, time.Second)
+	})
+	app.GET("/events", func(c *Context) {
+		goneErr = c.LongPoll(channelPoll(nil), time.Second)
+	})
+
+	performRequest(app, http.MethodGet, "/fail")
+	assert.ErrorIs(t, pollErr, errPoll)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	req := httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx)
+	app.ServeHTTP(httptest.NewRecorder(), req)
+	assert.ErrorIs(t, goneErr, context.Canceled)
+}
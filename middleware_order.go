@@ -0,0 +1,129 @@
+package cucumber
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// orderRulesKey is the context key of probe collecting rules of order validator
+const orderRulesKey = "middlewareOrderRules"
+
+// orderValidatorPC is the code pointer of handlers returned by MiddlewareOrderValidator,
+// which is not inlined so all its closures share the same code
+var orderValidatorPC = reflect.ValueOf(MiddlewareOrderValidator(nil)).Pointer()
+
+// OrderRule requires middleware named Before to run before middleware named After
+//
+// Names are function names of middlewares, either qualified with package
+// ("cucumber.DecompressBody") or not ("DecompressBody").
+type OrderRule struct {
+	Before string
+	After  string
+}
+
+// MiddlewareOrderValidator returns a middleware adding order rules to the router,
+// group or route it is installed on, see Router.ValidateMiddlewareOrder
+//
+// Rules are checked when the validator is registered, with Use, Group, route
+// registration or RegisterController. At request time it just calls next handler.
+//
+//	app.Use(cucumber.MiddlewareOrderValidator([]cucumber.OrderRule{
+//		{Before: "DecompressBody", After: "BindJSON"},
+//	}))
+//
+//go:noinline
+func MiddlewareOrderValidator(rules []OrderRule) HandlerFunc {
+	rules = append([]OrderRule(nil), rules...)
+	return func(c *Context) {
+		if probe, ok := c.Keys[orderRulesKey].(*[]OrderRule); ok {
+			*probe = rules
+			return
+		}
+		c.Next()
+	}
+}
+
+// ValidateMiddlewareOrder adds rules validating order of middlewares of the router,
+// its groups created afterwards and routes registered on them
+//
+// Chains are validated on registration, Use and route registration panic when
+// order rules are violated, so rules should be added before middlewares.
+//
+//	router.ValidateMiddlewareOrder(cucumber.OrderRule{Before: "DecompressBody", After: "BindJSON"})
+func (r *Router) ValidateMiddlewareOrder(rules ...OrderRule) {
+	r.orderRules = appendOrderRules(r.orderRules, rules)
+	validateMiddlewareOrder(r.Handlers, r.orderRules)
+}
+
+// appendOrderRules returns copy of rules with added rules, so groups sharing
+// rules are not affected
+func appendOrderRules(rules, added []OrderRule) []OrderRule {
+	if len(added) == 0 {
+		return rules
+	}
+	return append(rules[:len(rules):len(rules)], added...)
+}
+
+// orderRulesOf returns rules of validators returned by MiddlewareOrderValidator in handlers
+func orderRulesOf(handlers HandlersChain) []OrderRule {
+	var rules []OrderRule
+	for _, handler := range handlers {
+		if reflect.ValueOf(handler).Pointer() != orderValidatorPC {
+			continue
+		}
+		var probe []OrderRule
+		handler(&Context{Keys: map[string]interface{}{orderRulesKey: &probe}})
+		rules = append(rules, probe...)
+	}
+	return rules
+}
+
+func validateMiddlewareOrder(chain HandlersChain, rules []OrderRule) {
+	names := make([]string, len(chain))
+	for i, handler := range chain {
+		names[i] = middlewareName(nameOfFunction(handler))
+	}
+	for _, rule := range rules {
+		before, after := indexOfMiddleware(names, rule.Before), indexOfMiddleware(names, rule.After)
+		if before >= 0 && after >= 0 && after < before {
+			panic(fmt.Sprintf("%s must run before %s, got wrong order", rule.Before, rule.After))
+		}
+	}
+}
+
+// middlewareName returns function name without closure suffixes,
+// e.g. "pkg.Auth" for "github.com/org/pkg.Auth.func1"
+func middlewareName(name string) string {
+	for {
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 || !isClosureName(name[i+1:]) {
+			return name
+		}
+		name = name[:i]
+	}
+}
+
+// isClosureName reports whether name is compiler generated name of closure, e.g. "func1" or "2"
+func isClosureName(name string) bool {
+	name = strings.TrimPrefix(name, "func")
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// indexOfMiddleware returns index of the first middleware with given name, or -1
+func indexOfMiddleware(names []string, name string) int {
+	for i, n := range names {
+		if n == name || strings.HasSuffix(n, "."+name) || strings.HasSuffix(n, "/"+name) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,133 @@
+package cucumber
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func auth(c *Context) {
+	c.Next()
+}
+
+func authorization(c *Context) {
+	c.Next()
+}
+
+var authOrderRule = OrderRule{Before: "auth", After: "authorization"}
+
+func TestValidateMiddlewareOrder(t *testing.T) {
+	app := newTestAppInstance()
+	app.ValidateMiddlewareOrder(authOrderRule)
+	app.Use(auth, authorization)
+	app.GET("/", func(c *Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	w := performRequest(app, http.MethodGet, "/")
+	assert.Equal(t, "ok", w.Body.String())
+
+	app = newTestAppInstance()
+	app.ValidateMiddlewareOrder(authOrderRule)
+	assert.PanicsWithValue(t, "auth must run before authorization, got wrong order", func() {
+		app.Use(authorization, auth)
+	})
+
+	// installed chain is validated when rules are added
+	app = newTestAppInstance()
+	app.Use(authorization, auth)
+	assert.PanicsWithValue(t, "auth must run before authorization, got wrong order", func() {
+		app.ValidateMiddlewareOrder(authOrderRule)
+	})
+}
+
+func TestValidateMiddlewareOrderRoutes(t *testing.T) {
+	app := newTestAppInstance()
+	app.ValidateMiddlewareOrder(OrderRule{Before: "cucumber.DecompressBody", After: "authorization"})
+	app.Use(authorization)
+
+	assert.PanicsWithValue(t, "cucumber.DecompressBody must run before authorization, got wrong order", func() {
+		app.GET("/upload", DecompressBody(), func(c *Context) {})
+	})
+
+	// group rules do not apply to parent router
+	group := app.Router().Group("/admin")
+	group.ValidateMiddlewareOrder(authOrderRule)
+	assert.Panics(t, func() {
+		group.GET("/", auth, func(c *Context) {})
+	})
+	app.Router().Group("/public").GET("/", auth, func(c *Context) {})
+
+	// controller routes are validated when attached
+	ctrl := NewRouter()
+	ctrl.GET("/", DecompressBody(), func(c *Context) {})
+	assert.Panics(t, func() {
+		app.Attach("/orders", ctrl)
+	})
+}
+
+func TestMiddlewareName(t *testing.T) {
+	assert.Equal(t, "github.com/org/pkg.Auth", middlewareName("github.com/org/pkg.Auth.func1"))
+	assert.Equal(t, "github.com/org/pkg.Auth", middlewareName("github.com/org/pkg.Auth.func1.2"))
+	assert.Equal(t, "pkg.funcLogger", middlewareName("pkg.funcLogger"))
+	assert.Equal(t, 1, indexOfMiddleware([]string{"pkg.Auth", "pkg.Authorization"}, "Authorization"))
+	assert.Equal(t, -1, indexOfMiddleware([]string{"pkg.Auth"}, "uth"))
+}
+
+// authController registers order validator on its own router
+type authController struct{}
+
+func (ctrl *authController) Prefix() string {
+	return "/accounts"
+}
+
+func (ctrl *authController) Routes() *Router {
+	r := NewRouter()
+	r.Use(MiddlewareOrderValidator([]OrderRule{authOrderRule}))
+	r.GET("/", authorization, func(c *Context) {})
+	return r
+}
+
+func TestMiddlewareOrderValidator(t *testing.T) {
+	app := newTestAppInstance()
+	app.Use(MiddlewareOrderValidator([]OrderRule{authOrderRule}))
+	app.Use(auth, authorization)
+	app.GET("/", func(c *Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	// validator is transparent at request time
+	w := performRequest(app, http.MethodGet, "/")
+	assert.Equal(t, "ok", w.Body.String())
+
+	app = newTestAppInstance()
+	app.Use(MiddlewareOrderValidator([]OrderRule{authOrderRule}))
+	assert.PanicsWithValue(t, "auth must run before authorization, got wrong order", func() {
+		app.Use(authorization, auth)
+	})
+
+	// validator installed on group and route
+	app = newTestAppInstance()
+	app.Use(authorization)
+	assert.Panics(t, func() {
+		app.Router().Group("/admin", MiddlewareOrderValidator([]OrderRule{authOrderRule}), auth)
+	})
+	assert.Panics(t, func() {
+		app.GET("/admin", MiddlewareOrderValidator([]OrderRule{authOrderRule}), auth, func(c *Context) {})
+	})
+	app.GET("/public", auth, func(c *Context) {})
+
+	// controller rules are checked against application middlewares on registration
+	app = newTestAppInstance()
+	app.ControllerPackage = "cucumber"
+	app.Use(auth)
+	app.RegisterController(&authController{})
+
+	app = newTestAppInstance()
+	app.ControllerPackage = "cucumber"
+	app.Use(authorization, auth)
+	assert.PanicsWithValue(t, "auth must run before authorization, got wrong order", func() {
+		app.RegisterController(&authController{})
+	})
+}
@@ -0,0 +1,49 @@
+package cucumber
+
+// NamespacedContext stores context values under package namespace
+// to avoid key collisions between packages
+//
+// Values are kept in context Keys as "pkg:key"
+type NamespacedContext struct {
+	c   *Context
+	pkg string
+}
+
+// NS returns context namespace for given package
+//
+//	c.NS("auth").Set("userID", id)
+func (c *Context) NS(pkg string) NamespacedContext {
+	return NamespacedContext{c: c, pkg: pkg}
+}
+
+// NSMust returns value for given namespaced key ("pkg:key") if it exists, otherwise it panics.
+func (c *Context) NSMust(key string) interface{} {
+	return c.MustGet(key)
+}
+
+// Key returns key under which value is stored in context
+func (ns NamespacedContext) Key(key string) string {
+	return ns.pkg + ":" + key
+}
+
+// Set stores value for given key in namespace
+func (ns NamespacedContext) Set(key string, value interface{}) {
+	ns.c.Set(ns.Key(key), value)
+}
+
+// Get returns value for given key in namespace or nil if it does not exist
+func (ns NamespacedContext) Get(key string) interface{} {
+	value, _ := ns.c.Get(ns.Key(key))
+	return value
+}
+
+// Lookup returns value for given key in namespace, ie: (value, true).
+// If the value does not exists it returns (nil, false)
+func (ns NamespacedContext) Lookup(key string) (interface{}, bool) {
+	return ns.c.Get(ns.Key(key))
+}
+
+// Must returns value for given key in namespace if it exists, otherwise it panics.
+func (ns NamespacedContext) Must(key string) interface{} {
+	return ns.c.NSMust(ns.Key(key))
+}
@@ -0,0 +1,223 @@
+package cucumber
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultOPACacheTTL  = 5 * time.Second
+	defaultOPACacheSize = 1000
+	defaultOPATimeout   = 2 * time.Second
+)
+
+// ErrOPADenied is served when OPA policy denies the request
+var ErrOPADenied = errors.New("request denied by policy")
+
+// OPAConfig configures OPAMiddleware
+type OPAConfig struct {
+	// OPAEndpoint is the base URL of OPA server, e.g. http://localhost:8181
+	OPAEndpoint string
+	// PolicyPath is the path of policy document, e.g. httpapi/authz
+	PolicyPath string
+	// InputBuilder builds policy input from request, method, path
+	// and query of request are used when nil
+	InputBuilder func(*Context) map[string]interface{}
+	// DenyResponse writes response for denied request, 403 is served when nil
+	DenyResponse func(*Context, map[string]interface{})
+	// CacheTTL is the time policy result is cached per input, defaults to 5s
+	CacheTTL time.Duration
+	// CacheSize is the maximum number of cached results, least recently
+	// used result is evicted when full, defaults to 1000
+	CacheSize int
+	// Client used to reach OPA server, client with 2s timeout is used when nil
+	Client *http.Client
+}
+
+type opaCacheEntry struct {
+	key     string
+	result  map[string]interface{}
+	expires time.Time
+}
+
+// opaEvaluator evaluates policy on OPA server and caches the results
+type opaEvaluator struct {
+	url    string
+	client *http.Client
+	ttl    time.Duration
+	size   int
+
+	mu sync.Mutex
+	// lru holds *opaCacheEntry, most recently used first
+	lru   *list.List
+	cache map[string]*list.Element
+}
+
+// OPAMiddleware returns a middleware which evaluates Open Policy Agent policy for
+// every request and aborts request unless policy result has "allow" set to true
+//
+// Request is aborted with 502 Bad Gateway when OPA server can not be reached.
+func OPAMiddleware(cfg OPAConfig) HandlerFunc {
+	if cfg.OPAEndpoint == "" || cfg.PolicyPath == "" {
+		panic("OPA endpoint and policy path must be set")
+	}
+	if cfg.InputBuilder == nil {
+		cfg.InputBuilder = defaultOPAInput
+	}
+	if cfg.DenyResponse == nil {
+		cfg.DenyResponse = func(c *Context, result map[string]interface{}) {
+			c.ServeError(http.StatusForbidden, ErrOPADenied)
+		}
+	}
+	if cfg.CacheTTL == 0 {
+		cfg.CacheTTL = defaultOPACacheTTL
+	}
+	if cfg.CacheSize == 0 {
+		cfg.CacheSize = defaultOPACacheSize
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: defaultOPATimeout}
+	}
+
+	e := &opaEvaluator{
+		url:    strings.TrimRight(cfg.OPAEndpoint, "/") + "/v1/data/" + strings.Trim(cfg.PolicyPath, "/"),
+		client: cfg.Client,
+		ttl:    cfg.CacheTTL,
+		size:   cfg.CacheSize,
+		lru:    list.New(),
+		cache:  make(map[string]*list.Element),
+	}
+
+	return func(c *Context) {
+		result, err := e.evaluate(c, cfg.InputBuilder(c))
+		if err != nil {
+			c.Abort()
+			c.ServeError(http.StatusBadGateway, err)
+			return
+		}
+		if allow, _ := result["allow"].(bool); !allow {
+			c.Abort()
+			cfg.DenyResponse(c, result)
+			return
+		}
+		c.Next()
+	}
+}
+
+func defaultOPAInput(c *Context) map[string]interface{} {
+	return map[string]interface{}{
+		"method": c.Request.Method,
+		"path":   c.Request.URL.Path,
+		"query":  c.Request.URL.Query(),
+	}
+}
+
+func (e *opaEvaluator) evaluate(c *Context, input map[string]interface{}) (map[string]interface{}, error) {
+	body, err := json.Marshal(map[string]interface{}{"input": input})
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(body)
+	key := hex.EncodeToString(sum[:])
+	if result, ok := e.cached(key); ok {
+		return result, nil
+	}
+
+	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(ContentTypeHeader, "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OPA server responded with status %d", resp.StatusCode)
+	}
+
+	var decoded struct {
+		Result map[string]interface{} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, err
+	}
+
+	e.store(key, copyOPAResult(decoded.Result))
+	return decoded.Result, nil
+}
+
+func (e *opaEvaluator) cached(key string) (map[string]interface{}, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	el, ok := e.cache[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*opaCacheEntry)
+	if time.Now().After(entry.expires) {
+		e.lru.Remove(el)
+		delete(e.cache, key)
+		return nil, false
+	}
+	e.lru.MoveToFront(el)
+	// handlers get a copy so they can not modify cached result
+	return copyOPAResult(entry.result), true
+}
+
+func (e *opaEvaluator) store(key string, result map[string]interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	entry := &opaCacheEntry{key: key, result: result, expires: time.Now().Add(e.ttl)}
+	if el, ok := e.cache[key]; ok {
+		el.Value = entry
+		e.lru.MoveToFront(el)
+		return
+	}
+	e.cache[key] = e.lru.PushFront(entry)
+	if e.lru.Len() > e.size {
+		oldest := e.lru.Back()
+		e.lru.Remove(oldest)
+		delete(e.cache, oldest.Value.(*opaCacheEntry).key)
+	}
+}
+
+// copyOPAResult deep copies decoded JSON result
+func copyOPAResult(result map[string]interface{}) map[string]interface{} {
+	if result == nil {
+		return nil
+	}
+	return copyJSONValue(result).(map[string]interface{})
+}
+
+func copyJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			m[k] = copyJSONValue(item)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, item := range v {
+			s[i] = copyJSONValue(item)
+		}
+		return s
+	default:
+		return v
+	}
+}
@@ -0,0 +1,130 @@
+package cucumber
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newOPAServer(t *testing.T, calls *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		assert.Equal(t, "/v1/data/httpapi/authz", r.URL.Path)
+
+		var body struct {
+			Input map[string]interface{} `json:"input"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		allow := body.Input["path"] == "/public"
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"result": map[string]interface{}{"allow": allow, "reason": "private"},
+		})
+	}))
+}
+
+func TestOPAMiddleware(t *testing.T) {
+	calls := 0
+	server := newOPAServer(t, &calls)
+	defer server.Close()
+
+	opts := NewOptions()
+	opts.UseViewEngine = false
+	opts.UseRequestLogger = false
+	opts.UseSession = false
+	opts.UseTranslator = false
+	opts.UseOPA = true
+	opts.OPAConfig = OPAConfig{OPAEndpoint: server.URL, PolicyPath: "httpapi/authz"}
+
+	app := NewWithOptions(opts)
+	app.GET("/public", func(c *Context) {
+		c.String(http.StatusOK, "public")
+	})
+	app.GET("/private", func(c *Context) {
+		c.String(http.StatusOK, "private")
+	})
+
+	w := performRequest(app, "GET", "/public")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "public", w.Body.String())
+
+	// result is cached per input
+	w = performRequest(app, "GET", "/public")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, calls)
+
+	w = performRequest(app, "GET", "/private")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, 2, calls)
+}
+
+func TestOPAMiddlewareDenyResponse(t *testing.T) {
+	calls := 0
+	server := newOPAServer(t, &calls)
+
+	app := newTestAppInstance()
+	app.Use(OPAMiddleware(OPAConfig{
+		OPAEndpoint: server.URL,
+		PolicyPath:  "/httpapi/authz",
+		InputBuilder: func(c *Context) map[string]interface{} {
+			return map[string]interface{}{"path": c.Query("path")}
+		},
+		DenyResponse: func(c *Context, result map[string]interface{}) {
+			c.String(http.StatusUnauthorized, result["reason"].(string))
+		},
+	}))
+	app.GET("/", func(c *Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := performRequest(app, "GET", "/?path=/public")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = performRequest(app, "GET", "/?path=/private")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "private", w.Body.String())
+
+	// OPA server unavailable
+	server.Close()
+	w = performRequest(app, "GET", "/?path=/other")
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+}
+
+func TestOPAMiddlewareCache(t *testing.T) {
+	calls := 0
+	server := newOPAServer(t, &calls)
+	defer server.Close()
+
+	app := newTestAppInstance()
+	app.Use(OPAMiddleware(OPAConfig{
+		OPAEndpoint: server.URL,
+		PolicyPath:  "httpapi/authz",
+		CacheSize:   1,
+		InputBuilder: func(c *Context) map[string]interface{} {
+			return map[string]interface{}{"path": c.Query("path")}
+		},
+		DenyResponse: func(c *Context, result map[string]interface{}) {
+			c.String(http.StatusUnauthorized, result["reason"].(string))
+			result["reason"] = "modified"
+		},
+	}))
+	app.GET("/", func(c *Context) {
+		c.Status(http.StatusOK)
+	})
+
+	// cached result is not modified by handlers
+	w := performRequest(app, "GET", "/?path=/private")
+	assert.Equal(t, "private", w.Body.String())
+	w = performRequest(app, "GET", "/?path=/private")
+	assert.Equal(t, "private", w.Body.String())
+	assert.Equal(t, 1, calls)
+
+	// least recently used result is evicted
+	performRequest(app, "GET", "/?path=/public")
+	assert.Equal(t, 2, calls)
+	performRequest(app, "GET", "/?path=/private")
+	assert.Equal(t, 3, calls)
+}
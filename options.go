@@ -1,7 +1,13 @@
 package cucumber
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"html/template"
+	"net"
+	"os"
+	"time"
 
 	"github.com/AjdinHalac/cucumber/log"
 	"github.com/AjdinHalac/cucumber/render/view"
@@ -16,13 +22,19 @@ const (
 
 	defaultLogLevel = "debug"
 
+	defaultShutdownDrainTimeout = 10 * time.Second
+	defaultGRPCShutdownTimeout  = 30 * time.Second
+
 	defaultRedirectTrailingSlash  = true
 	defaultRedirectFixedPath      = false
 	defaultHandleMethodNotAllowed = false
 	defaultMaxMultipartMemory     = 32 << 20 // 32 MB
+	defaultAPIVersion             = "v1"
 
 	default404Body = "404 page not found"
 	default405Body = "405 method not allowed"
+	default500Body = "500 internal server error"
+	default503Body = "503 service unavailable"
 
 	defaultUseSession  = false
 	defaultSessionName = "_cucumber_app_session"
@@ -31,8 +43,26 @@ const (
 	defaultTranslatorLocalesRoot = "locales"
 	defaultTranslatorDefaultLang = "en-US"
 
-	defaultUseRequestLogger = true
-	defaultUsePanicRecovery = true
+	defaultUseRequestLogger     = true
+	defaultRequestLogHumanSize  = true
+	defaultLogResponseBodyLimit = 4 << 10 // 4 KB
+	defaultUsePanicRecovery     = true
+	defaultUseOPA               = false
+
+	defaultUseRequestQueue = false
+	defaultQueueSize       = 100
+	defaultQueueTimeout    = 5 * time.Second
+
+	defaultAccessLogMaxSize    = 100 // 100 MB
+	defaultAccessLogMaxBackups = 3
+
+	defaultReplayBodyLimit = 64 << 10 // 64 KB
+
+	defaultMaxDecompressedBodySize = 32 << 20 // 32 MB
+
+	defaultDigestNonceTTL = 5 * time.Minute
+
+	defaultTransportMetricsInterval = 10 * time.Second
 
 	defaultUseViewEngine     = false
 	defaultViewsRoot         = "views"
@@ -49,6 +79,8 @@ const (
 	defaultControllerPackage = "controllers"
 	// ControllerIndex holds controller Index name
 	defaultControllerIndex = "Index"
+	// ControllerIndexPrefix holds route prefix of Index controller
+	defaultControllerIndexPrefix = "/"
 	// ControllerSuffix holds controller naming convention
 	defaultControllerSuffix = "Controller"
 )
@@ -63,12 +95,63 @@ type Options struct {
 
 	LogLevel string
 
+	// StrictProductionConfig fails start in production env when
+	// ValidateProductionConfig reports problems, they are only logged otherwise
+	StrictProductionConfig bool
+
+	// ShutdownDrainTimeout limits time given to HTTP server
+	// to finish in-flight requests and to services to stop on shutdown
+	ShutdownDrainTimeout time.Duration
+	// GRPCShutdownTimeout limits time given to gRPC server to gracefully
+	// stop on shutdown, zero waits for all in-flight requests
+	GRPCShutdownTimeout time.Duration
+
+	// LivenessRequestDeadline is the time after which HTTP request in flight is
+	// considered stuck by LivenessGuard, twice the guard interval when zero
+	LivenessRequestDeadline time.Duration
+
+	// BaseContext returns base context of requests accepted on listener,
+	// e.g. context canceled on shutdown so long-running handlers stop
+	BaseContext func(net.Listener) context.Context
+	// ConnContext modifies context of requests of a new connection
+	ConnContext func(ctx context.Context, c net.Conn) context.Context
+
+	// UseProxyProtocol reads PROXY protocol (v1 or v2) header of HTTP connections
+	// accepted by StartHTTP and StartWithContext, so request RemoteAddr is the
+	// address of client behind proxy (e.g. HAProxy)
+	UseProxyProtocol bool
+
+	// DisableGRPCReflection does not register gRPC server reflection service
+	DisableGRPCReflection bool
+
+	// BasePath is prepended to all routes, e.g. "/myservice" for application
+	// mounted under shared ingress path, see App.URL
+	BasePath string
+
 	RedirectTrailingSlash  bool
 	RedirectFixedPath      bool
 	HandleMethodNotAllowed bool
 	MaxMultipartMemory     int64
 
+	// DefaultAPIVersion is used to match versioned routes
+	// when Accept header does not specify media type version
+	DefaultAPIVersion string
+
+	// EmptyResponseStatus is served when route handlers complete without
+	// writing response or setting status code, zero serves empty 200 OK
+	EmptyResponseStatus int
+
+	// RequestTimeout applies deadline on every HTTP request context once route
+	// is matched, zero disables the timeout, see NoRequestTimeout
+	RequestTimeout time.Duration
+
+	// Body404 is served for unmatched routes
 	Body404 string
+	// Body405 is served when route does not handle request method,
+	// see HandleMethodNotAllowed
+	Body405 string
+	// Body500 is served instead of error message for 500 responses
+	// without custom error handler
 	Body500 string
 
 	UseSession    bool
@@ -78,73 +161,170 @@ type Options struct {
 	UseTranslator         bool
 	TranslatorLocalesRoot string
 	TranslatorDefaultLang string
+	// TranslatorStrict rejects unsupported languages requested by query string with 406
+	TranslatorStrict bool
+	// TranslatorMarkMissing translates missing keys to "[[key]]" instead of falling back
+	TranslatorMarkMissing bool
+	// MergeStrategy resolves duplicate keys on Translator.Merge
+	// ("overwrite", "skip" or "error")
+	MergeStrategy string
 
 	UseRequestLogger bool
 	UsePanicRecovery bool
 
+	// UseRequestQueue lets at most QueueSize requests be handled at once, others
+	// wait up to QueueTimeout for a free slot before being rejected, see Queue
+	UseRequestQueue bool
+	QueueSize       int
+	QueueTimeout    time.Duration
+
+	// GRPCRecoveryHandler returns error sent to client when unary gRPC handler panics,
+	// Internal status without panic details is returned when nil
+	GRPCRecoveryHandler func(ctx context.Context, p interface{}) error
+
+	// DecompressBody enables DecompressBody middleware on application router
+	DecompressBody bool
+	// MaxDecompressedBodySize limits size in bytes of request body decompressed
+	// by DecompressBody, zero disables the limit
+	MaxDecompressedBodySize int64
+
+	// UseOPA enables OPAMiddleware on application router configured with OPAConfig
+	UseOPA    bool
+	OPAConfig OPAConfig
+
 	UseViewEngine     bool
 	ViewsRoot         string
 	ViewsExt          string
 	ViewsMasterLayout string
 	ViewsPartialsRoot string
 	ViewsDisableCache bool
+	// ViewsLazyLoad defers loading of views and partials until first render
+	ViewsLazyLoad bool
 
 	ServeStatic bool
 	StaticPath  string
 	StaticDir   string
 
-	Logger            log.Logger
-	SessionStore      sessions.Store
-	ViewEngine        view.Engine
-	Translator        *Translator
-	UnaryInterceptors []grpc.UnaryServerInterceptor
+	Logger             log.Logger
+	LogBackends        []log.Logger
+	SessionStore       sessions.Store
+	ViewEngine         view.Engine
+	Translator         *Translator
+	FlagProvider       FlagProvider
+	ResponseEnvelope   ResponseEnvelope
+	UnaryInterceptors  []grpc.UnaryServerInterceptor
+	StreamInterceptors []grpc.StreamServerInterceptor
 
 	// ControllerPackage holds package name in which controllers can be registered
 	ControllerPackage string
 	// ControllerIndex holds controller Index name
 	ControllerIndex string
+	// ControllerIndexPrefix holds route prefix of Index controller, e.g. "/api"
+	ControllerIndexPrefix string
 	// ControllerSuffix holds controller naming convention
 	ControllerSuffix string
 
 	RequestLoggerIgnore []string
 
+	// SlowRequestThreshold makes request loggers log requests taking longer
+	// at warn level with "slow" field, zero disables the check
+	SlowRequestThreshold time.Duration
+
+	// RequestLogContextKeys lists context keys logged by RequestLogger when set
+	RequestLogContextKeys []string
+
+	// RequestLogHumanSize makes RequestLogger log response size in human readable
+	// format as "human_size" field
+	RequestLogHumanSize bool
+
+	// LogResponseBody makes RequestLogger log response body up to LogResponseBodyLimit
+	// bytes as "response_body" field, redacted by ResponseScrubber when set
+	LogResponseBody      bool
+	LogResponseBodyLimit int
+	ResponseScrubber     ResponseScrubber
+
+	// AccessLogMaxSize is size in megabytes at which AccessLog file is rotated
+	AccessLogMaxSize int
+	// AccessLogMaxBackups is number of rotated AccessLog files to retain, zero retains all
+	AccessLogMaxBackups int
+	// AccessLogBufferSize is size in bytes of AccessLog write buffer, zero disables buffering
+	AccessLogBufferSize int
+
+	// ReplayStore holds entries replayed by App.ReplayRequest, see ReplayRecorder
+	ReplayStore ReplayStore
+	// ReplayBodyLimit is the maximum size in bytes of request and response body recorded by ReplayRecorder
+	ReplayBodyLimit int
+
+	// DigestNonceStore issues nonces of DigestAuth challenges, defaults to InMemoryNonceStore
+	DigestNonceStore NonceStore
+	// DigestNonceTTL is time after which DigestAuth nonce issued by default nonce store expires
+	DigestNonceTTL time.Duration
+
+	// TransportMetricsInterval is time between samples of transport metrics, see App.ExposeTransportMetrics
+	TransportMetricsInterval time.Duration
+
 	UnaryRequestLoggerIgnore []string
 
+	// RegisterGRPCHealthService registers grpc.health.v1 health service
+	// reporting all registered services as serving
+	RegisterGRPCHealthService bool
+
+	// DebugDI times dependency injection into registered services and controllers,
+	// logging it at debug level and collecting it in App.DIStats
+	DebugDI bool
+
 	AppConfig interface{}
 }
 
 // NewOptions returns a new Options instance with default configuration
 func NewOptions() Options {
 	opts := Options{
-		Env:                    defaultEnv,
-		Name:                   defaultName,
-		Version:                defaultVersion,
-		LogLevel:               defaultLogLevel,
-		RedirectTrailingSlash:  defaultRedirectTrailingSlash,
-		RedirectFixedPath:      defaultRedirectFixedPath,
-		HandleMethodNotAllowed: defaultHandleMethodNotAllowed,
-		MaxMultipartMemory:     defaultMaxMultipartMemory,
-		Body404:                default404Body,
-		Body500:                default405Body,
-		UseSession:             defaultUseSession,
-		SessionName:            defaultSessionName,
-		UseTranslator:          defaultUseTranslator,
-		TranslatorLocalesRoot:  defaultTranslatorLocalesRoot,
-		TranslatorDefaultLang:  defaultTranslatorDefaultLang,
-		UseRequestLogger:       defaultUseRequestLogger,
-		UsePanicRecovery:       defaultUsePanicRecovery,
-		UseViewEngine:          defaultUseViewEngine,
-		ViewsRoot:              defaultViewsRoot,
-		ViewsExt:               defaultViewsExt,
-		ViewsMasterLayout:      defaultViewsMasterLayout,
-		ViewsPartialsRoot:      defaultViewsPartialsRoot,
-		ViewsDisableCache:      defaultViewsDisableCache,
-		ServeStatic:            defaultServeStatic,
-		StaticPath:             defaultStaticPath,
-		StaticDir:              defaultStaticDir,
-		ControllerPackage:      defaultControllerPackage,
-		ControllerIndex:        defaultControllerIndex,
-		ControllerSuffix:       defaultControllerSuffix,
+		Env:                      defaultEnv,
+		Name:                     defaultName,
+		Version:                  defaultVersion,
+		LogLevel:                 defaultLogLevel,
+		ShutdownDrainTimeout:     defaultShutdownDrainTimeout,
+		GRPCShutdownTimeout:      defaultGRPCShutdownTimeout,
+		RedirectTrailingSlash:    defaultRedirectTrailingSlash,
+		RedirectFixedPath:        defaultRedirectFixedPath,
+		HandleMethodNotAllowed:   defaultHandleMethodNotAllowed,
+		MaxMultipartMemory:       defaultMaxMultipartMemory,
+		DefaultAPIVersion:        defaultAPIVersion,
+		Body404:                  default404Body,
+		Body405:                  default405Body,
+		Body500:                  default500Body,
+		UseSession:               defaultUseSession,
+		SessionName:              defaultSessionName,
+		UseTranslator:            defaultUseTranslator,
+		TranslatorLocalesRoot:    defaultTranslatorLocalesRoot,
+		TranslatorDefaultLang:    defaultTranslatorDefaultLang,
+		UseRequestLogger:         defaultUseRequestLogger,
+		RequestLogHumanSize:      defaultRequestLogHumanSize,
+		LogResponseBodyLimit:     defaultLogResponseBodyLimit,
+		UsePanicRecovery:         defaultUsePanicRecovery,
+		UseRequestQueue:          defaultUseRequestQueue,
+		QueueSize:                defaultQueueSize,
+		QueueTimeout:             defaultQueueTimeout,
+		UseOPA:                   defaultUseOPA,
+		AccessLogMaxSize:         defaultAccessLogMaxSize,
+		AccessLogMaxBackups:      defaultAccessLogMaxBackups,
+		ReplayBodyLimit:          defaultReplayBodyLimit,
+		MaxDecompressedBodySize:  defaultMaxDecompressedBodySize,
+		DigestNonceTTL:           defaultDigestNonceTTL,
+		TransportMetricsInterval: defaultTransportMetricsInterval,
+		UseViewEngine:            defaultUseViewEngine,
+		ViewsRoot:                defaultViewsRoot,
+		ViewsExt:                 defaultViewsExt,
+		ViewsMasterLayout:        defaultViewsMasterLayout,
+		ViewsPartialsRoot:        defaultViewsPartialsRoot,
+		ViewsDisableCache:        defaultViewsDisableCache,
+		ServeStatic:              defaultServeStatic,
+		StaticPath:               defaultStaticPath,
+		StaticDir:                defaultStaticDir,
+		ControllerPackage:        defaultControllerPackage,
+		ControllerIndex:          defaultControllerIndex,
+		ControllerIndexPrefix:    defaultControllerIndexPrefix,
+		ControllerSuffix:         defaultControllerSuffix,
 	}
 
 	return opts
@@ -152,6 +332,14 @@ func NewOptions() Options {
 
 func optionsWithDefault(opts Options) Options {
 	//configure logger
+	if len(opts.LogBackends) > 0 {
+		backends := opts.LogBackends
+		// explicitly set logger keeps receiving entries alongside backends
+		if opts.Logger != nil {
+			backends = append([]log.Logger{opts.Logger}, backends...)
+		}
+		opts.Logger = log.NewMultiLogger(backends...)
+	}
 	if opts.Logger == nil {
 		opts.Logger = log.New(log.Configuration{
 			EnableConsole:     true,
@@ -160,36 +348,72 @@ func optionsWithDefault(opts Options) Options {
 		})
 	}
 
+	//migrate deprecated options
+	opts, warnings := opts.Migrate()
+	for _, warning := range warnings {
+		opts.Logger.Warn(warning)
+	}
+
 	//configure session store
 	if opts.UseSession && opts.SessionStore == nil {
-		if opts.SessionSecret == "" {
-			opts.Logger.Warn("SessionSecret configuration key is not set. Your sessions are not safe!")
-		}
+		checkSessionSecret(opts, opts.Logger)
 		opts.SessionStore = sessions.NewCookieStore([]byte(opts.SessionSecret))
 	}
 	//configure ViewEngine
 	if opts.UseViewEngine && opts.ViewEngine == nil {
-		partials, err := loadPartials(opts.ViewsRoot, opts.ViewsPartialsRoot, opts.ViewsExt)
-		if err != nil {
-			opts.Logger.Fatal(err.Error())
-		}
-		opts.ViewEngine = view.NewHTMLEngine(view.Config{
+		config := view.Config{
 			Root:         opts.ViewsRoot,
 			Ext:          opts.ViewsExt,
 			Master:       opts.ViewsMasterLayout,
-			Partials:     partials,
 			Funcs:        make(template.FuncMap),
 			DisableCache: opts.ViewsDisableCache,
 			Delims:       view.Delims{Left: "{{", Right: "}}"},
-		})
+		}
+		if opts.ViewsLazyLoad {
+			config.PartialsRoot = opts.ViewsPartialsRoot
+			opts.ViewEngine = view.NewLazyHTMLEngine(config, opts.Logger)
+		} else {
+			partials, err := view.LoadPartials(opts.ViewsRoot, opts.ViewsPartialsRoot, opts.ViewsExt)
+			if err != nil {
+				opts.Logger.Fatal(err.Error())
+			}
+			config.Partials = partials
+			opts.ViewEngine = view.NewHTMLEngine(config)
+		}
+	}
+
+	if opts.DigestNonceTTL == 0 {
+		opts.DigestNonceTTL = defaultDigestNonceTTL
+	}
+
+	// configure feature flags
+	if opts.FlagProvider == nil {
+		opts.FlagProvider = noFlagProvider{}
+	}
+
+	// configure success response envelope
+	if opts.ResponseEnvelope == nil {
+		opts.ResponseEnvelope = defaultResponseEnvelope
 	}
 
 	// configure translator
 	if opts.UseTranslator && opts.Translator == nil {
 		t, err := NewTranslator(opts.TranslatorLocalesRoot, opts.TranslatorDefaultLang)
-		if err != nil {
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			// missing locales must not take down the app
+			opts.Logger.Warn(fmt.Sprintf("i18n Translator: locales directory %q not found, only default language %q is available", opts.TranslatorLocalesRoot, opts.TranslatorDefaultLang))
+		case err != nil:
 			opts.Logger.Fatal(err.Error())
+		case len(t.AvailableLanguages()) == 0:
+			opts.Logger.Warn(fmt.Sprintf("i18n Translator: no translations found in %q", opts.TranslatorLocalesRoot))
+		}
+		if opts.MergeStrategy != "" {
+			t.MergeStrategy = opts.MergeStrategy
 		}
+		t.Strict = opts.TranslatorStrict
+		t.ReportMissing = opts.Env == "development"
+		t.MarkMissing = opts.TranslatorMarkMissing
 		opts.Translator = t
 	}
 
@@ -0,0 +1,40 @@
+package cucumber
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// optionMigration maps deprecated Options field to the field replacing it
+type optionMigration struct {
+	Deprecated  string
+	Replacement string
+}
+
+// optionMigrations lists deprecated Options fields migrated by Options.Migrate
+var optionMigrations = []optionMigration{}
+
+// Migrate returns options with values of deprecated fields moved to fields
+// replacing them, and deprecation warning for each migrated field
+//
+// Deprecated field set to non-zero value overrides its replacement and is
+// reset, so options can be migrated repeatedly.
+func (o Options) Migrate() (Options, []string) {
+	warnings := migrateFields(reflect.ValueOf(&o).Elem(), "Options", optionMigrations)
+	return o, warnings
+}
+
+// migrateFields moves values of deprecated fields of struct v to their replacements
+func migrateFields(v reflect.Value, typeName string, migrations []optionMigration) []string {
+	var warnings []string
+	for _, m := range migrations {
+		deprecated := v.FieldByName(m.Deprecated)
+		if deprecated.IsZero() {
+			continue
+		}
+		v.FieldByName(m.Replacement).Set(deprecated)
+		deprecated.Set(reflect.Zero(deprecated.Type()))
+		warnings = append(warnings, fmt.Sprintf("%s.%s is deprecated, use %s.%s instead", typeName, m.Deprecated, typeName, m.Replacement))
+	}
+	return warnings
+}
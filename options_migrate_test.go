@@ -0,0 +1,47 @@
+package cucumber
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// legacyOptions has a deprecated field exercising option migrations
+type legacyOptions struct {
+	MaxMultipartMemory int64
+	MultipartMaxMemory int64
+}
+
+func TestMigrateFields(t *testing.T) {
+	migrations := []optionMigration{{Deprecated: "MultipartMaxMemory", Replacement: "MaxMultipartMemory"}}
+	opts := legacyOptions{MaxMultipartMemory: 32 << 20, MultipartMaxMemory: 8 << 20}
+
+	warnings := migrateFields(reflect.ValueOf(&opts).Elem(), "Options", migrations)
+	assert.Equal(t, int64(8<<20), opts.MaxMultipartMemory)
+	assert.Zero(t, opts.MultipartMaxMemory)
+	assert.Equal(t, []string{"Options.MultipartMaxMemory is deprecated, use Options.MaxMultipartMemory instead"}, warnings)
+
+	// migrated options are left intact
+	warnings = migrateFields(reflect.ValueOf(&opts).Elem(), "Options", migrations)
+	assert.Equal(t, int64(8<<20), opts.MaxMultipartMemory)
+	assert.Empty(t, warnings)
+}
+
+func TestOptionsMigrate(t *testing.T) {
+	opts := NewOptions()
+	migrated, warnings := opts.Migrate()
+	assert.Equal(t, opts.MaxMultipartMemory, migrated.MaxMultipartMemory)
+	assert.Empty(t, warnings)
+}
+
+func TestOptionMigrationsFields(t *testing.T) {
+	typ := reflect.TypeOf(Options{})
+	for _, m := range optionMigrations {
+		deprecated, ok := typ.FieldByName(m.Deprecated)
+		assert.True(t, ok, m.Deprecated)
+		replacement, ok := typ.FieldByName(m.Replacement)
+		assert.True(t, ok, m.Replacement)
+		assert.Equal(t, replacement.Type, deprecated.Type, m.Deprecated)
+	}
+}
@@ -2,11 +2,14 @@ package cucumber
 
 import (
 	"context"
+	"fmt"
 	"net"
 	"net/http"
 	"os"
+	"runtime/debug"
 	"strings"
 
+	"github.com/AjdinHalac/cucumber/log"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -16,7 +19,12 @@ import (
 func PanicRecovery() HandlerFunc {
 	return func(c *Context) {
 		defer func() {
-			if err := recover(); err != nil {
+			if r := recover(); r != nil {
+				// panic value is not necessarily an error, e.g. panic("boom")
+				err, ok := r.(error)
+				if !ok {
+					err = fmt.Errorf("%v", r)
+				}
 
 				var brokenPipe bool
 				if ne, ok := err.(*net.OpError); ok {
@@ -28,10 +36,10 @@ func PanicRecovery() HandlerFunc {
 				}
 
 				if brokenPipe {
-					c.Error(err.(error))
+					c.Error(err)
 					c.Abort()
 				} else {
-					c.ServeError(http.StatusInternalServerError, err.(error))
+					c.ServeError(http.StatusInternalServerError, err)
 				}
 			}
 		}()
@@ -40,14 +48,46 @@ func PanicRecovery() HandlerFunc {
 }
 
 // NewUnaryPanicRecovery creates  interceptor to protect a process from aborting by panic and return Internal error as status code
+//
+// Panic value, its type and stack trace are logged together with method name and request ID,
+// while client receives only the error returned by Options.GRPCRecoveryHandler,
+// or Internal status with generic message when it is not set.
 func NewUnaryPanicRecovery(opts Options) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
 		defer func() {
 			if r := recover(); r != nil {
-				err = status.Errorf(codes.Internal, "panic: %v", r)
+				logPanic(ctx, opts, info.FullMethod, r)
+
+				if opts.GRPCRecoveryHandler != nil {
+					err = opts.GRPCRecoveryHandler(ctx, r)
+					return
+				}
+				err = status.Error(codes.Internal, "internal error")
 			}
 		}()
 
 		return handler(ctx, req)
 	}
 }
+
+func logPanic(ctx context.Context, opts Options, method string, r interface{}) {
+	// prefer request logger which already holds request fields
+	l, ok := log.FromContext(ctx)
+	if !ok {
+		l = opts.Logger
+	}
+	if l == nil {
+		return
+	}
+
+	fields := log.Fields{
+		"grpc.method": method,
+		"panic":       fmt.Sprintf("%v", r),
+		"panic_type":  fmt.Sprintf("%T", r),
+		"stack":       string(debug.Stack()),
+	}
+	if requestID, ok := RequestIDFromContext(ctx); ok {
+		fields["request_id"] = requestID
+	}
+	l.WithFields(fields).Error("recovered from panic")
+}
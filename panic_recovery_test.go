@@ -0,0 +1,80 @@
+package cucumber
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPanicRecovery(t *testing.T) {
+	var errs []string
+	app := newTestAppInstance()
+	app.Use(func(c *Context) {
+		c.Next()
+		errs = c.Errors.Errors()
+	}, PanicRecovery())
+	app.GET("/string", func(c *Context) {
+		panic("boom")
+	})
+	app.GET("/error", func(c *Context) {
+		panic(errors.New("boom error"))
+	})
+	app.GET("/int", func(c *Context) {
+		panic(42)
+	})
+
+	w := performRequest(app, "GET", "/string")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, default500Body, w.Body.String())
+	assert.Equal(t, []string{"boom"}, errs)
+
+	w = performRequest(app, "GET", "/error")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, []string{"boom error"}, errs)
+
+	w = performRequest(app, "GET", "/int")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, []string{"42"}, errs)
+}
+
+func TestUnaryPanicRecovery(t *testing.T) {
+	interceptor := NewUnaryPanicRecovery(NewOptions())
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom: db password s3cret")
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, "internal error", status.Convert(err).Message())
+	assert.NotContains(t, err.Error(), "boom")
+}
+
+func TestUnaryPanicRecoveryLogsStack(t *testing.T) {
+	logger := newLevelLogger()
+	opts := NewOptions()
+	opts.Logger = logger
+	opts.GRPCRecoveryHandler = func(ctx context.Context, p interface{}) error {
+		return status.Error(codes.Unavailable, "try again later")
+	}
+
+	interceptor := NewUnaryPanicRecovery(opts)
+	ctx := ContextWithRequestID(context.Background(), "request-id")
+	info := &grpc.UnaryServerInfo{FullMethod: "/cucumber.Test/Call"}
+	_, err := interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom")
+	})
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, "try again later", status.Convert(err).Message())
+
+	assert.Equal(t, "error", *logger.level)
+	assert.Equal(t, "/cucumber.Test/Call", (*logger.last)["grpc.method"])
+	assert.Equal(t, "request-id", (*logger.last)["request_id"])
+	assert.Equal(t, "boom", (*logger.last)["panic"])
+	assert.Equal(t, "string", (*logger.last)["panic_type"])
+	assert.Contains(t, (*logger.last)["stack"], "panic_recovery_test.go")
+}
@@ -0,0 +1,32 @@
+package cucumber
+
+// ParamAlias makes old name of path parameter resolve to parameter new in
+// c.Param for routes of this group registered afterwards
+//
+// It keeps clients using the old name working after path parameter is renamed:
+//
+//	users := router.Group("/users").ParamAlias("user_id", "userId")
+//	users.GET("/:userId", handler) // c.Param("user_id") returns value of :userId
+func (r *Router) ParamAlias(old, new string) *Router {
+	aliases := make(map[string]string, len(r.paramAliases)+1)
+	for k, v := range r.paramAliases {
+		aliases[k] = v
+	}
+	aliases[old] = new
+	r.paramAliases = aliases
+	return r
+}
+
+// withParamAliases prepends handler setting param aliases of the group to route handlers
+func (r *Router) withParamAliases(handlers HandlersChain) HandlersChain {
+	if len(r.paramAliases) == 0 {
+		return handlers
+	}
+	aliases := r.paramAliases
+	chained := make(HandlersChain, 0, len(handlers)+1)
+	chained = append(chained, func(c *Context) {
+		c.paramAliases = aliases
+		c.Next()
+	})
+	return append(chained, handlers...)
+}
@@ -0,0 +1,31 @@
+package cucumber
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParamAlias(t *testing.T) {
+	app := newTestAppInstance()
+
+	var userID, oldUserID, orderID string
+	users := app.Router().Group("/users").ParamAlias("user_id", "userId")
+	users.GET("/:userId", func(c *Context) {
+		userID = c.Param("userId")
+		oldUserID = c.Param("user_id")
+	})
+	app.GET("/orders/:userId", func(c *Context) {
+		orderID = c.Param("user_id")
+	})
+
+	w := performRequest(app, http.MethodGet, "/users/42")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "42", userID)
+	assert.Equal(t, "42", oldUserID)
+
+	// alias is scoped to the group
+	performRequest(app, http.MethodGet, "/orders/7")
+	assert.Empty(t, orderID)
+}
@@ -0,0 +1,52 @@
+package cucumber
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInsecureConfig is returned by ValidateProductionConfig when options are not fit for production
+var ErrInsecureConfig = errors.New("insecure production config")
+
+// ValidateProductionConfig reports options which are insecure or suboptimal
+// in production, e.g. debug log level left on by accident
+//
+// TLS is not checked, as it is terminated in front of the application.
+func (a *App) ValidateProductionConfig() error {
+	var problems []string
+	if a.UseSession && a.SessionSecret == "" {
+		problems = append(problems, "SessionSecret is empty so sessions can be forged, set it to GenerateSessionSecret() output")
+	} else if a.UseSession && len(a.SessionSecret) < MinSessionSecretLength {
+		problems = append(problems, fmt.Sprintf("SessionSecret is shorter than %d bytes, set it to GenerateSessionSecret() output", MinSessionSecretLength))
+	}
+	if !a.DisableGRPCReflection {
+		problems = append(problems, "gRPC reflection exposes service descriptors, set DisableGRPCReflection")
+	}
+	if strings.EqualFold(a.LogLevel, "debug") {
+		problems = append(problems, "LogLevel is debug, set it to info or higher")
+	}
+	// caching parsed views is on unless ViewsDisableCache is set
+	if a.UseViewEngine && a.ViewsDisableCache {
+		problems = append(problems, "ViewsDisableCache parses views on every render, unset it")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInsecureConfig, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// checkProductionConfig validates config in production env, problems
+// fail start with StrictProductionConfig and are logged otherwise
+func (a *App) checkProductionConfig() error {
+	if a.Env != "production" {
+		return nil
+	}
+	err := a.ValidateProductionConfig()
+	if err == nil || a.StrictProductionConfig {
+		return err
+	}
+	a.Logger.Warn(err.Error())
+	return nil
+}
@@ -0,0 +1,61 @@
+package cucumber
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateProductionConfig(t *testing.T) {
+	opts := NewOptions()
+	opts.Env = "production"
+	opts.UseSession = true
+	opts.SessionSecret = "s3cret"
+	opts.UseViewEngine = true
+	opts.ViewsLazyLoad = true
+	opts.ViewsDisableCache = true
+	opts.UseRequestLogger = false
+	app := NewWithOptions(opts)
+
+	_, reflected := app.server.GetServiceInfo()["grpc.reflection.v1alpha.ServerReflection"]
+	assert.True(t, reflected)
+
+	err := app.ValidateProductionConfig()
+	assert.ErrorIs(t, err, ErrInsecureConfig)
+	for _, problem := range []string{"SessionSecret", "DisableGRPCReflection", "LogLevel", "ViewsDisableCache"} {
+		assert.Contains(t, err.Error(), problem)
+	}
+
+	opts.SessionSecret = GenerateSessionSecret()
+	opts.DisableGRPCReflection = true
+	opts.LogLevel = "info"
+	opts.ViewsDisableCache = false
+	app = NewWithOptions(opts)
+	assert.NoError(t, app.ValidateProductionConfig())
+	_, reflected = app.server.GetServiceInfo()["grpc.reflection.v1alpha.ServerReflection"]
+	assert.False(t, reflected)
+}
+
+func TestAppStrictProductionConfig(t *testing.T) {
+	opts := NewOptions()
+	opts.Env = "production"
+	opts.HTTPAddr = ""
+	opts.GRPCAddr = ""
+	opts.UseRequestLogger = false
+
+	// problems are only logged by default
+	logger := newLevelLogger()
+	opts.Logger = logger
+	app := NewWithOptions(opts)
+	assert.NoError(t, app.checkProductionConfig())
+	assert.Equal(t, "warn", *logger.level)
+
+	opts.StrictProductionConfig = true
+	app = NewWithOptions(opts)
+	assert.ErrorIs(t, app.StartWithContext(context.Background()), ErrInsecureConfig)
+
+	// other envs are not checked
+	app.Env = "staging"
+	assert.NoError(t, app.checkProductionConfig())
+}
@@ -0,0 +1,100 @@
+package cucumber
+
+import (
+	"context"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+)
+
+// ProxyOptions configures reverse proxy routes
+type ProxyOptions struct {
+	// StripPrefix is removed from request path before it is forwarded
+	StripPrefix string
+	// Rewrite allows custom rewriting of forwarded request path
+	Rewrite func(path string) string
+	// Target selects upstream per request, default target is used when nil is returned
+	Target func(c *Context) *url.URL
+	// Transport used to reach upstream, http.DefaultTransport is used when nil
+	Transport http.RoundTripper
+}
+
+// Proxy registers route which forwards all the HTTP methods to target upstream
+//
+//	router.Proxy("/users/*path", usersURL, cucumber.ProxyOptions{StripPrefix: "/users"})
+func (r *Router) Proxy(path string, target *url.URL, opts ProxyOptions) {
+	r.Any(path, func(c *Context) {
+		c.proxy(target, opts)
+	})
+}
+
+// Proxy forwards current request to target upstream
+func (c *Context) Proxy(target *url.URL) {
+	c.proxy(target, ProxyOptions{})
+}
+
+func (c *Context) proxy(target *url.URL, opts ProxyOptions) {
+	if opts.Target != nil {
+		if t := opts.Target(c); t != nil {
+			target = t
+		}
+	}
+
+	requestID := c.RequestID()
+	host := c.Request.Host
+	proto := c.requestHeader("X-Forwarded-Proto")
+	if proto == "" {
+		proto = "http"
+		if c.Request.TLS != nil {
+			proto = "https"
+		}
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			path := req.URL.Path
+			if opts.StripPrefix != "" {
+				path = strings.TrimPrefix(path, opts.StripPrefix)
+				if !strings.HasPrefix(path, "/") {
+					path = "/" + path
+				}
+			}
+			if opts.Rewrite != nil {
+				path = opts.Rewrite(path)
+			}
+
+			req.URL.Scheme = target.Scheme
+			req.URL.Host = target.Host
+			req.URL.Path = joinPaths(target.Path, path)
+			req.URL.RawPath = ""
+			if target.RawQuery == "" || req.URL.RawQuery == "" {
+				req.URL.RawQuery = target.RawQuery + req.URL.RawQuery
+			} else {
+				req.URL.RawQuery = target.RawQuery + "&" + req.URL.RawQuery
+			}
+			req.Host = target.Host
+
+			if _, ok := req.Header["User-Agent"]; !ok {
+				// explicitly disable User-Agent so it's not set to default value
+				req.Header.Set("User-Agent", "")
+			}
+			if requestID != "" {
+				req.Header.Set(RequestIDHeader, requestID)
+			}
+			req.Header.Set("X-Forwarded-Host", host)
+			req.Header.Set("X-Forwarded-Proto", proto)
+		},
+		Transport: opts.Transport,
+		ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+			c.ServeError(http.StatusBadGateway, err)
+		},
+	}
+
+	// ReverseProxy falls back to CloseNotifier when request context
+	// can not be cancelled, which is not supported by all response writers
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	proxy.ServeHTTP(c.Response, c.Request.WithContext(ctx))
+}
@@ -0,0 +1,175 @@
+package cucumber
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// proxyProtocolHeaderTimeout limits time to receive PROXY protocol header
+const proxyProtocolHeaderTimeout = 10 * time.Second
+
+// proxyProtocolV2Signature starts PROXY protocol v2 header
+var proxyProtocolV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// ErrProxyProtocolHeader is returned when connection does not start with valid PROXY protocol header
+var ErrProxyProtocolHeader = errors.New("invalid PROXY protocol header")
+
+// proxyProtocolListener accepts connections starting with PROXY protocol
+// (v1 or v2) header, their RemoteAddr is the address of proxied client
+type proxyProtocolListener struct {
+	net.Listener
+}
+
+func (l proxyProtocolListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &proxyProtocolConn{Conn: conn, r: bufio.NewReader(conn)}, nil
+}
+
+// proxyProtocolConn reads PROXY protocol header on first use, so slow
+// clients do not block accepting connections
+type proxyProtocolConn struct {
+	net.Conn
+	r *bufio.Reader
+
+	once   sync.Once
+	remote net.Addr
+	err    error
+}
+
+func (c *proxyProtocolConn) readHeader() {
+	c.once.Do(func() {
+		c.Conn.SetReadDeadline(time.Now().Add(proxyProtocolHeaderTimeout))
+		c.remote, c.err = readProxyProtocolHeader(c.r)
+		c.Conn.SetReadDeadline(time.Time{})
+	})
+}
+
+func (c *proxyProtocolConn) Read(p []byte) (int, error) {
+	c.readHeader()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.r.Read(p)
+}
+
+// RemoteAddr returns address of proxied client, or address of proxy for
+// LOCAL and UNKNOWN headers
+func (c *proxyProtocolConn) RemoteAddr() net.Addr {
+	c.readHeader()
+	if c.remote != nil {
+		return c.remote
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// readProxyProtocolHeader reads PROXY protocol header and returns source
+// address, which is nil when header does not carry it
+func readProxyProtocolHeader(r *bufio.Reader) (net.Addr, error) {
+	sig, err := r.Peek(len(proxyProtocolV2Signature))
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(sig, proxyProtocolV2Signature) {
+		return readProxyProtocolV2(r)
+	}
+	if bytes.HasPrefix(sig, []byte("PROXY ")) {
+		return readProxyProtocolV1(r)
+	}
+	return nil, ErrProxyProtocolHeader
+}
+
+// readProxyProtocolV1 reads human-readable header, e.g.
+// "PROXY TCP4 203.0.113.7 10.0.0.1 56324 443\r\n"
+func readProxyProtocolV1(r *bufio.Reader) (net.Addr, error) {
+	const maxLength = 107
+	var line []byte
+	for !bytes.HasSuffix(line, []byte("\r\n")) {
+		if len(line) == maxLength {
+			return nil, ErrProxyProtocolHeader
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, b)
+	}
+
+	fields := strings.Fields(string(line))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, ErrProxyProtocolHeader
+	}
+	ip := net.ParseIP(fields[2])
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if ip == nil || err != nil {
+		return nil, ErrProxyProtocolHeader
+	}
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+// readProxyProtocolV2 reads binary header
+func readProxyProtocolV2(r *bufio.Reader) (net.Addr, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	verCmd, family := header[12], header[13]
+	length := binary.BigEndian.Uint16(header[14:16])
+	if verCmd>>4 != 2 {
+		return nil, ErrProxyProtocolHeader
+	}
+
+	addrs := make([]byte, length)
+	if _, err := io.ReadFull(r, addrs); err != nil {
+		return nil, err
+	}
+
+	// LOCAL command is sent by proxy itself, e.g. health checks
+	if verCmd&0x0f == 0 {
+		return nil, nil
+	}
+	if verCmd&0x0f != 1 {
+		return nil, ErrProxyProtocolHeader
+	}
+
+	switch family >> 4 {
+	case 1: // AF_INET
+		if len(addrs) < 12 {
+			return nil, ErrProxyProtocolHeader
+		}
+		return &net.TCPAddr{IP: net.IP(addrs[0:4]), Port: int(binary.BigEndian.Uint16(addrs[8:10]))}, nil
+	case 2: // AF_INET6
+		if len(addrs) < 36 {
+			return nil, ErrProxyProtocolHeader
+		}
+		return &net.TCPAddr{IP: net.IP(addrs[0:16]), Port: int(binary.BigEndian.Uint16(addrs[32:34]))}, nil
+	}
+	// unspecified or unix socket addresses are not useful as client address
+	return nil, nil
+}
+
+// listenHTTP creates listener of HTTP server, reading PROXY protocol
+// headers when Options.UseProxyProtocol is set
+func (a *App) listenHTTP() (net.Listener, error) {
+	lis, err := listen(a.HTTPAddr)
+	if err != nil {
+		return nil, err
+	}
+	if a.UseProxyProtocol {
+		lis = proxyProtocolListener{Listener: lis}
+	}
+	return lis, nil
+}
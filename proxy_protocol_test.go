@@ -0,0 +1,107 @@
+package cucumber
+
+import (
+	"bufio"
+	"encoding/binary"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// proxyProtocolV2Header builds PROXY v2 header of TCP over IPv4 connection
+func proxyProtocolV2Header(src, dst net.IP, srcPort, dstPort uint16) []byte {
+	header := append([]byte{}, proxyProtocolV2Signature...)
+	header = append(header, 0x21, 0x11, 0, 12)
+	header = append(header, src.To4()...)
+	header = append(header, dst.To4()...)
+	port := make([]byte, 2)
+	binary.BigEndian.PutUint16(port, srcPort)
+	header = append(header, port...)
+	binary.BigEndian.PutUint16(port, dstPort)
+	return append(header, port...)
+}
+
+func TestProxyProtocolListener(t *testing.T) {
+	app := newTestAppInstance()
+	app.HTTPAddr = "127.0.0.1:0"
+	app.UseProxyProtocol = true
+
+	var remoteAddr, clientIP string
+	app.GET("/", func(c *Context) {
+		remoteAddr = c.Request.RemoteAddr
+		clientIP = c.ClientIP()
+		c.Status(http.StatusOK)
+	})
+
+	lis, err := app.listenHTTP()
+	if !assert.NoError(t, err) {
+		return
+	}
+	srv := app.newHTTPServer()
+	go srv.Serve(lis)
+	defer srv.Close()
+
+	request := "GET / HTTP/1.1\r\nHost: example.com\r\nConnection: close\r\n\r\n"
+	send := func(header []byte) (*http.Response, error) {
+		conn, err := net.Dial("tcp", lis.Addr().String())
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+		conn.Write(append(header, request...))
+		return http.ReadResponse(bufio.NewReader(conn), nil)
+	}
+
+	res, err := send([]byte("PROXY TCP4 203.0.113.7 10.0.0.1 56324 443\r\n"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "203.0.113.7:56324", remoteAddr)
+		assert.Equal(t, "203.0.113.7", clientIP)
+	}
+
+	res, err = send(proxyProtocolV2Header(net.ParseIP("198.51.100.22"), net.ParseIP("10.0.0.1"), 41000, 443))
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "198.51.100.22:41000", remoteAddr)
+	}
+
+	// requests without header are rejected
+	remoteAddr = ""
+	res, err = send(nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Empty(t, remoteAddr)
+	}
+}
+
+func TestReadProxyProtocolHeader(t *testing.T) {
+	tt := []struct {
+		Header string
+		Addr   string
+		Err    bool
+	}{
+		{Header: "PROXY TCP6 2001:db8::1 2001:db8::2 56324 443\r\n", Addr: "[2001:db8::1]:56324"},
+		{Header: "PROXY UNKNOWN\r\n"},
+		{Header: "PROXY TCP4 203.0.113.7 10.0.0.1 port 443\r\n", Err: true},
+		{Header: "PROXY UDP4 203.0.113.7 10.0.0.1 56324 443\r\n", Err: true},
+		{Header: "PROXY TCP4 " + strings.Repeat("1", 100) + "\r\n", Err: true},
+		{Header: "GET / HTTP/1.1\r\n", Err: true},
+		{Header: string(append(proxyProtocolV2Signature, 0x20, 0x00, 0, 0)) + "GET"},
+	}
+
+	for _, tc := range tt {
+		addr, err := readProxyProtocolHeader(bufio.NewReader(strings.NewReader(tc.Header)))
+		if tc.Err {
+			assert.Error(t, err, tc.Header)
+			continue
+		}
+		if assert.NoError(t, err, tc.Header) && tc.Addr != "" {
+			assert.Equal(t, tc.Addr, addr.String())
+		} else {
+			assert.Nil(t, addr, tc.Header)
+		}
+	}
+}
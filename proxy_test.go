@@ -0,0 +1,72 @@
+package cucumber
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Upstream-Request-ID", r.Header.Get(RequestIDHeader))
+		w.Header().Set("X-Upstream-Forwarded-Host", r.Header.Get("X-Forwarded-Host"))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(r.Method + " " + r.URL.RequestURI()))
+	}))
+	defer upstream.Close()
+
+	target, _ := url.Parse(upstream.URL + "/api")
+
+	size := 0
+	app := newTestAppInstance()
+	app.Use(func(c *Context) {
+		c.Next()
+		size = c.Response.Size()
+	})
+	app.Proxy("/users/*path", target, ProxyOptions{StripPrefix: "/users"})
+
+	req, _ := http.NewRequest("POST", "/users/1?active=true", nil)
+	req.Host = "bff.example.com"
+	req.Header.Set(RequestIDHeader, "proxy-id")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "POST /api/1?active=true", w.Body.String())
+	assert.Equal(t, len(w.Body.String()), size)
+	assert.Equal(t, "proxy-id", w.Header().Get("X-Upstream-Request-ID"))
+	assert.Equal(t, "bff.example.com", w.Header().Get("X-Upstream-Forwarded-Host"))
+}
+
+func TestRouterProxyTargetSelection(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer upstream.Close()
+
+	target, _ := url.Parse(upstream.URL)
+	unreachable, _ := url.Parse("http://127.0.0.1:1")
+
+	app := newTestAppInstance()
+	app.Proxy("/v2/*path", unreachable, ProxyOptions{
+		Rewrite: func(path string) string {
+			return "/rewritten" + path
+		},
+		Target: func(c *Context) *url.URL {
+			if c.Param("path") == "/unreachable" {
+				return nil
+			}
+			return target
+		},
+	})
+
+	w := performRequest(app, "GET", "/v2/items")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "/rewritten/v2/items", w.Body.String())
+
+	w = performRequest(app, "GET", "/v2/unreachable")
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+}
@@ -0,0 +1,66 @@
+package cucumber
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ErrQueueFull is served when request can not enter the request queue in time
+var ErrQueueFull = errors.New("request queue is full")
+
+// Queue returns a middleware which lets at most maxWaiting requests be handled
+// at once, absorbing bursts instead of overloading handlers
+//
+// Request waits up to timeout for a free slot and is rejected with 429 Too Many
+// Requests when none becomes available, zero timeout rejects it right away.
+// Requests in the queue, waiting or being handled, are reported by App.QueueDepth.
+func Queue(maxWaiting int, timeout time.Duration) HandlerFunc {
+	if maxWaiting <= 0 {
+		panic("request queue size must be positive")
+	}
+	slots := make(chan struct{}, maxWaiting)
+
+	return func(c *Context) {
+		atomic.AddInt64(&c.app.queueDepth, 1)
+		defer atomic.AddInt64(&c.app.queueDepth, -1)
+
+		if !acquireQueueSlot(c, slots, timeout) {
+			c.Abort()
+			c.ServeError(http.StatusTooManyRequests, ErrQueueFull)
+			return
+		}
+		defer func() { <-slots }()
+
+		c.Next()
+	}
+}
+
+// acquireQueueSlot waits up to timeout for a free slot, giving up when request is canceled
+func acquireQueueSlot(c *Context, slots chan struct{}, timeout time.Duration) bool {
+	select {
+	case slots <- struct{}{}:
+		return true
+	default:
+		if timeout <= 0 {
+			return false
+		}
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-c.Request.Context().Done():
+		return false
+	}
+}
+
+// QueueDepth returns number of requests in request queues, waiting or being handled
+func (a *App) QueueDepth() int64 {
+	return atomic.LoadInt64(&a.queueDepth)
+}
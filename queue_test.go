@@ -0,0 +1,57 @@
+package cucumber
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueue(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 3)
+
+	app := newTestAppInstance()
+	app.Use(Queue(2, 20*time.Millisecond))
+	app.GET("/slow", func(c *Context) {
+		started <- struct{}{}
+		<-release
+		c.Status(http.StatusOK)
+	})
+
+	codes := make(chan int, 4)
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- performRequest(app, "GET", "/slow").Code
+		}()
+	}
+	<-started
+	<-started
+	assert.Equal(t, int64(2), app.QueueDepth())
+
+	// overflow waits for timeout and is rejected
+	start := time.Now()
+	w := performRequest(app, "GET", "/slow")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(20*time.Millisecond))
+
+	// waiting request proceeds once slot is free
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		codes <- performRequest(app, "GET", "/slow").Code
+	}()
+	close(release)
+	wg.Wait()
+	close(codes)
+
+	for code := range codes {
+		assert.Equal(t, http.StatusOK, code)
+	}
+	assert.Equal(t, int64(0), app.QueueDepth())
+}
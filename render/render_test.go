@@ -61,9 +61,14 @@ func TestXML(t *testing.T) {
 		"foo": "bar",
 	}
 
-	err := XML{data}.Render(w)
+	err := XML{Data: data}.Render(w)
 	assert.NoError(t, err)
-	assert.Equal(t, "<map><foo>bar</foo></map>", w.Body.String())
+	assert.Equal(t, xml.Header+"<map><foo>bar</foo></map>", w.Body.String())
+
+	w = httptest.NewRecorder()
+	err = XML{Data: data, Indent: "  "}.Render(w)
+	assert.NoError(t, err)
+	assert.Equal(t, xml.Header+"<map>\n  <foo>bar</foo>\n</map>", w.Body.String())
 
 }
 
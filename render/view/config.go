@@ -8,6 +8,7 @@ type Config struct {
 	Ext          string           //template extension
 	Master       string           //template master
 	Partials     []string         //template partial, such as head, foot
+	PartialsRoot string           //partials folder loaded on first render by LazyHTMLEngine
 	Funcs        template.FuncMap //template functions
 	DisableCache bool             //disable cache, debug mode
 	Delims       Delims           //delimeters
@@ -7,6 +7,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -144,3 +145,26 @@ func DefaultFileHandler() FileHandler {
 		return string(data), nil
 	}
 }
+
+// LoadPartials returns names of partial templates found in partialsRoot folder of viewsRoot
+func LoadPartials(viewsRoot, partialsRoot, ext string) ([]string, error) {
+	dirname := path.Join(viewsRoot, partialsRoot)
+	files, err := ioutil.ReadDir(dirname)
+	if err != nil {
+		return nil, err
+	}
+	partials := []string{}
+	for _, f := range files {
+		partial := f.Name()
+		if strings.HasSuffix(partial, ext) {
+			// remove ext from file
+			partial = strings.TrimSuffix(partial, ext)
+			// join file with folder name
+			partial = path.Join(partialsRoot, partial)
+
+			// add to partials
+			partials = append(partials, partial)
+		}
+	}
+	return partials, nil
+}
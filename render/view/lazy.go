@@ -0,0 +1,64 @@
+package view
+
+import (
+	"html/template"
+	"io"
+	"sync"
+
+	"github.com/AjdinHalac/cucumber/log"
+)
+
+// LazyHTMLEngine is html view engine which defers all disk I/O, including
+// partials discovery, until first render
+//
+// Missing templates are logged as warnings at render time.
+type LazyHTMLEngine struct {
+	*HTMLEngine
+	logger       log.Logger
+	partialsOnce sync.Once
+}
+
+// NewLazyHTMLEngine creates lazy HTML view Engine instance, partials are
+// loaded from config.PartialsRoot on first render
+func NewLazyHTMLEngine(config Config, logger log.Logger) *LazyHTMLEngine {
+	e := &LazyHTMLEngine{
+		HTMLEngine: NewHTMLEngine(config),
+		logger:     logger,
+	}
+
+	fileHandler := e.fileHandler
+	e.fileHandler = func(config Config, tplFile string) (string, error) {
+		content, err := fileHandler(config, tplFile)
+		if err != nil {
+			e.warn(err.Error())
+		}
+		return content, err
+	}
+	return e
+}
+
+// Render renders HTML content to output writer, see HTMLEngine.Render
+func (e *LazyHTMLEngine) Render(out io.Writer, name string, data map[string]interface{}, viewFuncs template.FuncMap) error {
+	e.partialsOnce.Do(e.loadPartials)
+	return e.HTMLEngine.Render(out, name, data, viewFuncs)
+}
+
+func (e *LazyHTMLEngine) loadPartials() {
+	if e.config.PartialsRoot == "" {
+		return
+	}
+	partials, err := LoadPartials(e.config.Root, e.config.PartialsRoot, e.config.Ext)
+	if err != nil {
+		e.warn(err.Error())
+		return
+	}
+	e.config.Partials = append(e.config.Partials, partials...)
+}
+
+func (e *LazyHTMLEngine) warn(msg string) {
+	if e.logger != nil {
+		e.logger.Warn(msg)
+		return
+	}
+	log.Warn(msg)
+}
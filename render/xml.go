@@ -7,14 +7,24 @@ import (
 
 var xmlContentType = []string{"application/xml; charset=utf-8"}
 
-// XML renders XML
+// XML renders XML prepended with XML declaration
 type XML struct {
 	Data interface{}
+	// Prefix and Indent pretty-print XML when set, see xml.Encoder.Indent
+	Prefix string
+	Indent string
 }
 
 // Render XML to io.Writer
 func (r XML) Render(out io.Writer) error {
-	return xml.NewEncoder(out).Encode(r.Data)
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(out)
+	if r.Prefix != "" || r.Indent != "" {
+		enc.Indent(r.Prefix, r.Indent)
+	}
+	return enc.Encode(r.Data)
 }
 
 // ContentType returns contentType for renderer
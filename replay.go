@@ -0,0 +1,268 @@
+package cucumber
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/xid"
+)
+
+// ReplayIDHeader carries the entry ID on requests replayed by App.ReplayRequest
+const ReplayIDHeader = "X-Replay-ID"
+
+// replayContextKey marks request context of requests replayed by App.ReplayRequest
+type replayContextKey struct{}
+
+var (
+	// ErrReplayNotFound is returned by App.ReplayRequestFrom when entry is not stored
+	ErrReplayNotFound = errors.New("replay entry not found")
+
+	// ErrReplayStoreNotConfigured is returned by App.ReplayRequest when Options.ReplayStore is not set
+	ErrReplayStoreNotConfigured = errors.New("replay store not configured")
+)
+
+// replayRedactedHeaders are not recorded as they carry credentials
+var replayRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// ReplayEntry is a recorded request and its response
+type ReplayEntry struct {
+	ID        string
+	RequestID string
+	Time      time.Time
+
+	Method        string
+	URL           string
+	RequestHeader http.Header
+	RequestBody   []byte
+
+	Status         int
+	ResponseHeader http.Header
+	ResponseBody   []byte
+
+	// Truncated reports whether request or response body exceeded Options.ReplayBodyLimit
+	Truncated bool
+}
+
+// ReplayFilter selects stored replay entries, zero fields match all entries
+type ReplayFilter struct {
+	ID     string
+	Method string
+	// Path matches entries which URL path starts with it
+	Path   string
+	Status int
+	Since  time.Time
+	// Limit is the maximum number of returned entries
+	Limit int
+}
+
+// Match reports whether entry matches the filter
+func (f ReplayFilter) Match(e ReplayEntry) bool {
+	if f.ID != "" && f.ID != e.ID {
+		return false
+	}
+	if f.Method != "" && f.Method != e.Method {
+		return false
+	}
+	if f.Path != "" && !strings.HasPrefix(e.URL, f.Path) {
+		return false
+	}
+	if f.Status != 0 && f.Status != e.Status {
+		return false
+	}
+	return f.Since.IsZero() || !e.Time.Before(f.Since)
+}
+
+// ReplayStore stores entries recorded by ReplayRecorder
+type ReplayStore interface {
+	Save(entry ReplayEntry) error
+	List(filter ReplayFilter) ([]ReplayEntry, error)
+}
+
+// MemoryReplayStore is a ReplayStore keeping the most recent entries in memory
+type MemoryReplayStore struct {
+	mu      sync.RWMutex
+	entries []ReplayEntry
+	size    int
+}
+
+// NewMemoryReplayStore returns a MemoryReplayStore keeping up to size entries
+func NewMemoryReplayStore(size int) *MemoryReplayStore {
+	return &MemoryReplayStore{size: size}
+}
+
+// Save stores entry, dropping the oldest one when store is full
+func (s *MemoryReplayStore) Save(entry ReplayEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size > 0 && len(s.entries) >= s.size {
+		s.entries = s.entries[1:]
+	}
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+// List returns entries matching filter in recording order
+func (s *MemoryReplayStore) List(filter ReplayFilter) ([]ReplayEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entries := []ReplayEntry{}
+	for _, e := range s.entries {
+		if filter.Limit > 0 && len(entries) >= filter.Limit {
+			break
+		}
+		if filter.Match(e) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// ReplayRecorder returns a middleware that records requests and their responses
+// into store, so they can be replayed with App.ReplayRequestFrom when debugging,
+// or with App.ReplayRequest when store is Options.ReplayStore
+//
+// Credential headers are not recorded and bodies are recorded up to
+// Options.ReplayBodyLimit bytes. Replayed requests are not recorded again.
+func ReplayRecorder(store ReplayStore) HandlerFunc {
+	if store == nil {
+		panic("replay store can not be nil")
+	}
+	return func(c *Context) {
+		if c.Request.Context().Value(replayContextKey{}) != nil {
+			c.Next()
+			return
+		}
+
+		limit := c.app.ReplayBodyLimit
+		if limit < 0 {
+			limit = 0
+		}
+		entry := ReplayEntry{
+			ID:            xid.New().String(),
+			RequestID:     c.RequestID(),
+			Time:          time.Now(),
+			Method:        c.Request.Method,
+			URL:           c.Request.URL.RequestURI(),
+			RequestHeader: redactReplayHeader(c.Request.Header),
+		}
+
+		if c.Request.Body != nil {
+			body, err := ioutil.ReadAll(io.LimitReader(c.Request.Body, int64(limit)+1))
+			if err != nil {
+				c.Abort()
+				c.ServeError(http.StatusBadRequest, err)
+				return
+			}
+			// restore body for downstream handlers
+			c.Request.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), c.Request.Body), c.Request.Body}
+			if len(body) > limit {
+				body = body[:limit]
+				entry.Truncated = true
+			}
+			entry.RequestBody = body
+		}
+
+		writer := &bodyCaptureWriter{ResponseWriter: c.writermem.ResponseWriter, limit: limit}
+		c.writermem.ResponseWriter = writer
+
+		c.Next()
+
+		c.writermem.ResponseWriter = writer.ResponseWriter
+		entry.Status = c.Response.Status()
+		entry.ResponseHeader = redactReplayHeader(c.Response.Header())
+		entry.ResponseBody = writer.body.Bytes()
+		entry.Truncated = entry.Truncated || writer.truncated
+
+		if err := store.Save(entry); err != nil {
+			c.Logger().Error("replay-recorder: " + err.Error())
+		}
+	}
+}
+
+// ReplayRequest replays request recorded by ReplayRecorder into
+// Options.ReplayStore under given id, see ReplayRequestFrom
+func (a *App) ReplayRequest(id string) (*http.Response, error) {
+	if a.ReplayStore == nil {
+		return nil, ErrReplayStoreNotConfigured
+	}
+	return a.ReplayRequestFrom(a.ReplayStore, id)
+}
+
+// ReplayRequestFrom replays request recorded by ReplayRecorder into store
+// under given id against the app and returns its response
+func (a *App) ReplayRequestFrom(store ReplayStore, id string) (*http.Response, error) {
+	entries, err := store.List(ReplayFilter{ID: id, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrReplayNotFound
+	}
+	entry := entries[0]
+
+	req, err := http.NewRequest(entry.Method, entry.URL, bytes.NewReader(entry.RequestBody))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(context.WithValue(req.Context(), replayContextKey{}, entry.ID))
+	req.Header = entry.RequestHeader.Clone()
+	req.Header.Set(ReplayIDHeader, entry.ID)
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+	return w.Result(), nil
+}
+
+func redactReplayHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, name := range replayRedactedHeaders {
+		redacted.Del(name)
+	}
+	return redacted
+}
+
+// bodyCaptureWriter captures response body up to limit
+type bodyCaptureWriter struct {
+	http.ResponseWriter
+	body      bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (w *bodyCaptureWriter) Write(data []byte) (int, error) {
+	if remaining := w.limit - w.body.Len(); remaining < len(data) {
+		if remaining < 0 {
+			remaining = 0
+		}
+		w.body.Write(data[:remaining])
+		w.truncated = true
+	} else {
+		w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyCaptureWriter) Flush() {
+	w.ResponseWriter.(http.Flusher).Flush()
+}
+
+func (w *bodyCaptureWriter) CloseNotify() <-chan bool {
+	return w.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+func (w *bodyCaptureWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
@@ -0,0 +1,146 @@
+package cucumber
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplayRequest(t *testing.T) {
+	store := NewMemoryReplayStore(10)
+	app := newTestAppInstance()
+	app.ReplayStore = store
+
+	calls := 0
+	app.Use(ReplayRecorder(store))
+	app.POST("/orders/:id", func(c *Context) {
+		calls++
+		body, _ := c.GetRawData()
+		c.SetHeader("X-Order", c.Param("id"))
+		c.String(http.StatusCreated, "order "+c.Param("id")+": "+string(body))
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/orders/7?dry=1", strings.NewReader("two pizzas please"))
+	req.Header.Set(RequestIDHeader, "req-1")
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	assert.Equal(t, "order 7: two pizzas please", w.Body.String())
+
+	entries, err := store.List(ReplayFilter{})
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		entry := entries[0]
+		assert.NotEmpty(t, entry.ID)
+		assert.Equal(t, "req-1", entry.RequestID)
+		assert.Equal(t, "/orders/7?dry=1", entry.URL)
+		assert.Empty(t, entry.RequestHeader.Get("Authorization"))
+		assert.Equal(t, "two pizzas please", string(entry.RequestBody))
+		assert.Equal(t, http.StatusCreated, entry.Status)
+		assert.Equal(t, "order 7: two pizzas please", string(entry.ResponseBody))
+		assert.False(t, entry.Truncated)
+	}
+
+	id := entries[0].ID
+	res, err := app.ReplayRequest(id)
+	assert.NoError(t, err)
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, w.Code, res.StatusCode)
+	assert.Equal(t, w.Body.String(), string(body))
+	assert.Equal(t, "7", res.Header.Get("X-Order"))
+	assert.Equal(t, 2, calls)
+
+	// replayed requests are not recorded
+	entries, _ = store.List(ReplayFilter{})
+	assert.Len(t, entries, 1)
+
+	// client supplied replay header does not skip recording
+	req = httptest.NewRequest(http.MethodPost, "/orders/9", strings.NewReader("tea"))
+	req.Header.Set(ReplayIDHeader, id)
+	app.ServeHTTP(httptest.NewRecorder(), req)
+	entries, _ = store.List(ReplayFilter{})
+	assert.Len(t, entries, 2)
+
+	_, err = app.ReplayRequest("unknown")
+	assert.ErrorIs(t, err, ErrReplayNotFound)
+
+	// bodies are truncated to limit, handler still reads whole body
+	app.ReplayBodyLimit = 3
+	req = httptest.NewRequest(http.MethodPost, "/orders/8", strings.NewReader("soup"))
+	req.Header.Set(RequestIDHeader, "req-2")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	assert.Equal(t, "order 8: soup", w.Body.String())
+
+	entries, _ = store.List(ReplayFilter{Path: "/orders/8"})
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "req-2", entries[0].RequestID)
+		assert.Equal(t, "sou", string(entries[0].RequestBody))
+		assert.Equal(t, "ord", string(entries[0].ResponseBody))
+		assert.True(t, entries[0].Truncated)
+	}
+
+	// negative limit records no body
+	app.ReplayBodyLimit = -1
+	req = httptest.NewRequest(http.MethodPost, "/orders/10", strings.NewReader("cake"))
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	assert.Equal(t, "order 10: cake", w.Body.String())
+	entries, _ = store.List(ReplayFilter{Path: "/orders/10"})
+	if assert.Len(t, entries, 1) {
+		assert.Empty(t, entries[0].RequestBody)
+		assert.Empty(t, entries[0].ResponseBody)
+		assert.True(t, entries[0].Truncated)
+	}
+}
+
+func TestReplayFilter(t *testing.T) {
+	store := NewMemoryReplayStore(2)
+	assert.NoError(t, store.Save(ReplayEntry{ID: "1", Method: "GET", URL: "/a", Status: 200}))
+	assert.NoError(t, store.Save(ReplayEntry{ID: "2", Method: "POST", URL: "/a/b", Status: 500}))
+	assert.NoError(t, store.Save(ReplayEntry{ID: "3", Method: "GET", URL: "/b", Status: 500}))
+
+	entries, _ := store.List(ReplayFilter{})
+	assert.Len(t, entries, 2)
+
+	entries, _ = store.List(ReplayFilter{Status: 500, Path: "/a"})
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "2", entries[0].ID)
+	}
+
+	entries, _ = store.List(ReplayFilter{Method: "GET"})
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "3", entries[0].ID)
+	}
+
+	_, err := newTestAppInstance().ReplayRequest("1")
+	assert.ErrorIs(t, err, ErrReplayStoreNotConfigured)
+}
+
+func TestReplayRequestFrom(t *testing.T) {
+	store := NewMemoryReplayStore(10)
+	app := newTestAppInstance()
+	app.Use(ReplayRecorder(store))
+	app.GET("/ping", func(c *Context) {
+		c.String(http.StatusOK, "pong")
+	})
+
+	performRequest(app, http.MethodGet, "/ping")
+	entries, _ := store.List(ReplayFilter{})
+	if assert.Len(t, entries, 1) {
+		res, err := app.ReplayRequestFrom(store, entries[0].ID)
+		assert.NoError(t, err)
+		body, _ := ioutil.ReadAll(res.Body)
+		assert.Equal(t, "pong", string(body))
+	}
+
+	// store is not Options.ReplayStore
+	_, err := app.ReplayRequest(entries[0].ID)
+	assert.ErrorIs(t, err, ErrReplayStoreNotConfigured)
+
+	assert.Panics(t, func() { ReplayRecorder(nil) })
+}
@@ -20,6 +20,9 @@ import (
 // the path that was requested, the duration (time) it took to process the
 // request, the size of the response (and the "human" size), and the status
 // code of the response.
+//
+// Context values stored under Options.RequestLogContextKeys, e.g. authenticated
+// user ID, are logged as well.
 func RequestLogger() HandlerFunc {
 	return func(c *Context) {
 		// check if we should ignore given request
@@ -37,34 +40,120 @@ func RequestLogger() HandlerFunc {
 			guid := xid.New()
 			requestID = guid.String()
 			// add requestID to header
-			c.Request.Header.Add("X-Request-ID", requestID)
+			c.Request.Header.Add(RequestIDHeader, requestID)
 		}
 
-		c.Response.Header().Add("X-Request-ID", requestID)
+		c.Response.Header().Add(RequestIDHeader, requestID)
+
+		// expose request ID to outbound gRPC calls made with request context
+		c.Request = c.Request.WithContext(ContextWithRequestID(c.Request.Context(), requestID))
+
+		c.LogField("request_id", requestID)
 
-		//c.LogField("request_id", requestID)
-		c.LogFields(log.Fields{
-			"request_id": requestID,
-		})
+		var bodyWriter *bodyCaptureWriter
+		if c.app.LogResponseBody {
+			bodyWriter = &bodyCaptureWriter{ResponseWriter: c.writermem.ResponseWriter, limit: c.app.LogResponseBodyLimit}
+			c.writermem.ResponseWriter = bodyWriter
+		}
 
 		//execute next handler in chain
 		c.Next()
 
-		c.LogFields(log.Fields{
-			"app-version": c.app.Version,
-			"status":      c.Response.Status(),
-			"method":      c.Request.Method,
-			"path":        c.Request.URL.String(),
-			"client_ip":   c.ClientIP(),
-			"duration":    time.Since(start).String(),
-			"size":        c.Response.Size(),
-			"human_size":  byteCountDecimal(int64(c.Response.Size())),
-			"err_msg":     strings.Join(c.Errors.Errors(), ","),
-		})
+		duration := time.Since(start)
+
+		event := AccessLogEvent{
+			AppVersion:     c.app.Version,
+			Status:         c.Response.Status(),
+			Method:         c.Request.Method,
+			Path:           c.Request.URL.String(),
+			ClientIP:       c.ClientIP(),
+			DurationMS:     float64(duration) / float64(time.Millisecond),
+			Size:           int64(c.Response.Size()),
+			ErrMsg:         strings.Join(c.Errors.Errors(), ","),
+			OriginalStatus: c.OriginalStatusCode(),
+			Slow:           isSlowRequest(c.app.SlowRequestThreshold, duration),
+		}
+		if c.app.RequestLogHumanSize {
+			event.HumanSize = byteCountDecimal(event.Size)
+		}
+		if bodyWriter != nil {
+			c.writermem.ResponseWriter = bodyWriter.ResponseWriter
+			body := bodyWriter.body.Bytes()
+			if c.app.ResponseScrubber != nil {
+				body = c.app.ResponseScrubber.Scrub(c.Response.Header().Get(ContentTypeHeader), body)
+			}
+			event.ResponseBody = string(body)
+		}
+		event.each(c.LogField)
+		for _, key := range c.app.RequestLogContextKeys {
+			if value, ok := c.Get(key); ok {
+				c.LogField(key, value)
+			}
+		}
+		if event.Slow {
+			c.Logger().Warn("request-logger")
+			return
+		}
 		c.Logger().Info("request-logger")
 	}
 }
 
+// AccessLogEvent is a request logged by RequestLogger
+//
+// Fields are logged with stable names and types, request_id is added to
+// request logger when request starts so it is logged by handlers as well.
+type AccessLogEvent struct {
+	AppVersion string
+	Status     int
+	Method     string
+	Path       string
+	ClientIP   string
+	DurationMS float64
+	Size       int64
+	// HumanSize is logged when Options.RequestLogHumanSize is set
+	HumanSize string
+	ErrMsg    string
+	// ResponseBody is logged when Options.LogResponseBody is set
+	ResponseBody string
+	// OriginalStatus is logged when status code was rewritten
+	OriginalStatus int
+	// Slow is logged when request took longer than Options.SlowRequestThreshold
+	Slow bool
+}
+
+// Fields returns event as structured log fields
+func (e AccessLogEvent) Fields() log.Fields {
+	fields := log.Fields{}
+	e.each(func(key string, value interface{}) {
+		fields[key] = value
+	})
+	return fields
+}
+
+// each calls fn with name and value of every logged field
+func (e AccessLogEvent) each(fn func(key string, value interface{})) {
+	fn("app-version", e.AppVersion)
+	fn("status", e.Status)
+	fn("method", e.Method)
+	fn("path", e.Path)
+	fn("client_ip", e.ClientIP)
+	fn("duration_ms", e.DurationMS)
+	fn("size", e.Size)
+	if e.HumanSize != "" {
+		fn("human_size", e.HumanSize)
+	}
+	fn("err_msg", e.ErrMsg)
+	if e.ResponseBody != "" {
+		fn("response_body", e.ResponseBody)
+	}
+	if e.OriginalStatus != 0 && e.OriginalStatus != e.Status {
+		fn("original_status", e.OriginalStatus)
+	}
+	if e.Slow {
+		fn("slow", true)
+	}
+}
+
 // NewUnaryRequestLogger creates UnaryInterceptor that logs every request
 func NewUnaryRequestLogger(opts Options) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
@@ -97,6 +186,20 @@ func NewUnaryRequestLogger(opts Options) grpc.UnaryServerInterceptor {
 			)
 		}
 
+		// correlate with HTTP request logs of the caller
+		requestID, ok := RequestIDFromContext(ctx)
+		if !ok {
+			requestID, ok = requestIDFromIncomingContext(ctx)
+		}
+		if ok {
+			fl = fl.WithFields(
+				log.Fields{
+					"request_id": requestID,
+				},
+			)
+			ctx = ContextWithRequestID(ctx, requestID)
+		}
+
 		newCtx := log.NewContext(ctx, fl)
 
 		resp, err := handler(newCtx, req)
@@ -104,24 +207,58 @@ func NewUnaryRequestLogger(opts Options) grpc.UnaryServerInterceptor {
 		// extract logger from context as it might have additional fields
 		if l, ok := log.FromContext(newCtx); ok {
 			code := status.Code(err)
+			duration := time.Since(startTime)
 
 			fields := log.Fields{
 				"grpc.code":    code.String(),
-				"grpc.time_ms": durationToMilliseconds(time.Since(startTime)),
+				"grpc.time_ms": durationToMilliseconds(duration),
 			}
 
 			if err != nil {
 				fields["errr"] = err.Error()
 			}
 
+			// handler might not propagate deadline error, so check context as well
+			levelCode := code
+			if d, ok := ctx.Deadline(); ok {
+				if ctx.Err() == context.DeadlineExceeded {
+					fields["grpc.deadline_exceeded"] = true
+					levelCode = codes.DeadlineExceeded
+				} else {
+					fields["grpc.deadline_remaining_ms"] = durationToMilliseconds(time.Until(d))
+				}
+			}
+
+			slow := isSlowRequest(opts.SlowRequestThreshold, duration)
+			if slow {
+				fields["slow"] = true
+			}
+
 			l = l.WithFields(fields)
+			if slow {
+				l = warnLogger{l}
+			}
 
-			logCode(l, code, "finished unary call with code "+code.String())
+			logCode(l, levelCode, "finished unary call with code "+code.String())
 		}
 		return resp, err
 	}
 }
 
+// isSlowRequest reports whether duration exceeds threshold, zero threshold disables the check
+func isSlowRequest(threshold, duration time.Duration) bool {
+	return threshold > 0 && duration > threshold
+}
+
+// warnLogger logs info entries at warn level
+type warnLogger struct {
+	log.Logger
+}
+
+func (l warnLogger) Info(args ...interface{}) {
+	l.Logger.Warn(args...)
+}
+
 func durationToMilliseconds(duration time.Duration) float32 {
 	return float32(duration.Nanoseconds()/1000) / 1000
 }
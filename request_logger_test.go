@@ -0,0 +1,210 @@
+package cucumber
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AjdinHalac/cucumber/log"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+// levelLogger is log.Logger which records level and fields of logged entries
+type levelLogger struct {
+	recordingLogger
+	level   *string
+	last    *log.Fields
+	history *[]log.Fields
+}
+
+func newLevelLogger() *levelLogger {
+	return &levelLogger{recordingLogger: *newRecordingLogger(), level: new(string), last: &log.Fields{}, history: &[]log.Fields{}}
+}
+
+func (l *levelLogger) log(level string) {
+	*l.level = level
+	*l.last = l.fields
+	*l.history = append(*l.history, l.fields)
+}
+
+func (l *levelLogger) Info(args ...interface{})  { l.log("info") }
+func (l *levelLogger) Warn(args ...interface{})  { l.log("warn") }
+func (l *levelLogger) Error(args ...interface{}) { l.log("error") }
+
+func (l *levelLogger) WithFields(fields log.Fields) log.Logger {
+	return &levelLogger{
+		recordingLogger: *l.recordingLogger.WithFields(fields).(*recordingLogger),
+		level:           l.level,
+		last:            l.last,
+		history:         l.history,
+	}
+}
+
+func TestUnaryRequestLoggerDeadline(t *testing.T) {
+	logger := newLevelLogger()
+	interceptor := NewUnaryRequestLogger(Options{Logger: logger})
+	info := &grpc.UnaryServerInfo{FullMethod: "/cucumber.Test/Call"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	_, err := interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "info", *logger.level)
+	assert.NotContains(t, *logger.last, "grpc.deadline_exceeded")
+	assert.Contains(t, *logger.last, "grpc.deadline_remaining_ms")
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _ = interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		<-ctx.Done()
+		return nil, nil
+	})
+	assert.Equal(t, "warn", *logger.level)
+	assert.Equal(t, true, (*logger.last)["grpc.deadline_exceeded"])
+	assert.NotContains(t, *logger.last, "grpc.deadline_remaining_ms")
+
+	_, _ = interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	assert.NotContains(t, *logger.last, "grpc.deadline_exceeded")
+	assert.NotContains(t, *logger.last, "grpc.deadline_remaining_ms")
+}
+
+func TestRequestLoggerContextKeys(t *testing.T) {
+	logger := newLevelLogger()
+
+	opts := NewOptions()
+	opts.UseViewEngine = false
+	opts.UseSession = false
+	opts.UseTranslator = false
+	opts.Logger = logger
+	opts.RequestLogContextKeys = []string{"user_id", "tenant"}
+
+	app := NewWithOptions(opts)
+	app.Use(func(c *Context) {
+		c.Set("user_id", 42)
+		c.Next()
+	})
+	app.GET("/", func(c *Context) {
+		c.Status(http.StatusOK)
+	})
+
+	performRequest(app, "GET", "/")
+
+	assert.Equal(t, "info", *logger.level)
+	assert.Equal(t, 42, (*logger.last)["user_id"])
+	assert.NotContains(t, *logger.last, "tenant")
+}
+
+func TestRequestLoggerEventFields(t *testing.T) {
+	logger := newLevelLogger()
+
+	opts := NewOptions()
+	opts.UseViewEngine = false
+	opts.UseSession = false
+	opts.UseTranslator = false
+	opts.Logger = logger
+
+	app := NewWithOptions(opts)
+	app.GET("/users/:id", func(c *Context) {
+		c.String(http.StatusCreated, "user")
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/users/42?page=1", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	req.RemoteAddr = "10.0.0.1:1234"
+	app.ServeHTTP(httptest.NewRecorder(), req)
+
+	fields := *logger.last
+	assert.Equal(t, "req-1", fields["request_id"])
+	assert.Equal(t, http.StatusCreated, fields["status"])
+	assert.Equal(t, http.MethodGet, fields["method"])
+	assert.Equal(t, "/users/42?page=1", fields["path"])
+	assert.Equal(t, "10.0.0.1", fields["client_ip"])
+	assert.IsType(t, float64(0), fields["duration_ms"])
+	assert.Equal(t, int64(4), fields["size"])
+	assert.Equal(t, "4 B", fields["human_size"])
+	assert.Equal(t, "", fields["err_msg"])
+	assert.NotContains(t, fields, "original_status")
+
+	app.RequestLogHumanSize = false
+	app.ServeHTTP(httptest.NewRecorder(), req)
+	assert.NotContains(t, *logger.last, "human_size")
+
+	event := AccessLogEvent{Status: 200, OriginalStatus: 201, Size: 10, Slow: true}
+	assert.Equal(t, log.Fields{
+		"app-version":     "",
+		"status":          200,
+		"method":          "",
+		"path":            "",
+		"client_ip":       "",
+		"duration_ms":     float64(0),
+		"size":            int64(10),
+		"err_msg":         "",
+		"original_status": 201,
+		"slow":            true,
+	}, event.Fields())
+}
+
+func TestRequestLoggerSlowRequest(t *testing.T) {
+	logger := newLevelLogger()
+
+	opts := NewOptions()
+	opts.UseViewEngine = false
+	opts.UseSession = false
+	opts.UseTranslator = false
+	opts.Logger = logger
+	opts.SlowRequestThreshold = 10 * time.Millisecond
+
+	app := NewWithOptions(opts)
+	app.GET("/fast", func(c *Context) {
+		c.Status(http.StatusOK)
+	})
+	app.GET("/slow", func(c *Context) {
+		time.Sleep(20 * time.Millisecond)
+		c.Status(http.StatusOK)
+	})
+
+	performRequest(app, "GET", "/fast")
+	assert.Equal(t, "info", *logger.level)
+	assert.NotContains(t, *logger.last, "slow")
+
+	performRequest(app, "GET", "/slow")
+	assert.Equal(t, "warn", *logger.level)
+	assert.Equal(t, true, (*logger.last)["slow"])
+
+	interceptor := NewUnaryRequestLogger(opts)
+	info := &grpc.UnaryServerInfo{FullMethod: "/cucumber.Test/Call"}
+	_, _ = interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		time.Sleep(20 * time.Millisecond)
+		return nil, nil
+	})
+	assert.Equal(t, "warn", *logger.level)
+	assert.Equal(t, true, (*logger.last)["slow"])
+}
+
+func BenchmarkRequestLogger(b *testing.B) {
+	opts := NewOptions()
+	opts.Logger = log.New(log.Configuration{})
+	opts.UsePanicRecovery = false
+	app := NewWithOptions(opts)
+	app.GET("/users/:id", func(c *Context) {
+		c.String(http.StatusOK, "user")
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	w := &discardResponseWriter{header: http.Header{}}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		delete(w.header, RequestIDHeader)
+		app.ServeHTTP(w, req)
+	}
+}
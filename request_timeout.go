@@ -0,0 +1,24 @@
+package cucumber
+
+import "reflect"
+
+// noRequestTimeoutPC is the code pointer of NoRequestTimeout
+var noRequestTimeoutPC = reflect.ValueOf(NoRequestTimeout).Pointer()
+
+// NoRequestTimeout is a middleware exempting route from Options.RequestTimeout,
+// e.g. streaming routes
+//
+//	app.GET("/events/:id/stream", cucumber.NoRequestTimeout, streamEvents)
+func NoRequestTimeout(c *Context) {
+	c.Next()
+}
+
+// hasNoRequestTimeout reports whether handlers contain NoRequestTimeout
+func hasNoRequestTimeout(handlers HandlersChain) bool {
+	for _, handler := range handlers {
+		if reflect.ValueOf(handler).Pointer() == noRequestTimeoutPC {
+			return true
+		}
+	}
+	return false
+}
@@ -51,17 +51,38 @@ type Response struct {
 	http.ResponseWriter
 	size   int
 	status int
+
+	// statusSet reports whether status code was set by handler
+	statusSet bool
+
+	// status code before it was rewritten, zero if status is not rewritten
+	originalStatus int
+
+	// hooks executed right before http header is written
+	beforeWriteHeader []func()
 }
 
 func (w *Response) reset(writer http.ResponseWriter) {
 	w.ResponseWriter = writer
 	w.size = noWritten
 	w.status = defaultStatus
+	w.statusSet = false
+	w.originalStatus = 0
+	w.beforeWriteHeader = w.beforeWriteHeader[0:0]
+}
+
+// onBeforeWriteHeader registers hook which is executed right before
+// http header is written, while status and headers can still be changed
+func (w *Response) onBeforeWriteHeader(fn func()) {
+	w.beforeWriteHeader = append(w.beforeWriteHeader, fn)
 }
 
 // WriteHeader sends an HTTP response header with the provided
 // status code.
 func (w *Response) WriteHeader(code int) {
+	if code > 0 {
+		w.statusSet = true
+	}
 	if code > 0 && w.status != code {
 		if w.Written() {
 			fmt.Fprintf(os.Stderr, "[WARNING] Headers were already written. Wanted to override status code %d with %d", w.status, code)
@@ -73,6 +94,9 @@ func (w *Response) WriteHeader(code int) {
 // WriteHeaderNow forces to write the http header (status code + headers).
 func (w *Response) WriteHeaderNow() {
 	if !w.Written() {
+		for _, fn := range w.beforeWriteHeader {
+			fn()
+		}
 		w.size = 0
 		w.ResponseWriter.WriteHeader(w.status)
 	}
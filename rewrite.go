@@ -0,0 +1,86 @@
+package cucumber
+
+import (
+	"net/http"
+	"regexp"
+)
+
+// RewriteRule rewrites or redirects request path matching Pattern
+type RewriteRule struct {
+	// Pattern is matched against request path
+	Pattern *regexp.Regexp
+	// Replacement of matched path, may reference Pattern groups, e.g. $1
+	Replacement string
+	// Redirect issues redirect to replaced path instead of rewriting it
+	Redirect bool
+	// RedirectCode is the redirect status code, defaults to 301
+	RedirectCode int
+}
+
+// Rewrite returns a middleware which applies first rule matching request path
+//
+// It has to run before routing, so it should be registered with App.UseRewrite:
+//
+//	app.UseRewrite(cucumber.RewriteRule{
+//		Pattern:     regexp.MustCompile(`^/old-api/(.*)$`),
+//		Replacement: "/api/v2/$1",
+//	})
+func Rewrite(rules []RewriteRule) HandlerFunc {
+	for _, rule := range rules {
+		if rule.Pattern == nil {
+			panic("rewrite rule pattern can not be nil")
+		}
+	}
+
+	return func(c *Context) {
+		path := c.Request.URL.Path
+		for _, rule := range rules {
+			if !rule.Pattern.MatchString(path) {
+				continue
+			}
+			rewritten := rule.Pattern.ReplaceAllString(path, rule.Replacement)
+
+			if rule.Redirect {
+				code := rule.RedirectCode
+				if code == 0 {
+					code = http.StatusMovedPermanently
+				}
+				location := rewritten
+				if c.Request.URL.RawQuery != "" {
+					location += "?" + c.Request.URL.RawQuery
+				}
+				c.Abort()
+				http.Redirect(c.Response, c.Request, location, code)
+				return
+			}
+
+			c.Request.URL.Path = rewritten
+			c.Request.URL.RawPath = ""
+			break
+		}
+		c.Next()
+	}
+}
+
+// UseRewrite registers rewrite rules applied to request path before routing
+func (a *App) UseRewrite(rules ...RewriteRule) *App {
+	a.preRouting = append(a.preRouting, Rewrite(rules))
+	return a
+}
+
+// handlePreRouting executes middlewares registered to run before routing,
+// it reports whether request is handled by any of them
+func (a *App) handlePreRouting(c *Context) bool {
+	if len(a.preRouting) == 0 {
+		return false
+	}
+	c.handlers = a.preRouting
+	c.Next()
+	if c.IsAborted() || c.Response.Written() {
+		c.writermem.WriteHeaderNow()
+		return true
+	}
+	c.handlers = nil
+	c.index = -1
+	return false
+}
@@ -0,0 +1,38 @@
+package cucumber
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppUseRewrite(t *testing.T) {
+	app := newTestAppInstance()
+	app.UseRewrite(
+		RewriteRule{Pattern: regexp.MustCompile(`^/old-api/(.*)$`), Replacement: "/api/v2/$1"},
+		RewriteRule{Pattern: regexp.MustCompile(`^/legacy$`), Replacement: "/new", Redirect: true},
+	)
+	app.GET("/api/v2/users/:id", func(c *Context) {
+		c.String(http.StatusOK, c.Request.URL.Path+" "+c.Param("id")+" "+c.Query("q"))
+	})
+
+	w := performRequest(app, "GET", "/old-api/users/42?q=cucumber")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "/api/v2/users/42 42 cucumber", w.Body.String())
+
+	w = performRequest(app, "GET", "/legacy?q=cucumber")
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/new?q=cucumber", w.Header().Get("Location"))
+
+	w = performRequest(app, "GET", "/api/v2/users/1")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = performRequest(app, "GET", "/unknown")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	assert.Panics(t, func() {
+		Rewrite([]RewriteRule{{Replacement: "/"}})
+	})
+}
@@ -3,8 +3,10 @@ package cucumber
 // Route represents a request route's specification which
 // contains method and path and its handler.
 type Route struct {
-	Method        string
-	Path          string
+	Method string
+	Path   string
+	// Version is media type version of route registered with HandleVersioned
+	Version       string
 	HandlersChain HandlersChain
 	HandlerName   string
 	HandlerFunc   HandlerFunc
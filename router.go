@@ -11,10 +11,17 @@ import (
 	"net/http"
 	"path"
 	"strings"
+	"sync"
 )
 
 const abortIndex int8 = math.MaxInt8 / 2
 
+// ErrRouteNotFound is returned by Router.Deregister when route is not registered
+var ErrRouteNotFound = errors.New("route not found")
+
+// MethodQuery is HTTP QUERY method (IETF draft)
+const MethodQuery = "QUERY"
+
 // Router is a http.Handler which can be used to dispatch requests to different
 // handler functions via configurable routes
 //
@@ -23,9 +30,32 @@ type Router struct {
 	// routing tree nodes
 	trees map[string]*node
 
+	// mu guards routing trees shared between router groups
+	// against routes registered at runtime
+	mu *sync.RWMutex
+
 	// Handlers represents list of middlewares that will be executed in chain
 	Handlers HandlersChain
 
+	// named middleware stacks shared between router groups
+	stacks map[string][]HandlerFunc
+	// stacks used before they were defined, see UseStack
+	stackRefs *[]*stackRef
+	// deferStacks allows using stacks defined once router is attached
+	deferStacks bool
+
+	// routing tree nodes of media type versioned routes per version
+	versionTrees map[string]map[string]*node
+
+	// routes of domain-based groups
+	domains *domainRoutes
+
+	// path parameter aliases of the group, see ParamAlias
+	paramAliases map[string]string
+
+	// middleware order rules of the group, see ValidateMiddlewareOrder
+	orderRules []OrderRule
+
 	// base path for router
 	basePath string
 
@@ -39,7 +69,15 @@ func NewRouter() *Router {
 		root:     true,
 		basePath: "/",
 		trees:    make(map[string]*node),
+		mu:       &sync.RWMutex{},
+		stacks:   make(map[string][]HandlerFunc),
 		Handlers: nil,
+
+		stackRefs:   &[]*stackRef{},
+		deferStacks: true,
+
+		versionTrees: make(map[string]map[string]*node),
+		domains:      &domainRoutes{trees: make(map[string]map[string]*node)},
 	}
 }
 
@@ -53,17 +91,34 @@ func (r *Router) BasePath() string {
 // You should add all the routes that have common middlewares or the same path prefix.
 // For example, all the routes that use a common middleware for authorization could be grouped.
 func (r *Router) Group(relativePath string, handlers ...HandlerFunc) *Router {
+	assertHandlers(handlers, "group '"+r.calculateAbsolutePath(relativePath)+"'")
+	orderRules := appendOrderRules(r.orderRules, orderRulesOf(handlers))
+	combined := r.combineHandlers(handlers)
+	validateMiddlewareOrder(combined, orderRules)
 	return &Router{
 		root:     false,
 		basePath: r.calculateAbsolutePath(relativePath),
 		trees:    r.trees,
-		Handlers: r.combineHandlers(handlers),
+		mu:       r.mu,
+		stacks:   r.stacks,
+		Handlers: combined,
+
+		stackRefs:   r.stackRefs,
+		deferStacks: r.deferStacks,
+
+		versionTrees: r.versionTrees,
+		domains:      r.domains,
+		paramAliases: r.paramAliases,
+		orderRules:   orderRules,
 	}
 }
 
 // Use appends one or more middlewares onto the Router stack.
 func (r *Router) Use(middleware ...HandlerFunc) {
+	assertHandlers(middleware, "middleware of '"+r.basePath+"'")
 	r.Handlers = append(r.Handlers, middleware...)
+	r.orderRules = appendOrderRules(r.orderRules, orderRulesOf(middleware))
+	validateMiddlewareOrder(r.Handlers, r.orderRules)
 }
 
 // Handle registers a new request handle with the given path and method.
@@ -74,8 +129,15 @@ func (r *Router) Use(middleware ...HandlerFunc) {
 // This function is intended for bulk loading and to allow the usage of less
 // frequently used, non-standardized or custom methods (e.g. for internal
 // communication with a proxy).
+//
+// The last handler is expected to write the response. Route which completes
+// without writing response or setting status code, e.g. route registered only
+// with middlewares, responds with empty 200 OK unless Options.EmptyResponseStatus is set.
 func (r *Router) Handle(method, path string, handlers ...HandlerFunc) {
+	r.handle(r.trees, method, path, handlers)
+}
 
+func (r *Router) handle(trees map[string]*node, method, path string, handlers HandlersChain) {
 	path = r.calculateAbsolutePath(path)
 	if path[0] != '/' {
 		panic("path must begin with '/' in path '" + path + "'")
@@ -89,17 +151,21 @@ func (r *Router) Handle(method, path string, handlers ...HandlerFunc) {
 		panic("there must be at least one handler")
 	}
 
-	if r.trees == nil {
+	assertHandlers(handlers, "route "+method+" '"+path+"'")
+
+	if trees == nil {
 		panic("Router tree not initialized")
 	}
 
-	root := r.trees[method]
+	root := trees[method]
 	if root == nil {
 		root = new(node)
-		r.trees[method] = root
+		trees[method] = root
 	}
 
 	chained := r.combineHandlers(handlers)
+	validateMiddlewareOrder(chained, appendOrderRules(r.orderRules, orderRulesOf(handlers)))
+	chained = r.withParamAliases(chained)
 
 	root.addRoute(path, chained)
 }
@@ -139,6 +205,13 @@ func (r *Router) DELETE(path string, handler ...HandlerFunc) {
 	r.Handle("DELETE", path, handler...)
 }
 
+// QUERY is a shortcut for router.Handle("QUERY", path, handler)
+//
+// QUERY is safe and idempotent like GET, but carries query in request body.
+func (r *Router) QUERY(path string, handler ...HandlerFunc) {
+	r.Handle(MethodQuery, path, handler...)
+}
+
 // Any registers a route that matches all the HTTP methods.
 // GET, POST, PUT, PATCH, HEAD, OPTIONS, DELETE, CONNECT, TRACE.
 func (r *Router) Any(relativePath string, handler ...HandlerFunc) {
@@ -153,11 +226,22 @@ func (r *Router) Any(relativePath string, handler ...HandlerFunc) {
 	r.Handle("TRACE", relativePath, handler...)
 }
 
+// Handler registers http.Handler with the given path and method
+//
+// Use router.Any(path, WrapH(handler)) to match all the HTTP methods.
+func (r *Router) Handler(method, path string, handler http.Handler) {
+	r.Handle(method, path, WrapH(handler))
+}
+
 // Attach another router to current one
 func (r *Router) Attach(prefix string, router *Router) {
-
+	r.attachStacks(router)
 	for _, route := range router.Routes() {
 		path := joinPaths(prefix, route.Path)
+		if route.Version != "" {
+			r.HandleVersioned(route.Method, path, route.Version, route.HandlersChain...)
+			continue
+		}
 		r.Handle(route.Method, path, route.HandlersChain...)
 	}
 }
@@ -210,17 +294,75 @@ func (r *Router) Static(relativePath, root string) {
 // values. The third return value indicates whether a redirection to
 // the same path with an extra / without the trailing slash should be performed.
 func (r *Router) Lookup(method, path string) (HandlersChain, Params, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	if root := r.trees[method]; root != nil {
-		return root.getValue(path)
+		return root.getValue(path, nil)
 	}
 	return nil, nil, false
 }
 
-// Routes returns a slice of registered routes
+// Deregister removes the route registered with the given method and path,
+// e.g. when unloading a plugin registered with App.HotRegister
+//
+// It is safe to call concurrently with request handling.
+func (r *Router) Deregister(method, path string) error {
+	path = r.calculateAbsolutePath(path)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	root := r.trees[method]
+	if root == nil || !root.remove(path) {
+		return fmt.Errorf("%w: %s %s", ErrRouteNotFound, method, path)
+	}
+	if len(root.path) == 0 && len(root.children) == 0 {
+		delete(r.trees, method)
+	}
+	return nil
+}
+
+// maxParams returns the maximum number of params of registered routes
+func (r *Router) maxParams() uint8 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var max uint8
+	for _, root := range r.trees {
+		if root.maxParams > max {
+			max = root.maxParams
+		}
+	}
+	for _, trees := range r.versionTrees {
+		for _, root := range trees {
+			if root.maxParams > max {
+				max = root.maxParams
+			}
+		}
+	}
+	for _, trees := range r.domains.trees {
+		for _, root := range trees {
+			if root.maxParams > max {
+				max = root.maxParams
+			}
+		}
+	}
+	return max
+}
+
+// Routes returns a slice of registered routes, including versioned routes
 func (r *Router) Routes() (routes Routes) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	for method, tree := range r.trees {
 		routes = iterate("", method, routes, tree)
 	}
+	for version, trees := range r.versionTrees {
+		for method, tree := range trees {
+			start := len(routes)
+			routes = iterate("", method, routes, tree)
+			for i := start; i < len(routes); i++ {
+				routes[i].Version = version
+			}
+		}
+	}
 	return routes
 }
 
@@ -238,7 +380,7 @@ func (r *Router) createStaticHandler(relativePath string, fs http.FileSystem) Ha
 		// Check if file exists and/or if we have permission to access it
 		if _, err := fs.Open(file); err != nil {
 			fmt.Println(err)
-			c.ServeError(http.StatusNotFound, errors.New(c.app.Body404))
+			c.ServeError(http.StatusNotFound, errors.New(optionalBody(c.app.Body404, default404Body)))
 			return
 		}
 
@@ -260,40 +402,34 @@ func (r *Router) combineHandlers(handlers HandlersChain) HandlersChain {
 	return mergedHandlers
 }
 
+// assertHandlers panics if any of handlers is nil
+func assertHandlers(handlers HandlersChain, name string) {
+	for i, handler := range handlers {
+		if handler == nil {
+			panic(fmt.Sprintf("nil handler at position %d registered for %s", i, name))
+		}
+	}
+}
+
 func (r *Router) calculateAbsolutePath(relativePath string) string {
 	return joinPaths(r.basePath, relativePath)
 }
 
 func (r *Router) allowed(path, reqMethod string) (allow string) {
-	if path == "*" { // server-wide
-		for method := range r.trees {
-			if method == "OPTIONS" {
-				continue
-			}
-
-			// add request method to list of allowed methods
-			if len(allow) == 0 {
-				allow = method
-			} else {
-				allow += ", " + method
-			}
+	for method, roots := range r.methodRoots() {
+		// Skip the requested method - we already tried this one
+		if method == "OPTIONS" || (path != "*" && method == reqMethod) {
+			continue
+		}
+		if path != "*" && !matchesAny(roots, path) {
+			continue
 		}
-	} else { // specific path
-		for method := range r.trees {
-			// Skip the requested method - we already tried this one
-			if method == reqMethod || method == "OPTIONS" {
-				continue
-			}
 
-			handle, _, _ := r.trees[method].getValue(path)
-			if handle != nil {
-				// add request method to list of allowed methods
-				if len(allow) == 0 {
-					allow = method
-				} else {
-					allow += ", " + method
-				}
-			}
+		// add request method to list of allowed methods
+		if len(allow) == 0 {
+			allow = method
+		} else {
+			allow += ", " + method
 		}
 	}
 	if len(allow) > 0 {
@@ -301,3 +437,27 @@ func (r *Router) allowed(path, reqMethod string) (allow string) {
 	}
 	return
 }
+
+// methodRoots returns tree roots of unversioned and versioned routes per method
+func (r *Router) methodRoots() map[string][]*node {
+	roots := make(map[string][]*node, len(r.trees))
+	for method, root := range r.trees {
+		roots[method] = append(roots[method], root)
+	}
+	for _, trees := range r.versionTrees {
+		for method, root := range trees {
+			roots[method] = append(roots[method], root)
+		}
+	}
+	return roots
+}
+
+// matchesAny reports whether any of trees has route matching path
+func matchesAny(roots []*node, path string) bool {
+	for _, root := range roots {
+		if handle, _, _ := root.getValue(path, nil); handle != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package cucumber
+
+import "fmt"
+
+// RouterStats describes size of router radix trees
+type RouterStats struct {
+	// Nodes is the number of tree nodes of all methods
+	Nodes int
+	// MaxDepth is the depth of the deepest node, tree roots have depth 1
+	MaxDepth int
+	// Routes is the number of routes per method
+	Routes map[string]int
+}
+
+// TotalRoutes returns the number of routes of all methods
+func (s RouterStats) TotalRoutes() (total int) {
+	for _, n := range s.Routes {
+		total += n
+	}
+	return total
+}
+
+// Stats returns size of routing trees shared by router and its groups
+func (r *Router) Stats() RouterStats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	stats := RouterStats{Routes: make(map[string]int, len(r.trees))}
+	for method, root := range r.trees {
+		stats.Routes[method] = root.stats(&stats, 1)
+	}
+	return stats
+}
+
+// stats adds node subtree to stats and returns the number of its routes
+func (n *node) stats(stats *RouterStats, depth int) (routes int) {
+	stats.Nodes++
+	if depth > stats.MaxDepth {
+		stats.MaxDepth = depth
+	}
+	if n.handler != nil {
+		routes++
+	}
+	for _, child := range n.children {
+		routes += child.stats(stats, depth+1)
+	}
+	return routes
+}
+
+// logRouterStats logs size of routing trees in development
+func (a *App) logRouterStats() {
+	if a.Env != "development" {
+		return
+	}
+	stats := a.router.Stats()
+	a.Logger.Debug(fmt.Sprintf("Router has %d routes in %d nodes with max depth %d",
+		stats.TotalRoutes(), stats.Nodes, stats.MaxDepth))
+}
@@ -0,0 +1,25 @@
+package cucumber
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterStats(t *testing.T) {
+	router := NewRouter()
+	handler := func(c *Context) {}
+	router.GET("/", handler)
+	router.GET("/users", handler)
+	router.Group("/users").GET("/:id", handler)
+	router.POST("/users", handler)
+
+	stats := router.Stats()
+	assert.Equal(t, map[string]int{"GET": 3, "POST": 1}, stats.Routes)
+	assert.Equal(t, 4, stats.TotalRoutes())
+	// GET: "/" -> "users" -> "/" -> ":id", POST: "/users"
+	assert.Equal(t, 5, stats.Nodes)
+	assert.Equal(t, 4, stats.MaxDepth)
+
+	assert.Equal(t, RouterStats{Routes: map[string]int{}}, NewRouter().Stats())
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -33,6 +34,7 @@ func TestRouterGroupBasicHandle(t *testing.T) {
 	performRequestInGroup(t, "DELETE")
 	performRequestInGroup(t, "HEAD")
 	performRequestInGroup(t, "OPTIONS")
+	performRequestInGroup(t, "QUERY")
 }
 
 func TestRouterGroupInvalidStatic(t *testing.T) {
@@ -59,19 +61,41 @@ func TestRouterGroupInvalidStaticFile(t *testing.T) {
 
 func TestRouterGroupTooManyHandlers(t *testing.T) {
 	router := NewRouter()
+	handler := func(c *Context) {}
+
 	middlewares1 := make([]HandlerFunc, 40)
+	for i := range middlewares1 {
+		middlewares1[i] = handler
+	}
 	router.Use(middlewares1...)
 
 	middlewares2 := make([]HandlerFunc, 26)
+	for i := range middlewares2 {
+		middlewares2[i] = handler
+	}
 	router.Use(middlewares2...)
 
-	handler := func(c *Context) {}
-
 	assert.Panics(t, func() {
 		router.GET("/", handler)
 	})
 }
 
+func TestRouterGroupNilHandler(t *testing.T) {
+	router := NewRouter()
+	handler := func(c *Context) {}
+	var middleware HandlerFunc
+
+	assert.PanicsWithValue(t, "nil handler at position 0 registered for route GET '/users'", func() {
+		router.GET("/users", middleware, handler)
+	})
+	assert.PanicsWithValue(t, "nil handler at position 1 registered for group '/admin'", func() {
+		router.Group("/admin", handler, middleware)
+	})
+	assert.PanicsWithValue(t, "nil handler at position 0 registered for middleware of '/'", func() {
+		router.Use(middleware)
+	})
+}
+
 func TestRouterGroupBadMethod(t *testing.T) {
 	router := NewRouter()
 	assert.Panics(t, func() {
@@ -274,6 +298,9 @@ func performRequestInGroup(t *testing.T, method string) {
 	case "OPTIONS":
 		v1.OPTIONS("/test", handler)
 		login.OPTIONS("/test", handler)
+	case "QUERY":
+		v1.QUERY("/test", handler)
+		login.QUERY("/test", handler)
 	default:
 		panic("unknown method")
 	}
@@ -287,3 +314,323 @@ func performRequestInGroup(t *testing.T, method string) {
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 	assert.Equal(t, "the method was "+method+" and index 1", w.Body.String())
 }
+
+func TestRouterMiddlewareStack(t *testing.T) {
+	signature := ""
+	app := newTestAppInstance()
+	app.DefineStack("web", func(c *Context) {
+		signature += "A"
+	}, func(c *Context) {
+		signature += "B"
+	})
+
+	group := app.Router().Group("/admin", Stack(func(c *Context) {
+		signature += "C"
+	})...)
+	group.UseStack("web")
+	group.GET("/", func(c *Context) {
+		signature += "D"
+	})
+	// RUN
+	w := performRequest(app, "GET", "/admin/")
+
+	// TEST
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "CABD", signature)
+	assert.Len(t, app.Stack("web"), 2)
+	assert.Panics(t, func() {
+		app.UseStack("undefined")
+	})
+
+	// returned stack is a copy
+	app.Stack("web")[0] = nil
+	assert.NotNil(t, app.Stack("web")[0])
+}
+
+type stackController struct {
+	signature *string
+}
+
+func (ctrl *stackController) Prefix() string {
+	return "/dashboard"
+}
+
+func (ctrl *stackController) Routes() *Router {
+	r := NewRouter()
+	r.Use(func(c *Context) {
+		*ctrl.signature += "A"
+	})
+	r.UseStack("web")
+	r.GET("/", func(c *Context) {
+		*ctrl.signature += "D"
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func TestRouterMiddlewareStackInController(t *testing.T) {
+	signature := ""
+	app := newTestAppInstance()
+	app.ControllerPackage = "cucumber"
+	app.DefineStack("web", func(c *Context) {
+		signature += "B"
+	}, func(c *Context) {
+		signature += "C"
+	})
+	app.RegisterController(&stackController{signature: &signature})
+
+	w := performRequest(app, "GET", "/dashboard/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "ABCD", signature)
+
+	// stacks are resolved when controller is registered
+	assert.Panics(t, func() {
+		app := newTestAppInstance()
+		app.ControllerPackage = "cucumber"
+		app.RegisterController(&stackController{signature: &signature})
+	})
+}
+
+func TestRouterHTTPHandler(t *testing.T) {
+	app := newTestAppInstance()
+	app.Handler("GET", "/handler", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("created"))
+	}))
+
+	size := 0
+	app.Router().Group("/any", func(c *Context) {
+		c.Next()
+		size = c.Response.Size()
+	}).Any("/", WrapF(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(r.Method))
+	}))
+
+	w := performRequest(app, "GET", "/handler")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "created", w.Body.String())
+
+	w = performRequest(app, "PUT", "/any/")
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "PUT", w.Body.String())
+	assert.Equal(t, 3, size)
+}
+
+func TestRouterQueryMethod(t *testing.T) {
+	opts := NewOptions()
+	opts.UseViewEngine = false
+	opts.UseRequestLogger = false
+	opts.UseSession = false
+	opts.UseTranslator = false
+	opts.HandleMethodNotAllowed = true
+
+	app := NewWithOptions(opts)
+	app.QUERY("/search", func(c *Context) {
+		var query struct {
+			Term string `json:"term"`
+		}
+		if err := c.BindJSON(&query); err != nil {
+			return
+		}
+		c.String(http.StatusOK, query.Term)
+	})
+
+	req, _ := http.NewRequest(MethodQuery, "/search", strings.NewReader(`{"term":"cucumber"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "cucumber", w.Body.String())
+
+	w = performRequest(app, "GET", "/search")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "QUERY, OPTIONS", app.Router().allowed("/search", "GET"))
+}
+
+func TestRouterMiddlewareOnlyRoute(t *testing.T) {
+	middleware := func(c *Context) {
+		c.Next()
+	}
+
+	// route without terminal handler responds with empty 200 OK by default
+	app := newTestAppInstance()
+	app.GET("/", middleware, middleware)
+	app.GET("/status", middleware, func(c *Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := performRequest(app, "GET", "/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	app.EmptyResponseStatus = http.StatusNotImplemented
+
+	w = performRequest(app, "GET", "/")
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+	assert.Equal(t, http.StatusText(http.StatusNotImplemented), w.Body.String())
+
+	w = performRequest(app, "GET", "/status")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRouterBindURI(t *testing.T) {
+	type postURI struct {
+		ID     int `uri:"id" binding:"required"`
+		PostID int `uri:"postId" binding:"required"`
+	}
+
+	var bound postURI
+	app := newTestAppInstance()
+	app.GET("/users/:id/posts/:postId", func(c *Context) {
+		if err := c.BindURI(&bound); err != nil {
+			c.String(http.StatusNotFound, err.Error())
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	app.GET("/comments/:id/posts/:postId", func(c *Context) {
+		if !c.MustBindURI(&bound) {
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	w := performRequest(app, "GET", "/users/1/posts/42")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, postURI{ID: 1, PostID: 42}, bound)
+
+	// BindURI does not write response
+	w = performRequest(app, "GET", "/users/1/posts/latest")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = performRequest(app, "GET", "/comments/1/posts/latest")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRouterBindAll(t *testing.T) {
+	type updatePost struct {
+		ID     int    `uri:"id" json:"id" binding:"required"`
+		Draft  bool   `form:"draft" json:"draft"`
+		Title  string `json:"title" form:"-" binding:"required"`
+		Source string `uri:"-" form:"source" json:"source"`
+	}
+
+	var bound updatePost
+	app := newTestAppInstance()
+	app.PUT("/posts/:id", func(c *Context) {
+		bound = updatePost{}
+		if err := c.BindAll(&bound); err != nil {
+			c.ServeError(http.StatusBadRequest, err)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest("PUT", "/posts/7?draft=true&source=query", strings.NewReader(`{"id":1,"title":"cucumber","source":"body"}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, updatePost{ID: 7, Draft: true, Title: "cucumber", Source: "query"}, bound)
+
+	// title is required, but only present in body
+	w = performRequest(app, "PUT", "/posts/7?draft=true")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRouterMustBind(t *testing.T) {
+	type order struct {
+		Item     string `json:"item" xml:"item" form:"item" binding:"required"`
+		Quantity int    `json:"quantity" xml:"quantity" form:"quantity"`
+	}
+
+	var bound order
+	executed := false
+	app := newTestAppInstance()
+	app.POST("/orders/json", func(c *Context) {
+		if !c.MustBindJSON(&bound) {
+			return
+		}
+		executed = true
+		c.Status(http.StatusCreated)
+	})
+	app.POST("/orders/xml", func(c *Context) {
+		if !c.MustBindXML(&bound) {
+			return
+		}
+		c.Status(http.StatusCreated)
+	})
+	app.POST("/orders/form", func(c *Context) {
+		bound = order{}
+		if !c.MustBindForm(&bound) {
+			return
+		}
+		c.Status(http.StatusCreated)
+	}, func(c *Context) {
+		executed = true
+	})
+
+	post := func(path, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("/orders/json", "application/json", `{"item":"cucumber",`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.False(t, executed)
+
+	w = post("/orders/json", "application/json", `{"item":"cucumber","quantity":2}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, order{Item: "cucumber", Quantity: 2}, bound)
+	assert.True(t, executed)
+
+	w = post("/orders/xml", "application/xml", `<order><item>`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = post("/orders/xml", "application/xml", `<order><item>tomato</item></order>`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "tomato", bound.Item)
+
+	// chain is aborted on failure
+	executed = false
+	w = post("/orders/form", "application/x-www-form-urlencoded", "quantity=3")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.False(t, executed)
+
+	w = post("/orders/form", "application/x-www-form-urlencoded", "item=onion&quantity=3")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, order{Item: "onion", Quantity: 3}, bound)
+	assert.True(t, executed)
+}
+
+// discardResponseWriter is a http.ResponseWriter discarding response, used in benchmarks
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header         { return w.header }
+func (w *discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardResponseWriter) WriteHeader(code int)        {}
+
+func BenchmarkRouterParams(b *testing.B) {
+	opts := NewOptions()
+	opts.UseRequestLogger = false
+	opts.UsePanicRecovery = false
+	app := NewWithOptions(opts)
+	app.GET("/users/:id/orders/:order", func(c *Context) {})
+
+	req, _ := http.NewRequest(http.MethodGet, "/users/42/orders/7", nil)
+	w := &discardResponseWriter{header: http.Header{}}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		app.ServeHTTP(w, req)
+	}
+}
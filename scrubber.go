@@ -0,0 +1,104 @@
+package cucumber
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// ResponseScrubber redacts sensitive data from response body before it is logged,
+// see Options.LogResponseBody
+type ResponseScrubber interface {
+	Scrub(contentType string, body []byte) []byte
+}
+
+// ResponseScrubberFunc is an adapter to allow the use of ordinary functions as ResponseScrubber
+type ResponseScrubberFunc func(contentType string, body []byte) []byte
+
+// Scrub calls f(contentType, body)
+func (f ResponseScrubberFunc) Scrub(contentType string, body []byte) []byte {
+	return f(contentType, body)
+}
+
+// regexScrubber replaces matches of patterns with replacement
+type regexScrubber struct {
+	patterns    map[string]*regexp.Regexp
+	replacement string
+}
+
+// RegexScrubber returns ResponseScrubber replacing matches of patterns with replacement
+//
+// In JSON bodies pattern is applied to string values of fields named by its key,
+// at any depth. In other bodies all patterns are applied to the whole body.
+// When pattern has groups only matched groups are replaced, otherwise the whole match.
+//
+//	cucumber.RegexScrubber(map[string]*regexp.Regexp{
+//		"password": regexp.MustCompile(`.+`),
+//	}, "[REDACTED]")
+func RegexScrubber(patterns map[string]*regexp.Regexp, replacement string) ResponseScrubber {
+	return &regexScrubber{patterns: patterns, replacement: replacement}
+}
+
+// Scrub redacts body
+func (s *regexScrubber) Scrub(contentType string, body []byte) []byte {
+	if strings.Contains(contentType, "json") {
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		var data interface{}
+		if err := decoder.Decode(&data); err == nil {
+			if scrubbed, err := json.Marshal(s.scrubJSON(data)); err == nil {
+				return scrubbed
+			}
+		}
+	}
+
+	text := string(body)
+	for _, pattern := range s.patterns {
+		text = replaceGroups(pattern, text, s.replacement)
+	}
+	return []byte(text)
+}
+
+func (s *regexScrubber) scrubJSON(data interface{}) interface{} {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if str, ok := value.(string); ok {
+				if pattern, ok := s.patterns[key]; ok {
+					v[key] = replaceGroups(pattern, str, s.replacement)
+				}
+				continue
+			}
+			v[key] = s.scrubJSON(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = s.scrubJSON(value)
+		}
+	}
+	return data
+}
+
+// replaceGroups replaces groups matched by pattern, or whole matches when it has no groups
+func replaceGroups(pattern *regexp.Regexp, s, replacement string) string {
+	if pattern.NumSubexp() == 0 {
+		return pattern.ReplaceAllLiteralString(s, replacement)
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, match := range pattern.FindAllStringSubmatchIndex(s, -1) {
+		for g := 1; g < len(match)/2; g++ {
+			start, end := match[2*g], match[2*g+1]
+			if start < last {
+				continue
+			}
+			b.WriteString(s[last:start])
+			b.WriteString(replacement)
+			last = end
+		}
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
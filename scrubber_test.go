@@ -0,0 +1,62 @@
+package cucumber
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestLoggerResponseScrubber(t *testing.T) {
+	logger := newLevelLogger()
+
+	opts := NewOptions()
+	opts.Logger = logger
+	opts.LogResponseBody = true
+	opts.ResponseScrubber = RegexScrubber(map[string]*regexp.Regexp{
+		"password": regexp.MustCompile(`.+`),
+	}, "[REDACTED]")
+
+	app := NewWithOptions(opts)
+	app.GET("/users/:id", func(c *Context) {
+		c.JSON(http.StatusOK, map[string]interface{}{
+			"name":     "alice",
+			"password": "hunter2",
+			"devices":  []interface{}{map[string]string{"password": "1234"}},
+		})
+	})
+
+	w := performRequest(app, http.MethodGet, "/users/1")
+	assert.Contains(t, w.Body.String(), "hunter2")
+
+	body, _ := (*logger.last)["response_body"].(string)
+	assert.JSONEq(t, `{"name":"alice","password":"[REDACTED]","devices":[{"password":"[REDACTED]"}]}`, body)
+	assert.NotContains(t, body, "hunter2")
+
+	// body is logged up to limit
+	app.ResponseScrubber = nil
+	app.LogResponseBodyLimit = 8
+	performRequest(app, http.MethodGet, "/users/1")
+	assert.Len(t, (*logger.last)["response_body"], 8)
+
+	// negative limit logs no body
+	app.LogResponseBodyLimit = -1
+	w = performRequest(app, http.MethodGet, "/users/1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, (*logger.last)["response_body"])
+}
+
+func TestRegexScrubber(t *testing.T) {
+	scrubber := RegexScrubber(map[string]*regexp.Regexp{
+		"card":  regexp.MustCompile(`\d{12}(\d{4})`),
+		"token": regexp.MustCompile(`token=(\w+)`),
+	}, "***")
+
+	assert.JSONEq(t, `{"card":"123456789012***","amount":10}`,
+		string(scrubber.Scrub("application/json", []byte(`{"card":"1234567890123456","amount":10}`))))
+	assert.Equal(t, "redirect to /cb?token=***&state=1",
+		string(scrubber.Scrub("text/plain", []byte("redirect to /cb?token=abc123&state=1"))))
+	// invalid JSON is scrubbed as text
+	assert.Equal(t, `{"token=***"`, string(scrubber.Scrub("application/json", []byte(`{"token=secret"`))))
+}
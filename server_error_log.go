@@ -0,0 +1,31 @@
+package cucumber
+
+import (
+	stdlog "log"
+	"strings"
+
+	"github.com/AjdinHalac/cucumber/log"
+)
+
+// serverErrorLog returns logger for http.Server.ErrorLog writing server
+// level errors (TLS handshakes, malformed requests, handler panics) to app logger
+func (a *App) serverErrorLog() *stdlog.Logger {
+	return stdlog.New(serverErrorWriter{logger: a.Logger}, "", 0)
+}
+
+// serverErrorWriter logs lines written by http.Server
+type serverErrorWriter struct {
+	logger log.Logger
+}
+
+func (w serverErrorWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimSpace(string(p))
+	logger := w.logger.WithFields(log.Fields{"source": "http.server"})
+	// errors caused by misbehaving clients are not server failures
+	if strings.Contains(msg, "TLS handshake error") || strings.Contains(msg, "URL query contains semicolon") {
+		logger.Warn(msg)
+	} else {
+		logger.Error(msg)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,34 @@
+package cucumber
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AjdinHalac/cucumber/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerErrorLog(t *testing.T) {
+	app := newTestAppInstance()
+	logger := newLevelLogger()
+	app.Logger = logger
+
+	// plain HTTP request to TLS server fails handshake
+	srv := httptest.NewUnstartedServer(app)
+	srv.Config.ErrorLog = app.serverErrorLog()
+	srv.StartTLS()
+	res, err := http.Get(srv.URL)
+	if err == nil {
+		ioutil.ReadAll(res.Body)
+		res.Body.Close()
+	}
+	srv.Close()
+
+	assert.Equal(t, "warn", *logger.level)
+	assert.Equal(t, log.Fields{"source": "http.server"}, *logger.last)
+
+	app.serverErrorLog().Print("http: Accept error: too many open files")
+	assert.Equal(t, "error", *logger.level)
+}
@@ -0,0 +1,111 @@
+package cucumber
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AjdinHalac/cucumber/render"
+)
+
+// ServerTimingKey is the context key holding timing recorder installed by ServerTiming
+const ServerTimingKey = "serverTiming"
+
+// serverTiming records phases of a request reported in Server-Timing header
+type serverTiming struct {
+	mu     sync.Mutex
+	start  time.Time
+	phases []*timingPhase
+}
+
+type timingPhase struct {
+	name        string
+	description string
+	dur         time.Duration
+	done        bool
+}
+
+// header returns Server-Timing header value of finished phases and total request time
+func (t *serverTiming) header() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	metrics := make([]string, 0, len(t.phases)+1)
+	for _, p := range t.phases {
+		if p.done {
+			metrics = append(metrics, formatTimingMetric(p.name, p.description, p.dur))
+		}
+	}
+	metrics = append(metrics, formatTimingMetric("total", "", time.Since(t.start)))
+	return strings.Join(metrics, ", ")
+}
+
+func formatTimingMetric(name, description string, dur time.Duration) string {
+	metric := name
+	if description != "" {
+		metric += fmt.Sprintf(";desc=%q", description)
+	}
+	return metric + fmt.Sprintf(";dur=%.2f", float64(dur)/float64(time.Millisecond))
+}
+
+// ServerTiming returns a middleware that reports request phases recorded
+// with Context.TimingStart in Server-Timing header (W3C Server Timing)
+//
+// Header is set right before response header is written and contains
+// finished phases and "total" time spent handling request. Time spent
+// rendering HTML views is recorded as "template" phase.
+func ServerTiming() HandlerFunc {
+	return func(c *Context) {
+		timing := &serverTiming{start: time.Now()}
+		c.Set(ServerTimingKey, timing)
+		c.writermem.onBeforeWriteHeader(func() {
+			c.Response.Header().Set("Server-Timing", timing.header())
+		})
+		c.Next()
+	}
+}
+
+// TimingStart starts timing request phase with given name and optional
+// description, reported by ServerTiming middleware once returned function
+// is called
+//
+//	stop := c.TimingStart("db", "load orders")
+//	orders, err := repo.Orders(ctx)
+//	stop()
+//
+// It is no-op when ServerTiming middleware is not used.
+func (c *Context) TimingStart(name string, description ...string) func() {
+	timing, ok := c.Keys[ServerTimingKey].(*serverTiming)
+	if !ok {
+		return func() {}
+	}
+
+	phase := &timingPhase{name: name, description: strings.Join(description, " ")}
+	timing.mu.Lock()
+	timing.phases = append(timing.phases, phase)
+	timing.mu.Unlock()
+
+	start := time.Now()
+	return func() {
+		timing.mu.Lock()
+		defer timing.mu.Unlock()
+		if !phase.done {
+			phase.dur = time.Since(start)
+			phase.done = true
+		}
+	}
+}
+
+// timedRenderer records time spent rendering as request phase
+type timedRenderer struct {
+	render.Renderer
+	c           *Context
+	name        string
+	description string
+}
+
+func (r timedRenderer) Render(out io.Writer) error {
+	defer r.c.TimingStart(r.name, r.description)()
+	return r.Renderer.Render(out)
+}
@@ -0,0 +1,60 @@
+package cucumber
+
+import (
+	"html/template"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// slowViewEngine renders view name after a delay
+type slowViewEngine struct{}
+
+func (slowViewEngine) Render(out io.Writer, name string, data map[string]interface{}, viewFuncs template.FuncMap) error {
+	time.Sleep(time.Millisecond)
+	_, err := io.WriteString(out, name)
+	return err
+}
+
+func (slowViewEngine) SetViewHelpers(viewFuncs template.FuncMap) {}
+
+func TestServerTiming(t *testing.T) {
+	app := newTestAppInstance()
+	app.ViewEngine = slowViewEngine{}
+	app.Use(ServerTiming())
+	app.GET("/orders", func(c *Context) {
+		stop := c.TimingStart("custom")
+		time.Sleep(time.Millisecond)
+		stop()
+		stop()
+		// phases not finished before response is written are not reported
+		c.TimingStart("pending", "still running")
+		c.String(http.StatusOK, "orders")
+	})
+	app.GET("/view", func(c *Context) {
+		c.HTML(http.StatusOK, "orders/index", nil)
+	})
+
+	w := performRequest(app, http.MethodGet, "/orders")
+	assert.Equal(t, "orders", w.Body.String())
+	assert.Regexp(t, `^custom;dur=[0-9]+\.[0-9]{2}, total;dur=[0-9]+\.[0-9]{2}$`, w.Header().Get("Server-Timing"))
+
+	w = performRequest(app, http.MethodGet, "/view")
+	assert.Equal(t, "orders/index", w.Body.String())
+	assert.Regexp(t, `^template;desc="orders/index";dur=[0-9.]+, total;dur=[0-9.]+$`, w.Header().Get("Server-Timing"))
+}
+
+func TestServerTimingDisabled(t *testing.T) {
+	app := newTestAppInstance()
+	app.GET("/", func(c *Context) {
+		c.TimingStart("custom")()
+		c.Status(http.StatusNoContent)
+	})
+
+	w := performRequest(app, http.MethodGet, "/")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Header().Get("Server-Timing"))
+}
@@ -4,3 +4,10 @@ package cucumber
 type Service interface {
 	Service()
 }
+
+// ServiceGroup bundles related services registered together with app#RegisterServiceGroup
+//
+// Group implementing ControllerRouter has its routes attached to app router as well.
+type ServiceGroup interface {
+	Services() []interface{}
+}
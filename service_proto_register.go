@@ -1,8 +1,48 @@
 package cucumber
 
-import "google.golang.org/grpc"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"google.golang.org/grpc"
+)
 
 // ServiceProtoRegister allows to register Proto Buffer Server implementation to GRPC server
 type ServiceProtoRegister interface {
 	RegisterProtoServer(*grpc.Server)
 }
+
+// ErrGRPCServiceDuplicate is returned by RegisterServiceHandlerE when
+// proto service is already registered
+var ErrGRPCServiceDuplicate = errors.New("gRPC service is already registered")
+
+// trackProtoServices records proto services registered by service and fails
+// when any of them is already registered, which gRPC reports without naming
+// the conflicting implementations
+func (a *App) trackProtoServices(service ServiceProtoRegister) error {
+	// registering to a throwaway server reveals service names
+	probe := grpc.NewServer()
+	defer probe.Stop()
+	service.RegisterProtoServer(probe)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.protoServices == nil {
+		a.protoServices = make(map[string]reflect.Type)
+	}
+	registered := a.server.GetServiceInfo()
+	typ := reflect.TypeOf(service)
+	for name := range probe.GetServiceInfo() {
+		if prev, ok := a.protoServices[name]; ok {
+			return fmt.Errorf("%w: %s of %s by %s", ErrGRPCServiceDuplicate, name, typ, prev)
+		}
+		if _, ok := registered[name]; ok {
+			return fmt.Errorf("%w: %s of %s", ErrGRPCServiceDuplicate, name, typ)
+		}
+	}
+	for name := range probe.GetServiceInfo() {
+		a.protoServices[name] = typ
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package cucumber
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/health"
+)
+
+// moduleHealthService registers the same proto service as healthService
+type moduleHealthService struct {
+	*healthService
+}
+
+func TestRegisterServiceHandlerDuplicate(t *testing.T) {
+	app := newTestAppInstance()
+	app.RegisterServiceHandler(&healthService{health.NewServer()})
+
+	err := app.RegisterServiceHandlerE(&moduleHealthService{&healthService{health.NewServer()}})
+	assert.ErrorIs(t, err, ErrGRPCServiceDuplicate)
+	assert.EqualError(t, err,
+		"gRPC service is already registered: grpc.health.v1.Health of *cucumber.moduleHealthService by *cucumber.healthService")
+
+	assert.PanicsWithValue(t, err.Error(),
+		func() { app.RegisterServiceHandler(&moduleHealthService{&healthService{health.NewServer()}}) })
+}
@@ -0,0 +1,34 @@
+package cucumber
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/AjdinHalac/cucumber/log"
+)
+
+// MinSessionSecretLength is the minimum length of SessionSecret not reported as weak
+const MinSessionSecretLength = 32
+
+// GenerateSessionSecret returns a random hex encoded secret suitable for SessionSecret
+func GenerateSessionSecret() string {
+	b := make([]byte, MinSessionSecretLength)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("can not generate session secret: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// checkSessionSecret reports empty and weak session secret, empty secret
+// makes sessions forgeable so it panics outside of development env
+func checkSessionSecret(opts Options, logger log.Logger) {
+	switch {
+	case opts.SessionSecret == "" && opts.Env != "development":
+		panic("SessionSecret configuration key is not set. Your sessions are not safe!")
+	case opts.SessionSecret == "":
+		logger.Warn("SessionSecret configuration key is not set. Your sessions are not safe!")
+	case len(opts.SessionSecret) < MinSessionSecretLength:
+		logger.Warn(fmt.Sprintf("SessionSecret is shorter than %d bytes. Your sessions are not safe, use GenerateSessionSecret to create strong secret!", MinSessionSecretLength))
+	}
+}
@@ -0,0 +1,43 @@
+package cucumber
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateSessionSecret(t *testing.T) {
+	secret := GenerateSessionSecret()
+	assert.GreaterOrEqual(t, len(secret), MinSessionSecretLength)
+	assert.NotEqual(t, secret, GenerateSessionSecret())
+}
+
+func TestCheckSessionSecret(t *testing.T) {
+	tt := []struct {
+		Name   string
+		Env    string
+		Secret string
+		Level  string
+	}{
+		{Name: "strong", Env: "production", Secret: GenerateSessionSecret(), Level: ""},
+		{Name: "weak", Env: "production", Secret: "s3cret", Level: "warn"},
+		{Name: "empty in development", Env: "development", Secret: "", Level: "warn"},
+	}
+
+	for _, tc := range tt {
+		opts := NewOptions()
+		opts.Env = tc.Env
+		opts.SessionSecret = tc.Secret
+		logger := newLevelLogger()
+		checkSessionSecret(opts, logger)
+		assert.Equal(t, tc.Level, *logger.level, tc.Name)
+	}
+
+	for _, env := range []string{"production", "staging", "test"} {
+		opts := NewOptions()
+		opts.Env = env
+		assert.PanicsWithValue(t, "SessionSecret configuration key is not set. Your sessions are not safe!", func() {
+			checkSessionSecret(opts, newLevelLogger())
+		}, env)
+	}
+}
@@ -0,0 +1,92 @@
+package cucumber
+
+import "fmt"
+
+// Stack groups one or more middlewares so they can be reused
+// across multiple routers and groups
+//
+//	authStack := cucumber.Stack(session, auth)
+//	router.Group("/admin", authStack...)
+func Stack(middlewares ...HandlerFunc) []HandlerFunc {
+	stack := make([]HandlerFunc, len(middlewares))
+	copy(stack, middlewares)
+	return stack
+}
+
+// stackRef is named stack used before it was defined, e.g. by controller
+// router, resolved once router is attached to router defining the stack
+type stackRef struct {
+	name  string
+	stack []HandlerFunc
+}
+
+// handle runs resolved stack in place of the reference
+func (ref *stackRef) handle(c *Context) {
+	if ref.stack == nil {
+		panic(fmt.Sprintf("middleware stack `%s` is not defined", ref.name))
+	}
+	chain := make(HandlersChain, 0, len(c.handlers)+len(ref.stack)-1)
+	chain = append(chain, c.handlers[:c.index]...)
+	chain = append(chain, ref.stack...)
+	chain = append(chain, c.handlers[c.index+1:]...)
+	c.handlers = chain
+	c.index--
+	c.Next()
+}
+
+// DefineStack registers named middleware stack which can be applied
+// on router and all of its groups with UseStack
+func (r *Router) DefineStack(name string, middlewares ...HandlerFunc) {
+	if name == "" {
+		panic("stack name can not be empty")
+	}
+	assertHandlers(middlewares, "stack '"+name+"'")
+	r.stacks[name] = Stack(middlewares...)
+	r.resolveStacks()
+}
+
+// Stack returns copy of named middleware stack
+func (r *Router) Stack(name string) ([]HandlerFunc, bool) {
+	stack, ok := r.stacks[name]
+	return Stack(stack...), ok
+}
+
+// UseStack appends middlewares of named stack onto the Router stack.
+//
+// Routers created with NewRouter, e.g. by controllers, can use stacks
+// defined by application router, they are resolved when router is attached.
+// Application router panics when stack is not defined.
+func (r *Router) UseStack(name string) {
+	if stack, ok := r.stacks[name]; ok {
+		r.Use(stack...)
+		return
+	}
+	if !r.deferStacks {
+		panic(fmt.Sprintf("middleware stack `%s` is not defined", name))
+	}
+	ref := &stackRef{name: name}
+	*r.stackRefs = append(*r.stackRefs, ref)
+	r.Use(ref.handle)
+}
+
+// attachStacks takes over stack references of attached router
+func (r *Router) attachStacks(router *Router) {
+	*r.stackRefs = append(*r.stackRefs, *router.stackRefs...)
+	r.resolveStacks()
+	if refs := *r.stackRefs; len(refs) > 0 && !r.deferStacks {
+		panic(fmt.Sprintf("middleware stack `%s` is not defined", refs[0].name))
+	}
+}
+
+// resolveStacks resolves stack references defined on router
+func (r *Router) resolveStacks() {
+	pending := (*r.stackRefs)[:0]
+	for _, ref := range *r.stackRefs {
+		if stack, ok := r.stacks[ref.name]; ok {
+			ref.stack = stack
+			continue
+		}
+		pending = append(pending, ref)
+	}
+	*r.stackRefs = pending
+}
@@ -0,0 +1,42 @@
+package cucumber
+
+import (
+	"github.com/AjdinHalac/cucumber/log"
+	"go.elastic.co/apm"
+)
+
+// redacted replaces secret option values in startup summary
+const redacted = "[REDACTED]"
+
+// startupSummary returns effective configuration logged at startup, secrets are redacted
+func (a *App) startupSummary() log.Fields {
+	secret := ""
+	if a.SessionSecret != "" {
+		secret = redacted
+	}
+	return log.Fields{
+		"env":                a.Env,
+		"name":               a.Name,
+		"version":            a.Version,
+		"http_addr":          a.HTTPAddr,
+		"grpc_addr":          a.GRPCAddr,
+		"base_path":          a.router.BasePath(),
+		"session":            a.UseSession,
+		"session_secret":     secret,
+		"translator":         a.UseTranslator,
+		"view_engine":        a.UseViewEngine,
+		"apm":                apm.DefaultTracer.Active(),
+		"controller_package": a.ControllerPackage,
+		"middlewares":        len(a.router.Handlers),
+		"request_timeout":    a.RequestTimeout.String(),
+	}
+}
+
+// logStartupSummary logs effective configuration, so misconfiguration
+// is visible right at startup
+func (a *App) logStartupSummary() {
+	a.Logger.WithFields(a.startupSummary()).Info("Startup configuration")
+	if a.HTTPAddr == "" && a.GRPCAddr == "" {
+		a.Logger.Warn("Neither HTTPAddr nor GRPCAddr is set, application does not serve any requests")
+	}
+}
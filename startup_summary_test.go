@@ -0,0 +1,34 @@
+package cucumber
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppStartupSummary(t *testing.T) {
+	app := newTestAppInstance()
+	app.HTTPAddr = ":8080"
+	app.SessionSecret = "s3cret"
+	app.Use(func(c *Context) { c.Next() })
+
+	logger := newLevelLogger()
+	app.Logger = logger
+	app.logStartupSummary()
+
+	summary := (*logger.history)[0]
+	assert.Equal(t, ":8080", summary["http_addr"])
+	assert.Equal(t, "", summary["grpc_addr"])
+	assert.Equal(t, redacted, summary["session_secret"])
+	assert.Equal(t, len(app.router.Handlers), summary["middlewares"])
+	assert.Equal(t, app.ControllerPackage, summary["controller_package"])
+	assert.Contains(t, summary, "apm")
+	for _, value := range summary {
+		assert.NotEqual(t, "s3cret", value)
+	}
+	assert.Equal(t, "info", *logger.level)
+
+	app.HTTPAddr = ""
+	app.logStartupSummary()
+	assert.Equal(t, "warn", *logger.level)
+}
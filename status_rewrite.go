@@ -0,0 +1,43 @@
+package cucumber
+
+// RewriteStatusCode returns a middleware that replaces response status code
+// according to given rules before response header is written
+//
+// It is intended for backward-compatible API changes, e.g. legacy clients
+// expecting 200 instead of 201:
+//
+//	router.Use(cucumber.RewriteStatusCode(map[int]int{201: 200}))
+func RewriteStatusCode(rules map[int]int) HandlerFunc {
+	return ConditionalStatusRewrite(func(c *Context, code int) int {
+		if rewritten, ok := rules[code]; ok {
+			return rewritten
+		}
+		return code
+	})
+}
+
+// ConditionalStatusRewrite returns a middleware that replaces response status code
+// with the one returned by fn before response header is written
+func ConditionalStatusRewrite(fn func(c *Context, code int) int) HandlerFunc {
+	return func(c *Context) {
+		c.writermem.onBeforeWriteHeader(func() {
+			code := c.writermem.status
+			if rewritten := fn(c, code); rewritten > 0 && rewritten != code {
+				if c.writermem.originalStatus == 0 {
+					c.writermem.originalStatus = code
+				}
+				c.writermem.status = rewritten
+			}
+		})
+		c.Next()
+	}
+}
+
+// OriginalStatusCode returns response status code set by handler
+// before it was rewritten by status rewrite middleware
+func (c *Context) OriginalStatusCode() int {
+	if c.writermem.originalStatus != 0 {
+		return c.writermem.originalStatus
+	}
+	return c.writermem.status
+}
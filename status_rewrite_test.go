@@ -0,0 +1,51 @@
+package cucumber
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewriteStatusCode(t *testing.T) {
+	loggedStatus := 0
+	app := newTestAppInstance()
+	app.Use(func(c *Context) {
+		c.Next()
+		loggedStatus = c.OriginalStatusCode()
+	})
+	app.Use(RewriteStatusCode(map[int]int{http.StatusCreated: http.StatusOK}))
+	app.POST("/", func(c *Context) {
+		c.Status(http.StatusCreated)
+		_, _ = c.Response.WriteString("created")
+	})
+
+	w := performRequest(app, "POST", "/")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "created", w.Body.String())
+	assert.Equal(t, http.StatusCreated, loggedStatus)
+}
+
+func TestConditionalStatusRewrite(t *testing.T) {
+	app := newTestAppInstance()
+	app.Use(ConditionalStatusRewrite(func(c *Context, code int) int {
+		if c.requestHeader("X-Legacy-Client") != "" && code == http.StatusNoContent {
+			return http.StatusOK
+		}
+		return code
+	}))
+	app.DELETE("/", func(c *Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	w := performRequest(app, "DELETE", "/")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	req, _ := http.NewRequest("DELETE", "/", nil)
+	req.Header.Set("X-Legacy-Client", "1")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
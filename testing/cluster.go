@@ -0,0 +1,77 @@
+// Package testing provides helpers for testing cucumber applications
+package testing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"sync/atomic"
+
+	"github.com/AjdinHalac/cucumber"
+)
+
+// Node is a cluster App instance serving on ephemeral port
+type Node struct {
+	App    *cucumber.App
+	Server *httptest.Server
+	// URL is base URL of the node, e.g. http://127.0.0.1:54321
+	URL *url.URL
+
+	proxy *httputil.ReverseProxy
+}
+
+// Cluster is a round-robin load balancer in front of multiple App instances
+type Cluster struct {
+	Nodes []*Node
+
+	next uint64
+}
+
+// NewCluster starts n App instances created with opts on ephemeral ports
+//
+// Routes have to be registered on each node App before cluster serves requests.
+func NewCluster(n int, opts cucumber.Options) (*Cluster, error) {
+	if n < 1 {
+		return nil, errors.New("cluster needs at least one node")
+	}
+
+	cluster := &Cluster{}
+	for i := 0; i < n; i++ {
+		app := cucumber.NewWithOptions(opts)
+		srv := httptest.NewServer(app)
+		u, err := url.Parse(srv.URL)
+		if err != nil {
+			srv.Close()
+			cluster.Shutdown(context.Background())
+			return nil, err
+		}
+		cluster.Nodes = append(cluster.Nodes, &Node{
+			App:    app,
+			Server: srv,
+			URL:    u,
+			proxy:  httputil.NewSingleHostReverseProxy(u),
+		})
+	}
+	return cluster, nil
+}
+
+// ServeHTTP forwards request to the next node in round-robin order
+func (c *Cluster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	i := atomic.AddUint64(&c.next, 1) - 1
+	c.Nodes[i%uint64(len(c.Nodes))].proxy.ServeHTTP(w, r)
+}
+
+// Shutdown gracefully stops all nodes, waiting for in-flight requests until ctx is done
+func (c *Cluster) Shutdown(ctx context.Context) error {
+	var firstErr error
+	for _, node := range c.Nodes {
+		if err := node.Server.Config.Shutdown(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		node.Server.Close()
+	}
+	return firstErr
+}
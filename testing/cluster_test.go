@@ -0,0 +1,46 @@
+package testing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AjdinHalac/cucumber"
+	cucumbertesting "github.com/AjdinHalac/cucumber/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCluster(t *testing.T) {
+	opts := cucumber.NewOptions()
+	opts.UseRequestLogger = false
+
+	cluster, err := cucumbertesting.NewCluster(3, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer cluster.Shutdown(context.Background())
+
+	for _, node := range cluster.Nodes {
+		port := node.URL.Port()
+		node.App.GET("/port", func(c *cucumber.Context) {
+			c.String(http.StatusOK, port)
+		})
+	}
+
+	received := map[string]int{}
+	for i := 0; i < 9; i++ {
+		w := httptest.NewRecorder()
+		cluster.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/port", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		received[w.Body.String()]++
+	}
+
+	assert.Len(t, received, 3)
+	for _, node := range cluster.Nodes {
+		assert.Equal(t, 3, received[node.URL.Port()], node.URL.String())
+	}
+
+	_, err = cucumbertesting.NewCluster(0, opts)
+	assert.Error(t, err)
+}
@@ -0,0 +1,82 @@
+package cucumber
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// throttleSmoothing is the weight of the latest response time in moving average
+const throttleSmoothing = 0.2
+
+// ErrThrottled is served when request is rejected by AdaptiveThrottle
+var ErrThrottled = errors.New("server is overloaded")
+
+// adaptiveThrottle tracks exponentially weighted moving average of response time
+type adaptiveThrottle struct {
+	target      time.Duration
+	maxInflight int64
+
+	// inflight is accessed atomically
+	inflight int64
+
+	mu      sync.Mutex
+	average float64
+	random  *rand.Rand
+}
+
+// AdaptiveThrottle returns a middleware which rejects requests with 503 Service Unavailable
+// when average response time exceeds target, or when maxInflight requests are already
+// being handled
+//
+// Requests are rejected proportionally to how much average exceeds target, e.g. half of
+// requests are rejected when average is twice the target, until response times recover.
+// Zero maxInflight disables the in-flight limit.
+func AdaptiveThrottle(target time.Duration, maxInflight int) HandlerFunc {
+	if target <= 0 {
+		panic("adaptive throttle target must be positive")
+	}
+	t := &adaptiveThrottle{
+		target:      target,
+		maxInflight: int64(maxInflight),
+		random:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+
+	return func(c *Context) {
+		inflight := atomic.AddInt64(&t.inflight, 1)
+		defer atomic.AddInt64(&t.inflight, -1)
+
+		if (t.maxInflight > 0 && inflight > t.maxInflight) || t.reject() {
+			c.Abort()
+			c.ServeError(http.StatusServiceUnavailable, ErrThrottled)
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+		t.observe(time.Since(start))
+	}
+}
+
+// reject decides whether request should be rejected based on average response time
+func (t *adaptiveThrottle) reject() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.average <= float64(t.target) {
+		return false
+	}
+	return t.random.Float64() < 1-float64(t.target)/t.average
+}
+
+func (t *adaptiveThrottle) observe(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.average == 0 {
+		t.average = float64(d)
+		return
+	}
+	t.average = throttleSmoothing*float64(d) + (1-throttleSmoothing)*t.average
+}
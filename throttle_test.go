@@ -0,0 +1,64 @@
+package cucumber
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdaptiveThrottle(t *testing.T) {
+	var slow int32 = 1
+
+	app := newTestAppInstance()
+	app.Use(AdaptiveThrottle(5*time.Millisecond, 0))
+	app.GET("/", func(c *Context) {
+		if atomic.LoadInt32(&slow) == 1 {
+			time.Sleep(25 * time.Millisecond)
+		}
+		c.Status(http.StatusOK)
+	})
+
+	// overload
+	rejected := 0
+	for i := 0; i < 20; i++ {
+		if performRequest(app, "GET", "/").Code == http.StatusServiceUnavailable {
+			rejected++
+		}
+	}
+	assert.NotZero(t, rejected)
+
+	// handler speeds up, response times recover
+	atomic.StoreInt32(&slow, 0)
+	for i := 0; i < 500; i++ {
+		performRequest(app, "GET", "/")
+	}
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, http.StatusOK, performRequest(app, "GET", "/").Code)
+	}
+}
+
+func TestAdaptiveThrottleMaxInflight(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	app := newTestAppInstance()
+	app.Use(AdaptiveThrottle(time.Minute, 1))
+	app.GET("/", func(c *Context) {
+		close(started)
+		<-release
+		c.Status(http.StatusOK)
+	})
+
+	done := make(chan int)
+	go func() {
+		done <- performRequest(app, "GET", "/").Code
+	}()
+	<-started
+
+	assert.Equal(t, http.StatusServiceUnavailable, performRequest(app, "GET", "/").Code)
+	close(release)
+	assert.Equal(t, http.StatusOK, <-done)
+}
@@ -0,0 +1,31 @@
+package cucumber
+
+import "net/http"
+
+// SetTrailer sets HTTP trailer sent after response body
+//
+// Trailer is declared in Trailer header when response header was not written
+// yet, so clients know to expect it. Value can be set any time before handler
+// returns, e.g. checksum of streamed body.
+func (c *Context) SetTrailer(key, value string) {
+	key = http.CanonicalHeaderKey(key)
+	if !c.Response.Written() {
+		declared := false
+		for _, k := range c.Response.Header().Values("Trailer") {
+			if http.CanonicalHeaderKey(k) == key {
+				declared = true
+				break
+			}
+		}
+		if !declared {
+			c.Response.Header().Add("Trailer", key)
+		}
+	}
+	c.Response.Header().Set(http.TrailerPrefix+key, value)
+}
+
+// RequestTrailers returns trailers sent by client, they are only
+// available once request body is read to EOF
+func (c *Context) RequestTrailers() http.Header {
+	return c.Request.Trailer
+}
@@ -0,0 +1,81 @@
+package cucumber
+
+import (
+	"bufio"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rawRequest sends raw HTTP request to server and returns raw response
+func rawRequest(t *testing.T, srv *httptest.Server, request string) string {
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if !assert.NoError(t, err) {
+		return ""
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte(request))
+	assert.NoError(t, err)
+	raw, err := ioutil.ReadAll(bufio.NewReader(conn))
+	assert.NoError(t, err)
+	return string(raw)
+}
+
+func TestContextSetTrailer(t *testing.T) {
+	app := newTestAppInstance()
+	app.GET("/report", func(c *Context) {
+		c.SetTrailer("checksum", "")
+		c.Status(http.StatusOK)
+		hash := md5.New()
+		for _, line := range []string{"cucumber\n", "tomato\n"} {
+			c.Response.Write([]byte(line))
+			c.Response.Flush()
+			hash.Write([]byte(line))
+		}
+		c.SetTrailer("Checksum", hex.EncodeToString(hash.Sum(nil)))
+	})
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	raw := rawRequest(t, srv, "GET /report HTTP/1.1\r\nHost: example.com\r\nConnection: close\r\n\r\n")
+	sum := md5.Sum([]byte("cucumber\ntomato\n"))
+	assert.Contains(t, raw, "Trailer: Checksum\r\n")
+	assert.Contains(t, raw, "Transfer-Encoding: chunked\r\n")
+	assert.True(t, strings.HasSuffix(raw, fmt.Sprintf("0\r\nChecksum: %s\r\n\r\n", hex.EncodeToString(sum[:]))), raw)
+
+	// trailer is read by client after body
+	res, err := http.Get(srv.URL + "/report")
+	assert.NoError(t, err)
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal(t, "cucumber\ntomato\n", string(body))
+	assert.Equal(t, hex.EncodeToString(sum[:]), res.Trailer.Get("Checksum"))
+}
+
+func TestContextRequestTrailers(t *testing.T) {
+	var body, checksum string
+	app := newTestAppInstance()
+	app.POST("/upload", func(c *Context) {
+		data, _ := c.GetRawData()
+		body = string(data)
+		checksum = c.RequestTrailers().Get("Checksum")
+		c.Status(http.StatusNoContent)
+	})
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	raw := rawRequest(t, srv, "POST /upload HTTP/1.1\r\nHost: example.com\r\nConnection: close\r\n"+
+		"Transfer-Encoding: chunked\r\nTrailer: Checksum\r\n\r\n"+
+		"8\r\ncucumber\r\n0\r\nChecksum: abc\r\n\r\n")
+	assert.True(t, strings.HasPrefix(raw, "HTTP/1.1 204"), raw)
+	assert.Equal(t, "cucumber", body)
+	assert.Equal(t, "abc", checksum)
+}
@@ -1,14 +1,19 @@
 package cucumber
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/AjdinHalac/cucumber/i18n"
+	"github.com/AjdinHalac/cucumber/i18n/bundle"
 	"github.com/AjdinHalac/cucumber/i18n/language"
 	"github.com/AjdinHalac/cucumber/i18n/translation"
 )
@@ -21,6 +26,19 @@ type LanguageExtractor func(LanguageExtractorOptions, *Context) []string
 // LanguageExtractorOptions is a map of options for a LanguageExtractor.
 type LanguageExtractorOptions map[string]interface{}
 
+// Merge strategies applied on duplicate translation keys
+const (
+	// MergeOverwrite replaces existing translation with the merged one
+	MergeOverwrite = "overwrite"
+	// MergeSkip keeps existing translation
+	MergeSkip = "skip"
+	// MergeError fails merge on the first duplicate key
+	MergeError = "error"
+)
+
+// ErrDuplicateTranslation is returned by Merge with MergeError strategy
+var ErrDuplicateTranslation = errors.New("duplicate translation key")
+
 // Translator for handling all your i18n needs.
 type Translator struct {
 	// Path - where are the files?
@@ -33,11 +51,64 @@ type Translator struct {
 	LanguageExtractors []LanguageExtractor
 	// LanguageExtractorOptions - a map with options to give to LanguageExtractors.
 	LanguageExtractorOptions LanguageExtractorOptions
+	// MergeStrategy - behavior of Merge on duplicate keys. default is "overwrite"
+	MergeStrategy string
+	// Strict - reject languages explicitly requested by query string which
+	// have no translations, see Localize
+	Strict bool
+	// ReportMissing - collect keys missing in requested language, see MissingKeys.
+	// enabled in development environment
+	ReportMissing bool
+	// MarkMissing - translate keys missing in requested language to "[[key]]"
+	// instead of falling back, so untranslated strings are easy to spot
+	MarkMissing bool
+
+	// translations of this translator, i18n default bundle is used when nil
+	bundle *bundle.Bundle
+	// fallback chains of languages set by SetFallback
+	fallbacks map[string][]string
+	// missing keys collected when ReportMissing is set
+	missing map[string]struct{}
+	// mu guards bundle swapped by Reload, fallback chains and missing keys
+	mu sync.RWMutex
+}
+
+// translations returns bundle holding translator translations
+func (t *Translator) translations() *bundle.Bundle {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.bundle == nil {
+		return i18n.DefaultBundle()
+	}
+	return t.bundle
 }
 
 // Load translations.
 func (t *Translator) Load() error {
-	return filepath.Walk(t.Path, func(path string, info os.FileInfo, err error) error {
+	return loadTranslations(t.translations(), t.Path)
+}
+
+// Reload replaces translations with ones currently found in Path, e.g. on SIGHUP.
+//
+// Translations added at runtime are dropped, current translations are kept
+// when loading fails.
+func (t *Translator) Reload() error {
+	b := bundle.New()
+	if err := loadTranslations(b, t.Path); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	t.bundle = b
+	t.mu.Unlock()
+	return nil
+}
+
+// loadTranslations parses all translation files found in root into bundle
+func loadTranslations(b *bundle.Bundle, root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
 		if !info.IsDir() {
 			data, err := ioutil.ReadFile(path)
 			if err != nil {
@@ -48,7 +119,7 @@ func (t *Translator) Load() error {
 			dir := filepath.Dir(path)
 
 			// Add a prefix to the loaded string, to avoid colilision with ISO lang code
-			err = i18n.ParseTranslationFileBytes(fmt.Sprintf("%sbuff%s", dir, base), data)
+			err = b.ParseTranslationFileBytes(fmt.Sprintf("%sbuff%s", dir, base), data)
 			if err != nil {
 				return err
 			}
@@ -60,7 +131,150 @@ func (t *Translator) Load() error {
 // AddTranslation directly, without using a file. This is useful if you wish to load translations
 // from a database, instead of disk.
 func (t *Translator) AddTranslation(lang *language.Language, translations ...translation.Translation) {
-	i18n.AddTranslation(lang, translations...)
+	t.translations().AddTranslation(lang, translations...)
+}
+
+// AddLocale adds messages of given language at runtime, e.g. from a remote
+// source, messages map translation IDs to translations.
+//
+// It panics when lang is not a known language tag.
+func (t *Translator) AddLocale(lang string, messages map[string]string) {
+	translations := make([]translation.Translation, 0, len(messages))
+	for id, text := range messages {
+		tr, err := translation.NewTranslation(map[string]interface{}{"id": id, "translation": text})
+		if err != nil {
+			panic(err)
+		}
+		translations = append(translations, tr)
+	}
+	t.AddTranslation(language.MustParse(lang)[0], translations...)
+}
+
+// Merge imports translations of all languages from other translator
+//
+// Duplicate keys are resolved by MergeStrategy of the receiver.
+func (t *Translator) Merge(other *Translator) error {
+	strategy := t.MergeStrategy
+	if strategy == "" {
+		strategy = MergeOverwrite
+	}
+	if strategy != MergeOverwrite && strategy != MergeSkip && strategy != MergeError {
+		return fmt.Errorf("unknown translator merge strategy %q", strategy)
+	}
+
+	current := t.translations().Translations()
+	for tag, translations := range other.translations().Translations() {
+		langs := language.Parse(tag)
+		if len(langs) == 0 {
+			return fmt.Errorf("no language found in %q", tag)
+		}
+
+		merged := make([]translation.Translation, 0, len(translations))
+		for id, tr := range translations {
+			if _, exists := current[tag][id]; exists {
+				switch strategy {
+				case MergeSkip:
+					continue
+				case MergeError:
+					return fmt.Errorf("%w: %s in %s", ErrDuplicateTranslation, id, tag)
+				}
+			}
+			// copy translation so translators do not share state
+			merged = append(merged, tr.UntranslatedCopy().Merge(tr))
+		}
+		t.translations().AddTranslation(langs[0], merged...)
+	}
+	return nil
+}
+
+// Keys returns sorted list of translation keys registered for given language
+func (t *Translator) Keys(lang string) []string {
+	if langs := language.Parse(lang); len(langs) > 0 {
+		lang = langs[0].Tag
+	}
+	keys := t.translations().LanguageTranslationIDs(lang)
+	sort.Strings(keys)
+	return keys
+}
+
+// Tfunc returns a TranslateFunc bound to the first language which
+// has translations, see i18n.Tfunc
+func (t *Translator) Tfunc(languageSource string, languageSources ...string) (i18n.TranslateFunc, error) {
+	tfunc, err := t.translations().Tfunc(languageSource, languageSources...)
+	return i18n.TranslateFunc(tfunc), err
+}
+
+// SetFallback sets languages to look up, in order, when translation is
+// missing in lang, before DefaultLanguage is used.
+//
+// By default region languages fall back to their base language, e.g. "pt-BR" to "pt".
+func (t *Translator) SetFallback(lang string, fallbacks ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.fallbacks == nil {
+		t.fallbacks = make(map[string][]string)
+	}
+	t.fallbacks[language.NormalizeTag(lang)] = fallbacks
+}
+
+// fallbackChain returns languages looked up by Translate for lang
+func (t *Translator) fallbackChain(lang string) []string {
+	t.mu.RLock()
+	fallbacks, ok := t.fallbacks[language.NormalizeTag(lang)]
+	t.mu.RUnlock()
+	if !ok {
+		fallbacks = languageFallbacks(lang)[1:]
+	}
+
+	chain := make([]string, 0, len(fallbacks)+2)
+	chain = append(chain, lang)
+	chain = append(chain, fallbacks...)
+	return append(chain, t.DefaultLanguage)
+}
+
+// Translate translates translationID into lang walking its fallback chain,
+// see SetFallback, translationID is returned when no translation is found.
+func (t *Translator) Translate(lang string, translationID string, args ...interface{}) string {
+	translations := t.translations()
+	for i, tag := range t.fallbackChain(lang) {
+		if !translations.HasTranslation(tag, translationID) {
+			if i == 0 {
+				if t.ReportMissing {
+					t.reportMissing(lang, translationID)
+				}
+				if t.MarkMissing {
+					return "[[" + translationID + "]]"
+				}
+			}
+			continue
+		}
+		if tfunc, err := translations.Tfunc(tag); err == nil {
+			return tfunc(translationID, args...)
+		}
+	}
+	return translationID
+}
+
+// MissingKeys returns sorted list of keys missing in requested languages
+// as "<lang>:<key>", collected when ReportMissing is set
+func (t *Translator) MissingKeys() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	keys := make([]string, 0, len(t.missing))
+	for key := range t.missing {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (t *Translator) reportMissing(lang, translationID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.missing == nil {
+		t.missing = make(map[string]struct{})
+	}
+	t.missing[language.NormalizeTag(lang)+":"+translationID] = struct{}{}
 }
 
 // NewTranslator -
@@ -71,6 +285,8 @@ func NewTranslator(filePath string, language string) (*Translator, error) {
 		Path:            filePath,
 		DefaultLanguage: language,
 		HelperName:      "t",
+		MergeStrategy:   MergeOverwrite,
+		bundle:          bundle.New(),
 		LanguageExtractorOptions: LanguageExtractorOptions{
 			"CookieName":       "lang",
 			"SessionName":      "lang",
@@ -90,7 +306,7 @@ func NewTranslator(filePath string, language string) (*Translator, error) {
 
 // AvailableLanguages gets the list of languages provided by the app.
 func (t *Translator) AvailableLanguages() []string {
-	lt := i18n.LanguageTags()
+	lt := t.translations().LanguageTags()
 	sort.Strings(lt)
 	return lt
 }
@@ -106,6 +322,20 @@ func (t *Translator) ExtractLanguage(c *Context) []string {
 	return langs
 }
 
+// SupportedLanguage returns tag of the first language with translations,
+// region tags fall back to base language, e.g. "en-GB" to "en".
+// Empty string is returned when none of languages is supported.
+func (t *Translator) SupportedLanguage(langs ...string) string {
+	for _, lang := range langs {
+		for _, tag := range languageFallbacks(lang) {
+			if _, l, err := t.translations().TfuncAndLanguage(tag); err == nil {
+				return l.Tag
+			}
+		}
+	}
+	return ""
+}
+
 // CookieLanguageExtractor is a LanguageExtractor implementation, using a cookie.
 func CookieLanguageExtractor(o LanguageExtractorOptions, c *Context) []string {
 	langs := make([]string, 0)
@@ -127,8 +357,12 @@ func SessionLanguageExtractor(o LanguageExtractorOptions, c *Context) []string {
 	langs := make([]string, 0)
 	// try to get the language from the session
 	if sessionName := o["SessionName"].(string); sessionName != "" {
-		if s := c.Session().Get(sessionName); s != nil {
-			langs = append(langs, s.(string))
+		// sessions might be disabled
+		if c.app.SessionStore == nil {
+			return langs
+		}
+		if s, ok := c.Session().Get(sessionName).(string); ok && s != "" {
+			langs = append(langs, s)
 		}
 	} else {
 		c.Logger().Error("i18n Translator: \"SessionName\" is not defined in LanguageExtractorOptions")
@@ -180,16 +414,73 @@ func RequestParamLanguageExtractor(o LanguageExtractorOptions, c *Context) []str
 
 // Inspired from https://siongui.github.io/2015/02/22/go-parse-accept-language/
 // Parse an Accept-Language string to get usable lang values for i18n system
+//
+// Languages are ordered by quality value, malformed tags, wildcard and
+// languages with zero quality are skipped. Region tag is followed by its
+// base language, e.g. "en-GB" yields "en-GB", "en".
 func parseAcceptLanguage(acptLang string) []string {
-	var lqs []string
+	type weightedLang struct {
+		lang string
+		q    float64
+	}
+	var lqs []weightedLang
 
 	langQStrs := strings.Split(acptLang, ",")
 	for _, langQStr := range langQStrs {
 		trimedLangQStr := strings.Trim(langQStr, " ")
 
 		langQ := strings.Split(trimedLangQStr, ";")
-		lq := langQ[0]
-		lqs = append(lqs, lq)
+		lang := strings.TrimSpace(langQ[0])
+		if lang == "*" || !languageTagPattern.MatchString(lang) {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range langQ[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			v, err := strconv.ParseFloat(param[2:], 64)
+			if err != nil || v < 0 || v > 1 {
+				q = 0
+			} else {
+				q = v
+			}
+		}
+		if q == 0 {
+			continue
+		}
+		lqs = append(lqs, weightedLang{lang, q})
+	}
+	sort.SliceStable(lqs, func(i, j int) bool {
+		return lqs[i].q > lqs[j].q
+	})
+
+	langs := make([]string, 0, len(lqs))
+	seen := make(map[string]bool, len(lqs))
+	for _, lq := range lqs {
+		for _, lang := range languageFallbacks(lq.lang) {
+			if !seen[strings.ToLower(lang)] {
+				seen[strings.ToLower(lang)] = true
+				langs = append(langs, lang)
+			}
+		}
+	}
+	return langs
+}
+
+// languageTagPattern matches well formed BCP 47 language tags, e.g. "en", "pt-BR", "zh-Hans-CN"
+var languageTagPattern = regexp.MustCompile(`^[A-Za-z]{1,8}([-_][A-Za-z0-9]{1,8})*$`)
+
+// languageFallbacks returns tag followed by its less specific variants,
+// e.g. "zh-Hans-CN" yields "zh-Hans-CN", "zh-Hans", "zh"
+func languageFallbacks(tag string) []string {
+	tags := []string{tag}
+	for i := len(tag) - 1; i > 0; i-- {
+		if tag[i] == '-' || tag[i] == '_' {
+			tags = append(tags, tag[:i])
+		}
 	}
-	return lqs
+	return tags
 }
@@ -0,0 +1,175 @@
+package cucumber
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/AjdinHalac/cucumber/i18n/language"
+	"github.com/AjdinHalac/cucumber/i18n/translation"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTranslator(t *testing.T, translations map[string]string) *Translator {
+	tr, err := NewTranslator(t.TempDir(), "en-US")
+	if err != nil {
+		t.Fatalf("An error occured. %v", err)
+	}
+	for id, text := range translations {
+		tt, err := translation.NewTranslation(map[string]interface{}{"id": id, "translation": text})
+		if err != nil {
+			t.Fatalf("An error occured. %v", err)
+		}
+		tr.AddTranslation(language.MustParse("en-US")[0], tt)
+	}
+	return tr
+}
+
+func TestTranslatorMerge(t *testing.T) {
+	tt := []struct {
+		Strategy string
+		Welcome  string
+		Err      error
+	}{
+		{Strategy: MergeOverwrite, Welcome: "Welcome to billing"},
+		{Strategy: MergeSkip, Welcome: "Welcome"},
+		{Strategy: MergeError, Welcome: "Welcome", Err: ErrDuplicateTranslation},
+	}
+
+	for _, tc := range tt {
+		auth := newTestTranslator(t, map[string]string{"welcome": "Welcome", "login": "Log in"})
+		billing := newTestTranslator(t, map[string]string{"welcome": "Welcome to billing", "invoice": "Invoice"})
+		auth.MergeStrategy = tc.Strategy
+
+		err := auth.Merge(billing)
+		assert.ErrorIs(t, err, tc.Err, tc.Strategy)
+
+		tfunc, _ := auth.Tfunc("en-US")
+		assert.Equal(t, tc.Welcome, tfunc("welcome"), tc.Strategy)
+		assert.Equal(t, "Welcome to billing", func() string {
+			tfunc, _ := billing.Tfunc("en-US")
+			return tfunc("welcome")
+		}(), tc.Strategy)
+		if tc.Err == nil {
+			assert.Equal(t, []string{"invoice", "login", "welcome"}, auth.Keys("en-US"), tc.Strategy)
+		}
+	}
+}
+
+func TestTranslatorReload(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "messages.en-US.json")
+	writeFile := func(content string) {
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatalf("An error occured. %v", err)
+		}
+	}
+	writeFile(`[{"id": "welcome", "translation": "Welcome"}, {"id": "legacy", "translation": "Legacy"}]`)
+
+	tr, err := NewTranslator(dir, "en-US")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"legacy", "welcome"}, tr.Keys("en-US"))
+
+	writeFile(`[{"id": "welcome", "translation": "Welcome back"}]`)
+	assert.NoError(t, tr.Reload())
+
+	tfunc, _ := tr.Tfunc("en-US")
+	assert.Equal(t, "Welcome back", tfunc("welcome"))
+	assert.Equal(t, []string{"welcome"}, tr.Keys("en-US"))
+
+	// broken file keeps current translations
+	writeFile(`[{"id": `)
+	assert.Error(t, tr.Reload())
+	tfunc, _ = tr.Tfunc("en-US")
+	assert.Equal(t, "Welcome back", tfunc("welcome"))
+}
+
+func TestTranslatorAddLocale(t *testing.T) {
+	tr := newTestTranslator(t, map[string]string{"welcome": "Welcome"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			tfunc, _ := tr.Tfunc("de", "en-US")
+			tfunc("welcome")
+		}()
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, tr.Reload())
+			tr.AddLocale("de", map[string]string{"welcome": "Willkommen"})
+		}()
+	}
+	wg.Wait()
+
+	tfunc, _ := tr.Tfunc("de")
+	assert.Equal(t, "Willkommen", tfunc("welcome"))
+	assert.Panics(t, func() {
+		tr.AddLocale("unknown", map[string]string{"welcome": "?"})
+	})
+}
+
+func TestTranslatorFallbackChain(t *testing.T) {
+	tr := newTestTranslator(t, map[string]string{"hello": "Hello", "bye": "Bye", "thanks": "Thanks"})
+	tr.AddLocale("pt", map[string]string{"hello": "Olá", "bye": "Tchau"})
+	tr.AddLocale("pt-BR", map[string]string{"hello": "Oi"})
+	tr.AddLocale("es", map[string]string{"thanks": "Gracias"})
+
+	assert.Equal(t, "Oi", tr.Translate("pt-BR", "hello"))
+	assert.Equal(t, "Tchau", tr.Translate("pt-BR", "bye"))
+	assert.Equal(t, "Thanks", tr.Translate("pt-BR", "thanks"))
+	assert.Equal(t, "missing", tr.Translate("pt-BR", "missing"))
+
+	tr.SetFallback("pt-BR", "es")
+	assert.Equal(t, "Oi", tr.Translate("pt-BR", "hello"))
+	assert.Equal(t, "Gracias", tr.Translate("pt-BR", "thanks"))
+	assert.Equal(t, "Bye", tr.Translate("pt-BR", "bye"))
+}
+
+func TestTranslatorMissingKeys(t *testing.T) {
+	tr := newTestTranslator(t, map[string]string{"hello": "Hello", "bye": "Bye"})
+	tr.AddLocale("de", map[string]string{"hello": "Hallo"})
+
+	assert.Equal(t, "Bye", tr.Translate("de", "bye"))
+	assert.Empty(t, tr.MissingKeys())
+
+	tr.ReportMissing = true
+	assert.Equal(t, "Hallo", tr.Translate("de", "hello"))
+	assert.Equal(t, "Bye", tr.Translate("de", "bye"))
+	assert.Equal(t, "Bye", tr.Translate("de", "bye"))
+	assert.Equal(t, "missing", tr.Translate("en-US", "missing"))
+	assert.Equal(t, []string{"de:bye", "en-us:missing"}, tr.MissingKeys())
+
+	tr.MarkMissing = true
+	assert.Equal(t, "Hallo", tr.Translate("de", "hello"))
+	assert.Equal(t, "[[bye]]", tr.Translate("de", "bye"))
+}
+
+func TestTranslatorMissingLocales(t *testing.T) {
+	for _, root := range []string{filepath.Join(t.TempDir(), "missing"), t.TempDir()} {
+		logger := newLevelLogger()
+		opts := NewOptions()
+		opts.UseViewEngine = false
+		opts.UseRequestLogger = false
+		opts.UseTranslator = true
+		opts.TranslatorLocalesRoot = root
+		opts.Logger = logger
+
+		app := NewWithOptions(opts)
+		if assert.NotNil(t, app.Translator(), root) {
+			assert.Equal(t, "en-US", app.Translator().DefaultLanguage, root)
+			assert.Equal(t, "welcome", app.Translator().Translate("de", "welcome"), root)
+		}
+		assert.Equal(t, "warn", *logger.level, root)
+
+		app.GET("/", func(c *Context) {
+			c.String(http.StatusOK, c.Language())
+		})
+		w := performRequest(app, http.MethodGet, "/")
+		assert.Equal(t, http.StatusOK, w.Code, root)
+		assert.Equal(t, "en-US", w.Body.String(), root)
+	}
+}
@@ -0,0 +1,233 @@
+package cucumber
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// transportMetricsVars holds sampled metrics of collectors keyed by name
+var transportMetricsVars = expvar.NewMap("http_transports")
+
+// TransportMetricsStats describes connection pool of http.Transport
+type TransportMetricsStats struct {
+	// Open is the number of established connections
+	Open int64
+	// Active is the number of connections in use by requests
+	Active int64
+	// Idle is the number of open connections not in use
+	Idle int64
+	// Requests is the number of requests sent
+	Requests int64
+	// NewConns is the number of requests sent over a newly dialed connection
+	NewConns int64
+}
+
+// TransportMetricsCollector is http.RoundTripper collecting connection pool
+// metrics of wrapped http.Transport
+type TransportMetricsCollector struct {
+	// Interval between samples published to expvar, see Start
+	Interval time.Duration
+
+	transport *http.Transport
+	name      string
+
+	open     int64
+	active   int64
+	requests int64
+	newConns int64
+
+	vars *expvar.Map
+	done chan struct{}
+	once sync.Once
+}
+
+// TransportMetrics wraps a clone of transport to collect its connection pool
+// metrics published to expvar "http_transports" map under name
+//
+// Dialer of the clone is replaced to track open connections, t is left
+// untouched, so collector has to be used as client transport instead of t.
+func TransportMetrics(t *http.Transport, name string) *TransportMetricsCollector {
+	t = t.Clone()
+	m := &TransportMetricsCollector{
+		Interval:  defaultTransportMetricsInterval,
+		transport: t,
+		name:      name,
+		vars:      new(expvar.Map).Init(),
+		done:      make(chan struct{}),
+	}
+
+	dial := t.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		atomic.AddInt64(&m.open, 1)
+		return &trackedConn{Conn: conn, closed: func() { atomic.AddInt64(&m.open, -1) }}, nil
+	}
+
+	transportMetricsVars.Set(name, m.vars)
+	m.Sample()
+	return m
+}
+
+// RoundTrip sends request with wrapped transport
+func (m *TransportMetricsCollector) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt64(&m.requests, 1)
+
+	var gotConn int32
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			atomic.StoreInt32(&gotConn, 1)
+			atomic.AddInt64(&m.active, 1)
+			if !info.Reused {
+				atomic.AddInt64(&m.newConns, 1)
+			}
+		},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	res, err := m.transport.RoundTrip(req)
+	release := func() {
+		if atomic.CompareAndSwapInt32(&gotConn, 1, 0) {
+			atomic.AddInt64(&m.active, -1)
+		}
+	}
+	if err != nil || res.StatusCode == http.StatusSwitchingProtocols {
+		// upgraded connection is not returned to pool
+		release()
+		return res, err
+	}
+	// connection is returned to pool once body is read or closed
+	res.Body = &trackedBody{ReadCloser: res.Body, release: release}
+	return res, nil
+}
+
+// CloseIdleConnections closes idle connections of wrapped transport
+func (m *TransportMetricsCollector) CloseIdleConnections() {
+	m.transport.CloseIdleConnections()
+}
+
+// Stats returns current connection pool metrics
+func (m *TransportMetricsCollector) Stats() TransportMetricsStats {
+	open := atomic.LoadInt64(&m.open)
+	active := atomic.LoadInt64(&m.active)
+	idle := open - active
+	if idle < 0 {
+		idle = 0
+	}
+	return TransportMetricsStats{
+		Open:     open,
+		Active:   active,
+		Idle:     idle,
+		Requests: atomic.LoadInt64(&m.requests),
+		NewConns: atomic.LoadInt64(&m.newConns),
+	}
+}
+
+// Sample publishes current metrics to expvar
+func (m *TransportMetricsCollector) Sample() {
+	stats := m.Stats()
+	for key, value := range map[string]int64{
+		"open":      stats.Open,
+		"active":    stats.Active,
+		"idle":      stats.Idle,
+		"requests":  stats.Requests,
+		"new_conns": stats.NewConns,
+	} {
+		v := new(expvar.Int)
+		v.Set(value)
+		m.vars.Set(key, v)
+	}
+}
+
+// Start samples metrics every Interval until ctx is done or collector is stopped
+func (m *TransportMetricsCollector) Start(ctx context.Context) error {
+	go func() {
+		ticker := time.NewTicker(m.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.Sample()
+			case <-ctx.Done():
+				return
+			case <-m.done:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops sampling metrics
+func (m *TransportMetricsCollector) Stop(ctx context.Context) error {
+	m.once.Do(func() { close(m.done) })
+	return nil
+}
+
+// ExposeTransportMetrics replaces transport of client with TransportMetricsCollector
+// sampling its metrics every Options.TransportMetricsInterval while application runs
+//
+// Client without transport gets a clone of http.DefaultTransport.
+func (a *App) ExposeTransportMetrics(name string, client *http.Client) *TransportMetricsCollector {
+	var t *http.Transport
+	switch rt := client.Transport.(type) {
+	case nil:
+		t = http.DefaultTransport.(*http.Transport)
+	case *http.Transport:
+		t = rt
+	default:
+		panic(fmt.Sprintf("transport metrics require *http.Transport, got %T", rt))
+	}
+
+	m := TransportMetrics(t, name)
+	if a.TransportMetricsInterval > 0 {
+		m.Interval = a.TransportMetricsInterval
+	}
+	client.Transport = m
+	a.Register(m)
+	return m
+}
+
+// trackedConn reports when connection is closed
+type trackedConn struct {
+	net.Conn
+	once   sync.Once
+	closed func()
+}
+
+func (c *trackedConn) Close() error {
+	c.once.Do(c.closed)
+	return c.Conn.Close()
+}
+
+// trackedBody reports when response body is fully read or closed
+type trackedBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *trackedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.release()
+	}
+	return n, err
+}
+
+func (b *trackedBody) Close() error {
+	b.release()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,69 @@
+package cucumber
+
+import (
+	"context"
+	"expvar"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransportMetrics(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	defer upstream.Close()
+
+	app := newTestAppInstance()
+	app.TransportMetricsInterval = 10 * time.Millisecond
+	client := &http.Client{}
+	metrics := app.ExposeTransportMetrics("upstream", client)
+	assert.Same(t, metrics, client.Transport)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.NoError(t, app.startServices(ctx))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := client.Get(upstream.URL)
+			if assert.NoError(t, err) {
+				ioutil.ReadAll(res.Body)
+				res.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := metrics.Stats()
+	assert.Equal(t, int64(5), stats.Requests)
+	assert.Equal(t, int64(0), stats.Active)
+	assert.NotZero(t, stats.Idle)
+	assert.Equal(t, stats.Open, stats.Idle)
+	assert.GreaterOrEqual(t, stats.NewConns, stats.Open)
+
+	// sampled to expvar
+	time.Sleep(50 * time.Millisecond)
+	vars := expvar.Get("http_transports").(*expvar.Map).Get("upstream").(*expvar.Map)
+	assert.Equal(t, "5", vars.Get("requests").String())
+	assert.NotEqual(t, "0", vars.Get("idle").String())
+
+	assert.NoError(t, app.stop())
+	client.CloseIdleConnections()
+	assert.Zero(t, metrics.Stats().Open)
+}
+
+func TestTransportMetricsClonesTransport(t *testing.T) {
+	transport := &http.Transport{}
+	TransportMetrics(transport, "clone")
+	assert.Nil(t, transport.DialContext)
+}
@@ -301,12 +301,117 @@ func (n *node) insertChild(numParams uint8, path, fullPath string, handler Handl
 	n.handler = handler
 }
 
+// remove removes the handler registered with the given path, as it was passed
+// to addRoute, and reports whether it was found. Nodes left without handler
+// and children are pruned and a static node left with a single static child
+// is merged with it.
+// Not concurrency-safe!
+func (n *node) remove(path string) bool {
+	var parents []*node
+
+	// find the node holding the handler
+walk:
+	for {
+		if len(path) < len(n.path) || path[:len(n.path)] != n.path {
+			return false
+		}
+		path = path[len(n.path):]
+		if len(path) == 0 {
+			break
+		}
+
+		parents = append(parents, n)
+		if n.wildChild || (n.nType == param && len(n.children) == 1) {
+			n = n.children[0]
+			continue
+		}
+		for i := 0; i < len(n.indices); i++ {
+			if path[0] == n.indices[i] {
+				n = n.children[i]
+				continue walk
+			}
+		}
+		return false
+	}
+
+	if n.handler == nil {
+		return false
+	}
+	n.handler = nil
+	n.priority--
+	for _, parent := range parents {
+		parent.priority--
+	}
+
+	// prune empty nodes
+	for len(parents) > 0 && n.handler == nil && len(n.children) == 0 {
+		parent := parents[len(parents)-1]
+		parents = parents[:len(parents)-1]
+		parent.removeChild(n)
+		n = parent
+	}
+
+	// merge remaining node with its only static child
+	if n.nType != param && n.nType != catchAll && n.handler == nil &&
+		!n.wildChild && len(n.children) == 1 && n.children[0].nType == static {
+		child := n.children[0]
+		n.path += child.path
+		n.wildChild = child.wildChild
+		n.indices = child.indices
+		n.children = child.children
+		n.handler = child.handler
+	}
+
+	// empty tree
+	if n.nType == root && n.handler == nil && len(n.children) == 0 {
+		*n = node{}
+		return true
+	}
+
+	n.updateMaxParams()
+	for i := len(parents) - 1; i >= 0; i-- {
+		parents[i].updateMaxParams()
+	}
+	return true
+}
+
+// removeChild removes child from node children
+func (n *node) removeChild(child *node) {
+	for i := range n.children {
+		if n.children[i] != child {
+			continue
+		}
+		n.children = append(n.children[:i], n.children[i+1:]...)
+		if n.wildChild {
+			n.wildChild = false
+		} else if len(n.indices) > i {
+			n.indices = n.indices[:i] + n.indices[i+1:]
+		}
+		return
+	}
+}
+
+// updateMaxParams recalculates maxParams of the node from its children
+func (n *node) updateMaxParams() {
+	var maxParams uint8
+	for _, child := range n.children {
+		if child.maxParams > maxParams {
+			maxParams = child.maxParams
+		}
+	}
+	if n.nType > root && !n.wildChild {
+		maxParams++
+	}
+	n.maxParams = maxParams
+}
+
 // Returns the handler registered with the given path (key). The values of
-// wildcards are saved to a map.
+// wildcards are appended to po, which allows reusing its backing array.
 // If no handler can be found, a TSR (trailing slash redirect) recommendation is
 // made if a handler exists with an extra (without the) trailing slash for the
 // given path.
-func (n *node) getValue(path string) (handler HandlersChain, p Params, tsr bool) {
+func (n *node) getValue(path string, po Params) (handler HandlersChain, p Params, tsr bool) {
+	p = po
 walk: // outer loop for walking the tree
 	for {
 		if len(path) > len(n.path) {
@@ -347,10 +452,7 @@ walk: // outer loop for walking the tree
 						// lazy allocation
 						p = make(Params, 0, n.maxParams)
 					}
-					i := len(p)
-					p = p[:i+1] // expand slice within preallocated capacity
-					p[i].Key = n.path[1:]
-					p[i].Value = path[:end]
+					p = append(p, Param{Key: n.path[1:], Value: path[:end]})
 
 					// we need to go deeper!
 					if end < len(path) {
@@ -382,10 +484,7 @@ walk: // outer loop for walking the tree
 						// lazy allocation
 						p = make(Params, 0, n.maxParams)
 					}
-					i := len(p)
-					p = p[:i+1] // expand slice within preallocated capacity
-					p[i].Key = n.path[2:]
-					p[i].Value = path
+					p = append(p, Param{Key: n.path[2:], Value: path})
 
 					handler = n.handler
 					return
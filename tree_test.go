@@ -26,7 +26,7 @@ type testRequests []struct {
 
 func checkRequests(t *testing.T, tree *node, requests testRequests) {
 	for _, request := range requests {
-		handler, ps, _ := tree.getValue(request.path)
+		handler, ps, _ := tree.getValue(request.path, nil)
 
 		if handler == nil {
 			if !request.nilHandler {
@@ -409,7 +409,7 @@ func TestTreeTrailingSlashRedirect(t *testing.T) {
 		"/doc/",
 	}
 	for _, route := range tsrRoutes {
-		handler, _, tsr := tree.getValue(route)
+		handler, _, tsr := tree.getValue(route, nil)
 		if handler != nil {
 			t.Fatalf("non-nil handler for TSR route '%s", route)
 		} else if !tsr {
@@ -426,7 +426,7 @@ func TestTreeTrailingSlashRedirect(t *testing.T) {
 		"/api/world/abc",
 	}
 	for _, route := range noTsrRoutes {
-		handler, _, tsr := tree.getValue(route)
+		handler, _, tsr := tree.getValue(route, nil)
 		if handler != nil {
 			t.Fatalf("non-nil handler for No-TSR route '%s", route)
 		} else if tsr {
@@ -445,7 +445,7 @@ func TestTreeRootTrailingSlashRedirect(t *testing.T) {
 		t.Fatalf("panic inserting test route: %v", recv)
 	}
 
-	handler, _, tsr := tree.getValue("/")
+	handler, _, tsr := tree.getValue("/", nil)
 	if handler != nil {
 		t.Fatalf("non-nil handler")
 	} else if tsr {
@@ -596,7 +596,7 @@ func TestTreeInvalidNodeType(t *testing.T) {
 
 	// normal lookup
 	recv := catchPanic(func() {
-		tree.getValue("/test")
+		tree.getValue("/test", nil)
 	})
 	if rs, ok := recv.(string); !ok || rs != panicMsg {
 		t.Fatalf("Expected panic '"+panicMsg+"', got '%v'", recv)
@@ -649,3 +649,104 @@ func TestTreeWildcardConflictEx(t *testing.T) {
 		}
 	}
 }
+
+func TestTreeRemove(t *testing.T) {
+	tree := &node{}
+
+	routes := [...]string{
+		"/",
+		"/cmd/:tool/:sub",
+		"/cmd/:tool/",
+		"/src/*filepath",
+		"/search/",
+		"/search/:query",
+		"/user_:name",
+		"/user_:name/about",
+		"/doc/",
+		"/doc/go_faq.html",
+		"/doc/go1.html",
+	}
+	for _, route := range routes {
+		tree.addRoute(route, fakeHandler(route))
+	}
+
+	for _, route := range []string{"/cmd/:tool/:sub", "/src/*filepath", "/search/", "/user_:name", "/doc/go1.html", "/"} {
+		if !tree.remove(route) {
+			t.Errorf("route '%s' not removed", route)
+		}
+		checkPriorities(t, tree)
+		checkMaxParams(t, tree)
+	}
+
+	checkRequests(t, tree, testRequests{
+		{"/", true, "", nil},
+		{"/cmd/test/", false, "/cmd/:tool/", Params{Param{"tool", "test"}}},
+		{"/cmd/test/3", true, "", Params{Param{"tool", "test"}}},
+		{"/src/some/file.png", true, "", nil},
+		{"/search/", true, "", nil},
+		{"/search/gopher", false, "/search/:query", Params{Param{"query", "gopher"}}},
+		{"/user_gopher", true, "", Params{Param{"name", "gopher"}}},
+		{"/user_gopher/about", false, "/user_:name/about", Params{Param{"name", "gopher"}}},
+		{"/doc/", false, "/doc/", nil},
+		{"/doc/go_faq.html", false, "/doc/go_faq.html", nil},
+		{"/doc/go1.html", true, "", nil},
+	})
+
+	// removed routes can be registered again
+	for _, route := range []string{"/cmd/:tool/:sub", "/src/*filepath", "/doc/go1.html"} {
+		tree.addRoute(route, fakeHandler(route))
+	}
+	checkRequests(t, tree, testRequests{
+		{"/cmd/test/3", false, "/cmd/:tool/:sub", Params{Param{"tool", "test"}, Param{"sub", "3"}}},
+		{"/src/some/file.png", false, "/src/*filepath", Params{Param{"filepath", "/some/file.png"}}},
+		{"/doc/go1.html", false, "/doc/go1.html", nil},
+	})
+	checkPriorities(t, tree)
+	checkMaxParams(t, tree)
+
+	// unknown routes and wildcard name mismatch
+	for _, route := range []string{"/", "/cmd", "/cmd/:name/", "/search/:query/", "/nothing"} {
+		if tree.remove(route) {
+			t.Errorf("unexpected removal of route '%s'", route)
+		}
+	}
+}
+
+func TestTreeRemoveAll(t *testing.T) {
+	tree := &node{}
+
+	routes := [...]string{"/a", "/ab", "/abc/:id", "/files/*filepath"}
+	for _, route := range routes {
+		tree.addRoute(route, fakeHandler(route))
+	}
+	for _, route := range routes {
+		if !tree.remove(route) {
+			t.Errorf("route '%s' not removed", route)
+		}
+	}
+
+	if tree.path != "" || len(tree.children) != 0 || tree.priority != 0 {
+		t.Errorf("tree not empty after removing all routes: %+v", tree)
+	}
+
+	tree.addRoute("/a", fakeHandler("/a"))
+	checkRequests(t, tree, testRequests{
+		{"/a", false, "/a", nil},
+	})
+}
+
+func BenchmarkTreeGetValue(b *testing.B) {
+	tree := &node{}
+	for _, route := range []string{"/users/:id", "/users/:id/orders/:order", "/src/*filepath"} {
+		tree.addRoute(route, fakeHandler(route))
+	}
+
+	// params backing array is reused like the one of pooled Context
+	ps := make(Params, 0, tree.maxParams)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.getValue("/users/42/orders/7", ps[:0])
+	}
+}
@@ -2,7 +2,6 @@ package cucumber
 
 import (
 	"fmt"
-	"io/ioutil"
 	"path"
 	"reflect"
 	"regexp"
@@ -87,28 +86,6 @@ func byteCountBinary(b int64) string {
 	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
 }
 
-func loadPartials(viewsRoot, partialsRoot, ext string) ([]string, error) {
-	dirname := path.Join(viewsRoot, partialsRoot)
-	files, err := ioutil.ReadDir(dirname)
-	if err != nil {
-		return nil, err
-	}
-	partials := []string{}
-	for _, f := range files {
-		partial := f.Name()
-		if strings.HasSuffix(partial, ext) {
-			// remove ext from file
-			partial = strings.TrimSuffix(partial, ext)
-			// join file with folder name
-			partial = path.Join(partialsRoot, partial)
-
-			// add to partials
-			partials = append(partials, partial)
-		}
-	}
-	return partials, nil
-}
-
 var matchFirstCap = regexp.MustCompile("(.)([A-Z][a-z]+)")
 var matchAllCap = regexp.MustCompile("([a-z0-9])([A-Z])")
 
@@ -0,0 +1,86 @@
+package cucumber
+
+import (
+	"regexp"
+	"strings"
+)
+
+// mediaTypeVersionRegex matches version of vendor media type, e.g. application/vnd.myapp.v2+json
+var mediaTypeVersionRegex = regexp.MustCompile(`^application/vnd\.[^;+]*\.(v[0-9]+)(\+[a-z0-9.-]+)?$`)
+
+// HandleVersioned registers a new request handle with the given path, method
+// and media type version requested via Accept header, e.g. "v2" for
+// application/vnd.myapp.v2+json
+//
+// When request does not specify version, Options.DefaultAPIVersion is used.
+// Requests with version which has no matching route are handled by routes
+// registered without version.
+func (r *Router) HandleVersioned(method, path, version string, handlers ...HandlerFunc) {
+	if version == "" {
+		panic("version can not be empty")
+	}
+	trees := r.versionTrees[version]
+	if trees == nil {
+		trees = make(map[string]*node)
+		r.versionTrees[version] = trees
+	}
+	r.handle(trees, method, path, handlers)
+}
+
+// GETVersioned is a shortcut for router.HandleVersioned("GET", path, version, handler)
+func (r *Router) GETVersioned(path, version string, handler ...HandlerFunc) {
+	r.HandleVersioned("GET", path, version, handler...)
+}
+
+// POSTVersioned is a shortcut for router.HandleVersioned("POST", path, version, handler)
+func (r *Router) POSTVersioned(path, version string, handler ...HandlerFunc) {
+	r.HandleVersioned("POST", path, version, handler...)
+}
+
+// PUTVersioned is a shortcut for router.HandleVersioned("PUT", path, version, handler)
+func (r *Router) PUTVersioned(path, version string, handler ...HandlerFunc) {
+	r.HandleVersioned("PUT", path, version, handler...)
+}
+
+// PATCHVersioned is a shortcut for router.HandleVersioned("PATCH", path, version, handler)
+func (r *Router) PATCHVersioned(path, version string, handler ...HandlerFunc) {
+	r.HandleVersioned("PATCH", path, version, handler...)
+}
+
+// DELETEVersioned is a shortcut for router.HandleVersioned("DELETE", path, version, handler)
+func (r *Router) DELETEVersioned(path, version string, handler ...HandlerFunc) {
+	r.HandleVersioned("DELETE", path, version, handler...)
+}
+
+// APIVersion returns media type version requested via Accept header,
+// or Options.DefaultAPIVersion when request does not specify it
+func (c *Context) APIVersion() string {
+	if version := mediaTypeVersion(c.requestHeader("Accept")); version != "" {
+		return version
+	}
+	return c.app.DefaultAPIVersion
+}
+
+// lookupVersioned returns handlers of versioned route matching request version
+func (a *App) lookupVersioned(c *Context) (HandlersChain, Params) {
+	if len(a.router.versionTrees) == 0 {
+		return nil, nil
+	}
+	if root := a.router.versionTrees[c.APIVersion()][c.Request.Method]; root != nil {
+		if handlers, ps, _ := root.getValue(c.Request.URL.Path, c.Params); handlers != nil {
+			return handlers, ps
+		}
+	}
+	return nil, nil
+}
+
+// mediaTypeVersion extracts version from first vendor media type of Accept header
+func mediaTypeVersion(accept string) string {
+	for _, mediaType := range strings.Split(accept, ",") {
+		mediaType = normalizeContentType(mediaType)
+		if m := mediaTypeVersionRegex.FindStringSubmatch(mediaType); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
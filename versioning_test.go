@@ -0,0 +1,101 @@
+package cucumber
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterVersionedRoutes(t *testing.T) {
+	app := newTestAppInstance()
+	api := app.Router().Group("/api")
+	api.GETVersioned("/users", "v1", func(c *Context) {
+		c.String(http.StatusOK, "v1")
+	})
+	api.GETVersioned("/users", "v2", func(c *Context) {
+		c.String(http.StatusOK, c.APIVersion())
+	})
+	api.GET("/users", func(c *Context) {
+		c.String(http.StatusOK, "unversioned")
+	})
+
+	get := func(accept string) string {
+		req, _ := http.NewRequest("GET", "/api/users", nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	assert.Equal(t, "v2", get("application/vnd.myapp.v2+json"))
+	assert.Equal(t, "v2", get("text/html, application/vnd.myapp.v2+json; charset=utf-8"))
+	assert.Equal(t, "v1", get("application/vnd.myapp.v1+json"))
+	assert.Equal(t, "v1", get("application/json"))
+	assert.Equal(t, "v1", get(""))
+	assert.Equal(t, "unversioned", get("application/vnd.myapp.v3+json"))
+
+	app.DefaultAPIVersion = "v2"
+	assert.Equal(t, "v2", get(""))
+
+	assert.Panics(t, func() {
+		api.GETVersioned("/users", "", func(c *Context) {})
+	})
+}
+
+type invoicesController struct{}
+
+func (ctrl *invoicesController) Prefix() string {
+	return "/invoices"
+}
+
+func (ctrl *invoicesController) Routes() *Router {
+	r := NewRouter()
+	r.GETVersioned("/", "v2", func(c *Context) {
+		c.String(http.StatusOK, "invoices v2")
+	})
+	r.GET("/", func(c *Context) {
+		c.String(http.StatusOK, "invoices")
+	})
+	return r
+}
+
+func TestRegisterControllerVersionedRoutes(t *testing.T) {
+	app := newTestAppInstance()
+	app.ControllerPackage = "cucumber"
+	app.HandleMethodNotAllowed = true
+	app.RegisterController(&invoicesController{})
+	app.Router().DELETEVersioned("/invoices/:id", "v2", func(c *Context) {})
+
+	req, _ := http.NewRequest("GET", "/invoices/", nil)
+	req.Header.Set("Accept", "application/vnd.myapp.v2+json")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	assert.Equal(t, "invoices v2", w.Body.String())
+
+	w = performRequest(app, "GET", "/invoices/")
+	assert.Equal(t, "invoices", w.Body.String())
+
+	var versioned []Route
+	for _, route := range app.Router().Routes() {
+		if route.Version != "" {
+			versioned = append(versioned, route)
+		}
+	}
+	assert.Len(t, versioned, 2)
+
+	// versioned routes are allowed methods of path
+	w = performRequest(app, "GET", "/invoices/7")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "DELETE, OPTIONS", app.router.allowed("/invoices/7", "GET"))
+
+	assert.Contains(t, app.VisualizeRoutes()["GET v2"], "invoices")
+
+	// host routing does not consult versions
+	assert.Panics(t, func() {
+		app.Router().SubdomainRouter("api.example.com", (&invoicesController{}).Routes())
+	})
+}
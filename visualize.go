@@ -0,0 +1,66 @@
+package cucumber
+
+import (
+	"strings"
+)
+
+// VisualizeTree returns ASCII representation of the route tree
+// registered for given HTTP method
+//
+// Every line holds path segment of a tree node, children are indented
+// under their parent. Nodes with handlers show the handler function name.
+func (r *Router) VisualizeTree(method string) string {
+	root := r.trees[method]
+	if root == nil {
+		return ""
+	}
+	var sb strings.Builder
+	root.visualize(&sb, "", true, true)
+	return sb.String()
+}
+
+// VisualizeRoutes returns ASCII representation of route trees per HTTP method,
+// trees of versioned routes are keyed by method and version, e.g. "GET v2"
+func (a *App) VisualizeRoutes() map[string]string {
+	trees := make(map[string]string, len(a.router.trees))
+	for method := range a.router.trees {
+		trees[method] = a.router.VisualizeTree(method)
+	}
+	for version, versionTrees := range a.router.versionTrees {
+		for method, root := range versionTrees {
+			var sb strings.Builder
+			root.visualize(&sb, "", true, true)
+			trees[method+" "+version] = sb.String()
+		}
+	}
+	return trees
+}
+
+func (n *node) visualize(sb *strings.Builder, prefix string, last, isRoot bool) {
+	childPrefix := prefix
+	if !isRoot {
+		if last {
+			sb.WriteString(prefix + "`-- ")
+			childPrefix += "    "
+		} else {
+			sb.WriteString(prefix + "|-- ")
+			childPrefix += "|   "
+		}
+	}
+
+	sb.WriteString(n.path)
+	switch n.nType {
+	case param:
+		sb.WriteString(" (param)")
+	case catchAll:
+		sb.WriteString(" (wildcard)")
+	}
+	if len(n.handler) > 0 {
+		sb.WriteString(" => " + nameOfFunction(n.handler.Last()))
+	}
+	sb.WriteString("\n")
+
+	for i, child := range n.children {
+		child.visualize(sb, childPrefix, i == len(n.children)-1, false)
+	}
+}
@@ -0,0 +1,38 @@
+package cucumber
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func visualizeTestHandler(c *Context) {}
+
+func TestAppVisualizeRoutes(t *testing.T) {
+	app := newTestAppInstance()
+	app.GET("/", visualizeTestHandler)
+	app.GET("/users", visualizeTestHandler)
+	app.GET("/users/:id", visualizeTestHandler)
+	app.GET("/users/:id/posts", visualizeTestHandler)
+	app.GET("/posts", visualizeTestHandler)
+	app.GET("/posts/:slug/comments/:comment", visualizeTestHandler)
+	app.GET("/static/*filepath", visualizeTestHandler)
+	app.POST("/users", visualizeTestHandler)
+	app.POST("/users/:id/avatar", visualizeTestHandler)
+	app.DELETE("/users/:id", visualizeTestHandler)
+
+	trees := app.VisualizeRoutes()
+	assert.Len(t, trees, 3)
+
+	get := trees["GET"]
+	assert.Contains(t, get, ":id (param) => github.com/AjdinHalac/cucumber.visualizeTestHandler")
+	assert.Contains(t, get, ":comment (param)")
+	assert.Contains(t, get, "/*filepath (wildcard)")
+	assert.Contains(t, get, "posts")
+	assert.True(t, strings.HasPrefix(get, "/ =>"))
+
+	assert.Contains(t, trees["POST"], "/avatar")
+	assert.Equal(t, "/users/\n`-- :id (param) => github.com/AjdinHalac/cucumber.visualizeTestHandler\n", trees["DELETE"])
+	assert.Empty(t, app.Router().VisualizeTree("PATCH"))
+}
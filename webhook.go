@@ -0,0 +1,146 @@
+package cucumber
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BodyBytesKey is the context key holding request body cached by middlewares
+// which have to read it, e.g. WebhookVerify
+const BodyBytesKey = "bodyBytes"
+
+// defaultWebhookMaxBodySize limits webhook body when WebhookConfig.MaxBodySize is not set
+const defaultWebhookMaxBodySize = 1 << 20 // 1 MB
+
+var (
+	// ErrWebhookSignature is served when webhook signature is missing or invalid
+	ErrWebhookSignature = errors.New("invalid webhook signature")
+
+	// ErrWebhookTimestamp is served when webhook timestamp is missing, invalid or expired
+	ErrWebhookTimestamp = errors.New("invalid webhook timestamp")
+
+	// ErrWebhookBodyTooLarge is served when webhook body exceeds WebhookConfig.MaxBodySize
+	ErrWebhookBodyTooLarge = errors.New("webhook body too large")
+)
+
+// WebhookConfig configures WebhookVerify middleware
+type WebhookConfig struct {
+	// SecretKey is the key used to compute HMAC of request body
+	SecretKey []byte
+
+	// SignatureHeader is the header holding hex encoded signature,
+	// optionally prefixed with algorithm, e.g. "sha256=...". Defaults to X-Hub-Signature-256
+	SignatureHeader string
+
+	// Algorithm is the HMAC hash algorithm, sha256 or sha1. Defaults to sha256
+	Algorithm string
+
+	// TimestampHeader is the header holding unix timestamp of the request.
+	// When set, signature is computed over "<timestamp>.<body>"
+	TimestampHeader string
+
+	// MaxAge is the maximum allowed difference between timestamp and current time.
+	// Zero disables the check
+	MaxAge time.Duration
+
+	// MaxBodySize is the maximum size in bytes of verified request body,
+	// larger requests are rejected with 413. Defaults to 1 MB
+	MaxBodySize int64
+}
+
+// WebhookVerify returns a middleware that verifies HMAC signature of request body
+// and aborts with 401 Unauthorized on mismatch or expired timestamp
+//
+// Request body is cached, so it can still be read by downstream handlers,
+// and is also available under BodyBytesKey. It panics when SecretKey is empty,
+// as anyone could sign requests with empty key.
+func WebhookVerify(cfg WebhookConfig) HandlerFunc {
+	if len(cfg.SecretKey) == 0 {
+		panic("webhook secret key can not be empty")
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = defaultWebhookMaxBodySize
+	}
+	if cfg.SignatureHeader == "" {
+		cfg.SignatureHeader = "X-Hub-Signature-256"
+	}
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = "sha256"
+	}
+
+	var hashFunc func() hash.Hash
+	switch cfg.Algorithm {
+	case "sha256":
+		hashFunc = sha256.New
+	case "sha1":
+		hashFunc = sha1.New
+	default:
+		panic("unsupported webhook algorithm '" + cfg.Algorithm + "'")
+	}
+
+	return func(c *Context) {
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			// read one byte over the limit to detect larger body
+			if body, err = ioutil.ReadAll(io.LimitReader(c.Request.Body, cfg.MaxBodySize+1)); err != nil {
+				c.Abort()
+				c.ServeError(http.StatusBadRequest, err)
+				return
+			}
+			if int64(len(body)) > cfg.MaxBodySize {
+				c.Abort()
+				c.ServeError(http.StatusRequestEntityTooLarge, ErrWebhookBodyTooLarge)
+				return
+			}
+		}
+		c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		c.Set(BodyBytesKey, body)
+
+		mac := hmac.New(hashFunc, cfg.SecretKey)
+		if cfg.TimestampHeader != "" {
+			timestamp := c.Request.Header.Get(cfg.TimestampHeader)
+			if !validWebhookTimestamp(timestamp, cfg.MaxAge) {
+				c.Abort()
+				c.ServeError(http.StatusUnauthorized, ErrWebhookTimestamp)
+				return
+			}
+			mac.Write([]byte(timestamp + "."))
+		}
+		mac.Write(body)
+
+		signature := c.Request.Header.Get(cfg.SignatureHeader)
+		signature = strings.TrimPrefix(signature, cfg.Algorithm+"=")
+		expected, err := hex.DecodeString(signature)
+		if err != nil || !hmac.Equal(expected, mac.Sum(nil)) {
+			c.Abort()
+			c.ServeError(http.StatusUnauthorized, ErrWebhookSignature)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func validWebhookTimestamp(value string, maxAge time.Duration) bool {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return false
+	}
+	if maxAge <= 0 {
+		return true
+	}
+	age := time.Since(time.Unix(seconds, 0))
+	return age <= maxAge && age >= -maxAge
+}
@@ -0,0 +1,101 @@
+package cucumber
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func signWebhook(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWebhookVerify(t *testing.T) {
+	app := newTestAppInstance()
+	app.POST("/webhook", WebhookVerify(WebhookConfig{SecretKey: []byte("secret")}), func(c *Context) {
+		body, _ := ioutil.ReadAll(c.Request.Body)
+		cached, _ := c.Get(BodyBytesKey)
+		assert.Equal(t, string(body), string(cached.([]byte)))
+		c.String(http.StatusOK, string(body))
+	})
+
+	payload := `{"action":"opened"}`
+	signature := signWebhook("secret", payload)
+
+	req, _ := http.NewRequest("POST", "/webhook", strings.NewReader(payload))
+	req.Header.Set("X-Hub-Signature-256", signature)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, payload, w.Body.String())
+
+	req, _ = http.NewRequest("POST", "/webhook", strings.NewReader(`{"action":"closed"}`))
+	req.Header.Set("X-Hub-Signature-256", signature)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = performRequest(app, "POST", "/webhook")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestWebhookVerifyTimestamp(t *testing.T) {
+	app := newTestAppInstance()
+	app.POST("/webhook", WebhookVerify(WebhookConfig{
+		SecretKey:       []byte("secret"),
+		SignatureHeader: "X-Signature",
+		TimestampHeader: "X-Timestamp",
+		MaxAge:          5 * time.Minute,
+	}), func(c *Context) {
+		c.Status(http.StatusOK)
+	})
+
+	send := func(timestamp time.Time) int {
+		payload := `{"action":"opened"}`
+		ts := strconv.FormatInt(timestamp.Unix(), 10)
+		req, _ := http.NewRequest("POST", "/webhook", strings.NewReader(payload))
+		req.Header.Set("X-Signature", signWebhook("secret", ts+"."+payload))
+		req.Header.Set("X-Timestamp", ts)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send(time.Now()))
+	assert.Equal(t, http.StatusUnauthorized, send(time.Now().Add(-time.Hour)))
+	assert.Panics(t, func() {
+		WebhookVerify(WebhookConfig{SecretKey: []byte("secret"), Algorithm: "md5"})
+	})
+	assert.Panics(t, func() {
+		WebhookVerify(WebhookConfig{})
+	})
+}
+
+func TestWebhookVerifyBodyLimit(t *testing.T) {
+	app := newTestAppInstance()
+	app.POST("/webhook", WebhookVerify(WebhookConfig{SecretKey: []byte("secret"), MaxBodySize: 16}), func(c *Context) {
+		c.Status(http.StatusOK)
+	})
+
+	send := func(payload string) int {
+		req, _ := http.NewRequest("POST", "/webhook", strings.NewReader(payload))
+		req.Header.Set("X-Hub-Signature-256", signWebhook("secret", payload))
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send(strings.Repeat("a", 16)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, send(strings.Repeat("a", 17)))
+}